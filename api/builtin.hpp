@@ -48,6 +48,9 @@ slice<_Item_t> JNID(append)(const slice<_Item_t> &_Src,
 
 template <typename T> ptr<T> JNID(new)(void) noexcept;
 
+template <typename T> constexpr uint_jnt JNID(sizeof)(void) noexcept;
+template <typename T> constexpr uint_jnt JNID(alignof)(void) noexcept;
+
 // definition
 template <typename _Obj_t> inline void JNID(print)(const _Obj_t _Obj) noexcept {
   std::cout << _Obj;
@@ -120,4 +123,12 @@ ptr<T> JNID(new)(void) noexcept {
   return _ptr;
 }
 
+template <typename T> constexpr uint_jnt JNID(sizeof)(void) noexcept {
+  return sizeof(T);
+}
+
+template <typename T> constexpr uint_jnt JNID(alignof)(void) noexcept {
+  return alignof(T);
+}
+
 #endif // !__JNC_BUILTIN_HPP
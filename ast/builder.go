@@ -24,6 +24,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/DeRuneLabs/jane/ast/models"
 	"github.com/DeRuneLabs/jane/lexer"
@@ -47,6 +48,9 @@ type Builder struct {
 	Errors []jnlog.CompilerLog
 	Toks   Toks
 	Pos    int
+	// StdlibPath is path of standard library for use declarations.
+	// it must be set before build of trees that have use declarations.
+	StdlibPath string
 }
 
 func NewBuilder(toks Toks) *Builder {
@@ -61,7 +65,10 @@ func compilerErr(tok Tok, key string, args ...any) jnlog.CompilerLog {
 		Type:    jnlog.Error,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  utf8.RuneCountInString(tok.Kind),
 		Path:    tok.File.Path(),
+		Line:    tok.File.Line(tok.Row),
+		Key:     key,
 		Message: jn.GetError(key, args...),
 	}
 }
@@ -681,7 +688,7 @@ func (b *Builder) buildUseCppDecl(use *models.Use, toks Toks) {
 
 func (b *Builder) buildUseDecl(use *models.Use, toks Toks) {
 	var path strings.Builder
-	path.WriteString(jn.StdlibPath)
+	path.WriteString(b.StdlibPath)
 	path.WriteRune(os.PathSeparator)
 	tok := toks[0]
 	isStd := false
@@ -1075,7 +1082,7 @@ func (b *Builder) idDataTypePartEnd(t *models.DataType, dtv *strings.Builder, to
 		if index+1 < len(part) {
 			b.pusherr(part[index+1], "invalid_syntax")
 		}
-		genericsStr.WriteString(t.String())
+		genericsStr.WriteString(t.Kind)
 		genericsStr.WriteByte(',')
 		generics[i] = t
 	}
@@ -2344,13 +2351,14 @@ func (b *Builder) ContinueStatement(toks Toks) models.Statement {
 }
 
 func (b *Builder) Expr(toks Toks) (e models.Expr) {
-	e.Processes = b.exprProcesses(toks)
 	e.Toks = toks
+	e.Tree = b.exprTree(toks)
 	return
 }
 
-type exprProcessInfo struct {
-	processes        []Toks
+type exprPartsInfo struct {
+	operands         []Toks
+	ops              []Tok
 	part             Toks
 	operator         bool
 	value            bool
@@ -2361,7 +2369,7 @@ type exprProcessInfo struct {
 	i                int
 }
 
-func (b *Builder) exprOperatorPart(info *exprProcessInfo, tok Tok) {
+func (b *Builder) exprOperatorPart(info *exprPartsInfo, tok Tok) {
 	if IsExprOperator(tok.Kind) ||
 		IsAssignOperator(tok.Kind) {
 		info.part = append(info.part, tok)
@@ -2384,12 +2392,12 @@ func (b *Builder) exprOperatorPart(info *exprProcessInfo, tok Tok) {
 		info.part = append(info.part, tok)
 		return
 	}
-	info.processes = append(info.processes, info.part)
-	info.processes = append(info.processes, Toks{tok})
+	info.operands = append(info.operands, info.part)
+	info.ops = append(info.ops, tok)
 	info.part = Toks{}
 }
 
-func (b *Builder) exprValuePart(info *exprProcessInfo, tok Tok) {
+func (b *Builder) exprValuePart(info *exprPartsInfo, tok Tok) {
 	if info.i > 0 && info.braceCount == 0 {
 		lt := info.toks[info.i-1]
 		if (lt.Id == tokens.Id || lt.Id == tokens.Value) &&
@@ -2404,7 +2412,7 @@ func (b *Builder) exprValuePart(info *exprProcessInfo, tok Tok) {
 	info.value = false
 }
 
-func (b *Builder) exprBracePart(info *exprProcessInfo, tok Tok) bool {
+func (b *Builder) exprBracePart(info *exprPartsInfo, tok Tok) bool {
 	switch tok.Kind {
 	case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
 		if tok.Kind == tokens.LBRACKET {
@@ -2425,8 +2433,10 @@ func (b *Builder) exprBracePart(info *exprProcessInfo, tok Tok) bool {
 	return false
 }
 
-func (b *Builder) exprProcesses(toks Toks) []Toks {
-	var info exprProcessInfo
+// exprParts splits tokens into operands and binary operators between
+// operands. Returns nil operands if tokens have syntax errors.
+func (b *Builder) exprParts(toks Toks) (operands []Toks, ops []Tok) {
+	var info exprPartsInfo
 	info.toks = toks
 	for ; info.i < len(info.toks); info.i++ {
 		tok := info.toks[info.i]
@@ -2446,17 +2456,21 @@ func (b *Builder) exprProcesses(toks Toks) []Toks {
 		}
 		b.exprValuePart(&info, tok)
 	}
-	if len(info.part) > 0 {
-		info.processes = append(info.processes, info.part)
-	}
 	if info.value {
-		b.pusherr(info.processes[len(info.processes)-1][0], "operator_overflow")
+		errTok := info.part
+		if len(errTok) == 0 {
+			errTok = info.ops[len(info.ops)-1:]
+		}
+		b.pusherr(errTok[0], "operator_overflow")
 		info.pushedError = true
 	}
-	if info.pushedError {
-		return nil
+	if len(info.part) > 0 {
+		info.operands = append(info.operands, info.part)
+	}
+	if info.pushedError || len(info.operands) == 0 {
+		return nil, nil
 	}
-	return info.processes
+	return info.operands, info.ops
 }
 
 func (b *Builder) checkExprTok(tok Tok) {
@@ -0,0 +1,369 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package ast
+
+import (
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+// exprTree returns expression tree of tokens.
+// Binary operators are grouped by precedence, from left to right.
+// Returns nil if tokens have syntax errors.
+func (b *Builder) exprTree(toks Toks) models.ExprNode {
+	operands, ops := b.exprParts(toks)
+	if operands == nil {
+		return nil
+	}
+	n := len(ops)
+	for _, operand := range operands {
+		n += len(operand)
+	}
+	// Tokens without comments, spans of nodes are subslices of it.
+	spans := make(Toks, 0, n)
+	var nodes []models.ExprNode
+	var starts, ends []int
+	for i, operand := range operands {
+		if i > 0 {
+			spans = append(spans, ops[i-1])
+		}
+		starts = append(starts, len(spans))
+		spans = append(spans, operand...)
+		ends = append(ends, len(spans))
+		nodes = append(nodes, Operand(spans[starts[i]:]))
+	}
+	for len(ops) > 0 {
+		i := nextOperator(ops)
+		nodes[i] = &models.BinaryExpr{
+			Toks:  spans[starts[i]:ends[i+1]],
+			Op:    ops[i],
+			Left:  nodes[i],
+			Right: nodes[i+1],
+		}
+		ends[i] = ends[i+1]
+		nodes = append(nodes[:i+1], nodes[i+2:]...)
+		ends = append(ends[:i+1], ends[i+2:]...)
+		starts = append(starts[:i+1], starts[i+2:]...)
+		ops = append(ops[:i], ops[i+1:]...)
+	}
+	return nodes[0]
+}
+
+func nextOperator(ops []Tok) int {
+	next := 0
+	prec := -1
+	for i, op := range ops {
+		if p := BinaryPrecedence(op.Kind); p > prec {
+			next = i
+			prec = p
+		}
+	}
+	return next
+}
+
+// subExpr returns expression tree of tokens of
+// nested expression, such as index or arguments.
+// Returns nil if tokens are empty.
+func subExpr(toks Toks) models.ExprNode {
+	if len(toks) == 0 {
+		return nil
+	}
+	if n := new(Builder).exprTree(toks); n != nil {
+		return n
+	}
+	return &models.BadExpr{Toks: toks}
+}
+
+// Operand returns expression tree of single operand.
+// Returns nil if tokens are empty.
+func Operand(toks Toks) models.ExprNode {
+	switch len(toks) {
+	case 0:
+		return nil
+	case 1:
+		return singleOperand(toks)
+	}
+	if tok := toks[0]; tok.Id == tokens.Operator {
+		return &models.UnaryExpr{Toks: toks, Op: tok, Expr: Operand(toks[1:])}
+	}
+	tok := toks[len(toks)-1]
+	switch tok.Id {
+	case tokens.Id:
+		return idOperand(toks)
+	case tokens.Operator:
+		if tok.Kind == tokens.TRIPLE_DOT {
+			return &models.VariadicExpr{
+				Toks: toks,
+				Op:   tok,
+				Expr: Operand(toks[:len(toks)-1]),
+			}
+		}
+		return &models.BadExpr{Toks: toks, Tok: tok}
+	case tokens.Brace:
+		switch tok.Kind {
+		case tokens.RPARENTHESES:
+			return parenOperand(toks)
+		case tokens.RBRACE:
+			return braceOperand(toks)
+		case tokens.RBRACKET:
+			return bracketOperand(toks)
+		}
+	}
+	return &models.BadExpr{Toks: toks, Tok: toks[0]}
+}
+
+func singleOperand(toks Toks) models.ExprNode {
+	tok := toks[0]
+	switch tok.Id {
+	case tokens.Value:
+		return &models.LitExpr{Toks: toks, Tok: tok}
+	case tokens.Id, tokens.Self:
+		return &models.IdExpr{Toks: toks, Tok: tok}
+	}
+	return &models.BadExpr{Toks: toks, Tok: tok}
+}
+
+func idOperand(toks Toks) models.ExprNode {
+	tok := toks[len(toks)-2]
+	switch tok.Id {
+	case tokens.Dot:
+		return &models.SelectorExpr{
+			Toks: toks,
+			Expr: Operand(toks[:len(toks)-2]),
+			Dot:  tok,
+			Id:   toks[len(toks)-1],
+		}
+	case tokens.DoubleColon:
+		return nsSelectorOperand(toks)
+	}
+	return &models.BadExpr{Toks: toks, Tok: tok}
+}
+
+// nsSelectorOperand returns namespace selection of tokens.
+// Identifiers of selection must be separated with double colons.
+func nsSelectorOperand(toks Toks) models.ExprNode {
+	n := &models.NsSelectorExpr{Toks: toks, Id: toks[len(toks)-1]}
+	for i, tok := range toks[:len(toks)-1] {
+		if i%2 == 1 {
+			if tok.Id != tokens.DoubleColon {
+				return &models.BadExpr{Toks: toks, Tok: tok}
+			}
+			continue
+		}
+		if tok.Id != tokens.Id {
+			return &models.BadExpr{Toks: toks, Tok: tok}
+		}
+		n.Ns = append(n.Ns, tok)
+	}
+	return n
+}
+
+func parenOperand(toks Toks) models.ExprNode {
+	if tok := toks[0]; tok.Id == tokens.Brace && tok.Kind == tokens.LPARENTHESES {
+		if cast := castOperand(toks); cast != nil {
+			return cast
+		}
+	}
+	return callOperand(toks)
+}
+
+// castOperand returns cast expression if tokens are syntactically
+// type casting, returns nil if not.
+func castOperand(toks Toks) models.ExprNode {
+	braceCount := 0
+	for i, tok := range toks {
+		if tok.Id == tokens.Brace {
+			switch tok.Kind {
+			case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
+				braceCount++
+				continue
+			default:
+				braceCount--
+			}
+		}
+		if braceCount > 0 {
+			continue
+		} else if i+1 == len(toks) {
+			return nil
+		}
+		b := NewBuilder(nil)
+		dtindex := 0
+		typeToks := toks[1:i]
+		t, ok := b.DataType(typeToks, &dtindex, false, false)
+		b.Wait()
+		if !ok || dtindex+1 < len(typeToks) {
+			return nil
+		}
+		exprToks := toks[i+1:]
+		tok = exprToks[0]
+		if tok.Id != tokens.Brace || tok.Kind != tokens.LPARENTHESES {
+			return nil
+		}
+		j := 0
+		exprToks = Range(&j, tokens.LPARENTHESES, tokens.RPARENTHESES, exprToks)
+		if exprToks == nil {
+			return nil
+		}
+		return &models.CastExpr{
+			Toks: toks,
+			Type: t,
+			Expr: subExpr(exprToks),
+			Call: callOperand(toks),
+		}
+	}
+	return nil
+}
+
+func callOperand(toks Toks) models.ExprNode {
+	expr, rang := RangeLast(toks)
+	if len(expr) == 0 {
+		return &models.ParenExpr{Toks: toks, Expr: subExpr(rang[1 : len(rang)-1])}
+	}
+	var generics Toks
+	if tok := expr[len(expr)-1]; tok.Id == tokens.Brace && tok.Kind == tokens.RBRACKET {
+		expr, generics = RangeLast(expr)
+	}
+	return &models.CallExpr{
+		Toks:     toks,
+		Fn:       Operand(expr),
+		Generics: generics,
+		Range:    rang,
+		Args:     callArgs(rang),
+	}
+}
+
+// callArgs returns arguments of call range.
+// Returns nil if arguments have syntax errors.
+func callArgs(rang Toks) *models.Args {
+	if tok := rang[0]; tok.Id != tokens.Brace || tok.Kind != tokens.LPARENTHESES {
+		return nil
+	}
+	b := new(Builder)
+	args := b.Args(rang[1:len(rang)-1], false)
+	if len(b.Errors) > 0 {
+		return nil
+	}
+	return args
+}
+
+func braceOperand(toks Toks) models.ExprNode {
+	expr, body := RangeLast(toks)
+	if len(expr) == 0 || body == nil {
+		return &models.BadExpr{Toks: toks, Tok: toks[0]}
+	}
+	tok := expr[0]
+	switch tok.Id {
+	case tokens.Id:
+		return compositeOperand(toks, expr, body)
+	case tokens.Brace:
+		switch tok.Kind {
+		case tokens.LBRACKET:
+			return compositeOperand(toks, expr, body)
+		case tokens.LPARENTHESES:
+			return anonFuncOperand(toks)
+		}
+	}
+	return &models.BadExpr{Toks: toks, Tok: tok}
+}
+
+func anonFuncOperand(toks Toks) models.ExprNode {
+	n := &models.AnonFuncExpr{Toks: toks}
+	b := NewBuilder(toks)
+	f := b.Func(b.Toks, true, false)
+	b.Wait()
+	if len(b.Errors) > 0 {
+		n.Errors = b.Errors
+	} else {
+		n.Func = &f
+	}
+	return n
+}
+
+func compositeOperand(toks, typeToks, body Toks) models.ExprNode {
+	n := &models.CompositeExpr{Toks: toks, Body: body}
+	b := NewBuilder(nil)
+	i := 0
+	t, ok := b.DataType(typeToks, &i, true, true)
+	b.Wait()
+	if ok && len(b.Errors) == 0 && i+1 == len(typeToks) {
+		n.Type = &t
+	}
+	if elems, errs := Elems(body); len(errs) == 0 {
+		n.Elems = elems
+	}
+	return n
+}
+
+// Elems returns expression trees of elements of composite literal body.
+// Body is includes braces. Keyed elements are KeyValueExpr,
+// and element is nil if it is empty.
+func Elems(body Toks) ([]models.ExprNode, []jnlog.CompilerLog) {
+	parts, errs := Parts(body[1:len(body)-1], tokens.Comma, true)
+	elems := make([]models.ExprNode, len(parts))
+	for i, part := range parts {
+		elems[i] = elemOperand(part)
+	}
+	return elems, errs
+}
+
+func elemOperand(toks Toks) models.ExprNode {
+	braceCount := 0
+	for i, tok := range toks {
+		if tok.Id == tokens.Brace {
+			switch tok.Kind {
+			case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
+				braceCount++
+			default:
+				braceCount--
+			}
+		}
+		if braceCount != 0 || tok.Id != tokens.Colon {
+			continue
+		}
+		return &models.KeyValueExpr{
+			Toks:  toks,
+			Key:   subExpr(toks[:i]),
+			Colon: tok,
+			Value: subExpr(toks[i+1:]),
+		}
+	}
+	return subExpr(toks)
+}
+
+func bracketOperand(toks Toks) models.ExprNode {
+	expr, rang := RangeLast(toks)
+	if len(expr) == 0 || rang == nil {
+		return &models.BadExpr{Toks: toks, Tok: toks[0]}
+	}
+	if rangeToks, colon := SplitColon(rang, new(int)); colon != -1 {
+		return &models.SliceExpr{
+			Toks: toks,
+			Expr: subExpr(expr),
+			Low:  subExpr(rangeToks[:colon]),
+			High: subExpr(rangeToks[colon+1:]),
+		}
+	}
+	return &models.IndexExpr{
+		Toks:  toks,
+		Expr:  subExpr(expr),
+		Index: subExpr(rang[1 : len(rang)-1]),
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/lexer"
+	"github.com/DeRuneLabs/jane/package/jnio"
+)
+
+func lex(t *testing.T, src string, comments bool) Toks {
+	t.Helper()
+	l := lexer.NewLex(&jnio.File{Name: "main.jn", Data: []rune(src)})
+	l.KeepComments = comments
+	toks := l.Lex()
+	if len(l.Logs) > 0 {
+		t.Fatalf("%q: lexer logs: %v", src, l.Logs)
+	}
+	return toks
+}
+
+// expr returns expression of source code and errors of builder.
+func expr(t *testing.T, src string) (models.Expr, *Builder) {
+	t.Helper()
+	b := NewBuilder(nil)
+	e := b.Expr(lex(t, src, false))
+	return e, b
+}
+
+func spanString(toks Toks) string {
+	kinds := make([]string, len(toks))
+	for i, tok := range toks {
+		kinds[i] = tok.Kind
+	}
+	return strings.Join(kinds, " ")
+}
+
+// sexpr returns tree in s-expression form.
+// Absent nodes are "_".
+func sexpr(n models.ExprNode) string {
+	switch t := n.(type) {
+	case nil:
+		return "_"
+	case *models.BinaryExpr:
+		return fmt.Sprintf("(%s %s %s)", t.Op.Kind, sexpr(t.Left), sexpr(t.Right))
+	case *models.UnaryExpr:
+		return fmt.Sprintf("(%s %s)", t.Op.Kind, sexpr(t.Expr))
+	case *models.ParenExpr:
+		return fmt.Sprintf("(paren %s)", sexpr(t.Expr))
+	case *models.LitExpr:
+		return t.Tok.Kind
+	case *models.IdExpr:
+		return t.Tok.Kind
+	case *models.SelectorExpr:
+		return fmt.Sprintf("(. %s %s)", sexpr(t.Expr), t.Id.Kind)
+	case *models.NsSelectorExpr:
+		return fmt.Sprintf("(:: %s %s)", spanString(t.Ns), t.Id.Kind)
+	case *models.CallExpr:
+		args := "bad"
+		if t.Args != nil {
+			args = fmt.Sprint(len(t.Args.Src))
+		}
+		return fmt.Sprintf("(call %s %s)", sexpr(t.Fn), args)
+	case *models.CastExpr:
+		return fmt.Sprintf("(cast %s %s)", t.Type.Kind, sexpr(t.Expr))
+	case *models.IndexExpr:
+		return fmt.Sprintf("(index %s %s)", sexpr(t.Expr), sexpr(t.Index))
+	case *models.SliceExpr:
+		return fmt.Sprintf("(slice %s %s %s)", sexpr(t.Expr), sexpr(t.Low), sexpr(t.High))
+	case *models.CompositeExpr:
+		elems := make([]string, len(t.Elems))
+		for i, elem := range t.Elems {
+			elems[i] = " " + sexpr(elem)
+		}
+		typ := "_"
+		if t.Type != nil {
+			typ = t.Type.Kind
+		}
+		return fmt.Sprintf("(composite %s%s)", typ, strings.Join(elems, ""))
+	case *models.KeyValueExpr:
+		return fmt.Sprintf("(: %s %s)", sexpr(t.Key), sexpr(t.Value))
+	case *models.AnonFuncExpr:
+		if t.Func == nil {
+			return "(func bad)"
+		}
+		return fmt.Sprintf("(func %d)", len(t.Func.Params))
+	case *models.VariadicExpr:
+		return fmt.Sprintf("(... %s)", sexpr(t.Expr))
+	case *models.BadExpr:
+		return "bad"
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func TestExprTree(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{"x", "x"},
+		{"1 + 2", "(+ 1 2)"},
+		{"1 + 2 * 3", "(+ 1 (* 2 3))"},
+		{"1 * 2 + 3", "(+ (* 1 2) 3)"},
+		{"1 - 2 - 3", "(- (- 1 2) 3)"},
+		{"a || b && c", "(|| a (&& b c))"},
+		{"a == b || c < d", "(|| (== a b) (< c d))"},
+		{"x & 1 == 0", "(== (& x 1) 0)"},
+		{"-x * y", "(* (- x) y)"},
+		{"!(a && b)", "(! (paren (&& a b)))"},
+		{"(1 + 2) * 3", "(* (paren (+ 1 2)) 3)"},
+		{"a.b.c", "(. (. a b) c)"},
+		{".x", "(. _ x)"},
+		{"std::math::pi", "(:: std math pi)"},
+		{"f(1, g(2))", "(call f 2)"},
+		{"x.f() + 1", "(+ (call (. x f) 0) 1)"},
+		{"xs[i + 1]", "(index xs (+ i 1))"},
+		{"xs[1:]", "(slice xs 1 _)"},
+		{"xs[:n]", "(slice xs _ n)"},
+		{"(int)(x + 1)", "(cast int (+ x 1))"},
+		{"[]int{1, 2}", "(composite []int 1 2)"},
+		{"point{x: 1, y: 2}", "(composite point (: x 1) (: y 2))"},
+		{"(a int, b int) int { ret a + b }", "(func 2)"},
+		{"xs...", "(... xs)"},
+		{"&x.y", "(& (. x y))"},
+	}
+	for _, c := range cases {
+		e, b := expr(t, c.src)
+		if len(b.Errors) > 0 {
+			t.Errorf("%q: unexpected errors: %v", c.src, b.Errors)
+			continue
+		}
+		if got := sexpr(e.Tree); got != c.want {
+			t.Errorf("%q: got %s, want %s", c.src, got, c.want)
+		}
+	}
+}
+
+func TestExprTreeErrors(t *testing.T) {
+	for _, src := range []string{"1 +", "a b", "1 2"} {
+		e, b := expr(t, src)
+		if len(b.Errors) == 0 {
+			t.Errorf("%q: expected errors", src)
+		}
+		if e.Tree != nil {
+			t.Errorf("%q: expected nil tree, got %s", src, sexpr(e.Tree))
+		}
+	}
+}
+
+func TestExprTreeSpans(t *testing.T) {
+	e, _ := expr(t, "a + b * (c - d)")
+	root := e.Tree.(*models.BinaryExpr)
+	spans := []struct {
+		n    models.ExprNode
+		want string
+	}{
+		{root, "a + b * ( c - d )"},
+		{root.Left, "a"},
+		{root.Right, "b * ( c - d )"},
+		{root.Right.(*models.BinaryExpr).Right, "( c - d )"},
+		{root.Right.(*models.BinaryExpr).Right.(*models.ParenExpr).Expr, "c - d"},
+	}
+	for _, s := range spans {
+		if got := spanString(s.n.Span()); got != s.want {
+			t.Errorf("span of %s: got %q, want %q", sexpr(s.n), got, s.want)
+		}
+	}
+
+	// Comments are not in spans.
+	b := NewBuilder(nil)
+	e = b.Expr(lex(t, "a /* left */ + /* right */ b", true))
+	if got := spanString(e.Tree.Span()); got != "a + b" {
+		t.Errorf("span with comments: got %q, want %q", got, "a + b")
+	}
+}
+
+func TestAnonFuncErrors(t *testing.T) {
+	e, _ := expr(t, "(a int) { a: = }")
+	n, ok := e.Tree.(*models.AnonFuncExpr)
+	if !ok {
+		t.Fatalf("not an anonymous function: %s", sexpr(e.Tree))
+	}
+	if n.Func != nil || len(n.Errors) == 0 {
+		t.Errorf("expected syntax errors of function, got %s", sexpr(n))
+	}
+}
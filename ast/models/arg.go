@@ -25,7 +25,3 @@ type Arg struct {
 	TargetId string
 	Expr     Expr
 }
-
-func (a Arg) String() string {
-	return a.Expr.String()
-}
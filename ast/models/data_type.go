@@ -24,8 +24,6 @@ import (
 	"strings"
 	"unicode"
 
-	"github.com/DeRuneLabs/jane/lexer/tokens"
-	"github.com/DeRuneLabs/jane/package/jnapi"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
 
@@ -138,156 +136,6 @@ func (dt *DataType) Pointers() string {
 	return ""
 }
 
-func (dt DataType) String() (s string) {
-	dt.SetToOriginal()
-	if dt.MultiTyped {
-		return dt.MultiTypeString()
-	}
-	i := strings.LastIndex(dt.Kind, tokens.DOUBLE_COLON)
-	if i != -1 {
-		dt.Kind = dt.Kind[i+len(tokens.DOUBLE_COLON):]
-	}
-	pointers := dt.Pointers()
-
-	defer func() {
-		var cpp strings.Builder
-		for range pointers {
-			cpp.WriteString("ptr<")
-		}
-		cpp.WriteString(s)
-		for range pointers {
-			cpp.WriteString(">")
-		}
-		s = cpp.String()
-	}()
-	dt.Kind = dt.Kind[len(pointers):]
-	switch dt.Id {
-	case jntype.Slice:
-		return dt.SliceString()
-	case jntype.Array:
-		return dt.ArrayString()
-	case jntype.Map:
-		return dt.MapString()
-	}
-	switch dt.Tag.(type) {
-	case CompiledStruct:
-		return dt.StructString()
-	}
-	switch dt.Id {
-	case jntype.Id:
-		if dt.Generic {
-			return jnapi.AsId(dt.Kind)
-		}
-		return jnapi.OutId(dt.Kind, dt.Tok.File)
-	case jntype.Enum:
-		return
-	case jntype.Trait:
-		return dt.TraitString()
-	case jntype.Struct:
-		return dt.StructString()
-	case jntype.Func:
-		return dt.FuncString()
-	default:
-		return jntype.CppId(dt.Id)
-	}
-}
-
-func (dt *DataType) SliceString() string {
-	var cpp strings.Builder
-	cpp.WriteString("slice<")
-	dt.ComponentType.Pure = dt.Pure
-	cpp.WriteString(dt.ComponentType.String())
-	cpp.WriteByte('>')
-	return cpp.String()
-}
-
-func (dt *DataType) ArrayString() string {
-	var cpp strings.Builder
-	cpp.WriteString("array<")
-	dt.ComponentType.Pure = dt.Pure
-	cpp.WriteString(dt.ComponentType.String())
-	cpp.WriteByte(',')
-	cpp.WriteString(dt.Size.Expr.String())
-	cpp.WriteByte('>')
-	return cpp.String()
-}
-
-func (dt *DataType) MapString() string {
-	var cpp strings.Builder
-	types := dt.Tag.([]DataType)
-	cpp.WriteString("map<")
-	key := types[0]
-	key.Pure = dt.Pure
-	cpp.WriteString(key.String())
-	cpp.WriteByte(',')
-	value := types[1]
-	value.Pure = dt.Pure
-	cpp.WriteString(value.String())
-	cpp.WriteByte('>')
-	return cpp.String()
-}
-
-func (dt *DataType) TraitString() string {
-	var cpp strings.Builder
-	id, _ := dt.KindId()
-	cpp.WriteString("trait<")
-	cpp.WriteString(jnapi.OutId(id, dt.Tok.File))
-	cpp.WriteByte('>')
-	return cpp.String()
-}
-
-func (dt *DataType) StructString() string {
-	var cpp strings.Builder
-	s := dt.Tag.(CompiledStruct)
-	cpp.WriteString(s.OutId())
-	types := s.Generics()
-	if len(types) == 0 {
-		return cpp.String()
-	}
-	cpp.WriteByte('<')
-	for _, t := range types {
-		t.Pure = dt.Pure
-		cpp.WriteString(t.String())
-		cpp.WriteByte(',')
-	}
-	return cpp.String()[:cpp.Len()-1] + ">"
-}
-
-func (dt *DataType) FuncString() string {
-	var cpp strings.Builder
-	cpp.WriteString("func<std::function<")
-	f := dt.Tag.(*Func)
-	f.RetType.Type.Pure = dt.Pure
-	cpp.WriteString(f.RetType.String())
-	cpp.WriteByte('(')
-	if len(f.Params) > 0 {
-		for _, param := range f.Params {
-			param.Type.Pure = dt.Pure
-			cpp.WriteString(param.Prototype())
-			cpp.WriteByte(',')
-		}
-		cxxStr := cpp.String()[:cpp.Len()-1]
-		cpp.Reset()
-		cpp.WriteString(cxxStr)
-	} else {
-		cpp.WriteString("void")
-	}
-	cpp.WriteString(")>>")
-	return cpp.String()
-}
-
-func (dt *DataType) MultiTypeString() string {
-	types := dt.Tag.([]DataType)
-	var cpp strings.Builder
-	cpp.WriteString("std::tuple<")
-	for _, t := range types {
-		t.Pure = dt.Pure
-		cpp.WriteString(t.String())
-		cpp.WriteByte(',')
-	}
-	return cpp.String()[:cpp.Len()-1] + ">" + dt.Pointers()
-}
-
 func (dt *DataType) MapKind() string {
 	types := dt.Tag.([]DataType)
 	var kind strings.Builder
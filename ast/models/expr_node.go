@@ -0,0 +1,191 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package models
+
+import "github.com/DeRuneLabs/jane/package/jnlog"
+
+// ExprNode is node of expression tree.
+type ExprNode interface {
+	// Span returns tokens of node.
+	Span() []Tok
+}
+
+// BinaryExpr is binary operation, such as "x + y".
+type BinaryExpr struct {
+	Toks  []Tok
+	Op    Tok
+	Left  ExprNode
+	Right ExprNode
+}
+
+func (e *BinaryExpr) Span() []Tok { return e.Toks }
+
+// UnaryExpr is unary operation, such as "-x" or "&x".
+type UnaryExpr struct {
+	Toks []Tok
+	Op   Tok
+	Expr ExprNode
+}
+
+func (e *UnaryExpr) Span() []Tok { return e.Toks }
+
+// ParenExpr is parenthesized expression.
+// Expr is nil if expression between parentheses is invalid.
+type ParenExpr struct {
+	Toks []Tok
+	Expr ExprNode
+}
+
+func (e *ParenExpr) Span() []Tok { return e.Toks }
+
+// LitExpr is literal value, such as number, string, char, bool or nil.
+type LitExpr struct {
+	Toks []Tok
+	Tok  Tok
+}
+
+func (e *LitExpr) Span() []Tok { return e.Toks }
+
+// IdExpr is single identifier, data-type or self.
+type IdExpr struct {
+	Toks []Tok
+	Tok  Tok
+}
+
+func (e *IdExpr) Span() []Tok { return e.Toks }
+
+// SelectorExpr is sub identifier selection, such as "x.y".
+// Expr is nil for selections of self, such as ".y".
+type SelectorExpr struct {
+	Toks []Tok
+	Expr ExprNode
+	Dot  Tok
+	Id   Tok
+}
+
+func (e *SelectorExpr) Span() []Tok { return e.Toks }
+
+// NsSelectorExpr is namespace selection, such as "x::y".
+// Ns are identifiers before last separator, such as "x".
+type NsSelectorExpr struct {
+	Toks []Tok
+	Ns   []Tok
+	Id   Tok
+}
+
+func (e *NsSelectorExpr) Span() []Tok { return e.Toks }
+
+// CallExpr is function call.
+// Generics and Range are includes their brackets and parentheses.
+// Args are arguments of Range, nil if arguments have syntax errors.
+type CallExpr struct {
+	Toks     []Tok
+	Fn       ExprNode
+	Generics []Tok
+	Range    []Tok
+	Args     *Args
+}
+
+func (e *CallExpr) Span() []Tok { return e.Toks }
+
+// CastExpr is type casting, such as "(int)(x)".
+// Call is call interpretation of expression,
+// it is used if Type is not a known type.
+type CastExpr struct {
+	Toks []Tok
+	Type DataType
+	Expr ExprNode
+	Call ExprNode
+}
+
+func (e *CastExpr) Span() []Tok { return e.Toks }
+
+// IndexExpr is indexing, such as "x[i]".
+type IndexExpr struct {
+	Toks  []Tok
+	Expr  ExprNode
+	Index ExprNode
+}
+
+func (e *IndexExpr) Span() []Tok { return e.Toks }
+
+// SliceExpr is slicing, such as "x[i:j]".
+// Low and High are nil if they are not given.
+type SliceExpr struct {
+	Toks []Tok
+	Expr ExprNode
+	Low  ExprNode
+	High ExprNode
+}
+
+func (e *SliceExpr) Span() []Tok { return e.Toks }
+
+// CompositeExpr is literal of struct, array, slice or map.
+// Body is includes braces, Elems are elements of Body.
+// Type is nil if type is not valid,
+// Elems are nil if Body has not elements or has syntax errors.
+type CompositeExpr struct {
+	Toks  []Tok
+	Type  *DataType
+	Body  []Tok
+	Elems []ExprNode
+}
+
+func (e *CompositeExpr) Span() []Tok { return e.Toks }
+
+// KeyValueExpr is keyed element of composite literal, such as "x: y".
+// Key and Value are nil if they are not given.
+type KeyValueExpr struct {
+	Toks  []Tok
+	Key   ExprNode
+	Colon Tok
+	Value ExprNode
+}
+
+func (e *KeyValueExpr) Span() []Tok { return e.Toks }
+
+// AnonFuncExpr is anonymous function.
+// Func is nil and Errors are syntax errors
+// if function has syntax errors.
+type AnonFuncExpr struct {
+	Toks   []Tok
+	Func   *Func
+	Errors []jnlog.CompilerLog
+}
+
+func (e *AnonFuncExpr) Span() []Tok { return e.Toks }
+
+// VariadicExpr is variadic expansion, such as "x...".
+type VariadicExpr struct {
+	Toks []Tok
+	Op   Tok
+	Expr ExprNode
+}
+
+func (e *VariadicExpr) Span() []Tok { return e.Toks }
+
+// BadExpr is invalid expression.
+// Tok is token of syntax error, Id of Tok is tokens.NA if unknown.
+type BadExpr struct {
+	Toks []Tok
+	Tok  Tok
+}
+
+func (e *BadExpr) Span() []Tok { return e.Toks }
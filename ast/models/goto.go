@@ -20,8 +20,6 @@
 
 package models
 
-import "strings"
-
 type Labels []*Label
 type Gotos []*Goto
 
@@ -33,21 +31,9 @@ type Label struct {
 	Block *Block
 }
 
-func (l Label) String() string {
-	return l.Label + ":;"
-}
-
 type Goto struct {
 	Tok   Tok
 	Label string
 	Index int
 	Block *Block
 }
-
-func (gt Goto) String() string {
-	var cpp strings.Builder
-	cpp.WriteString("goto ")
-	cpp.WriteString(gt.Label)
-	cpp.WriteByte(';')
-	return cpp.String()
-}
@@ -31,10 +31,14 @@ type Genericable interface {
 	SetGenerics([]DataType)
 }
 
+// IterProfile is profile of iteration.
+// Implemented by IterWhile, IterFor and IterForeach.
 type IterProfile interface {
-	String(iter Iter) string
+	iterProfile()
 }
 
+// IExprModel is checked model of expression.
+// Parser stores typed IR of expression as model.
 type IExprModel interface {
-	String() string
+	DataType() DataType
 }
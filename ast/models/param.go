@@ -24,7 +24,6 @@ import (
 	"strings"
 
 	"github.com/DeRuneLabs/jane/lexer/tokens"
-	"github.com/DeRuneLabs/jane/package/jn"
 	"github.com/DeRuneLabs/jane/package/jnapi"
 )
 
@@ -54,28 +53,3 @@ func (p *Param) TypeString() string {
 func (p *Param) OutId() string {
 	return jnapi.AsId(p.Id)
 }
-
-func (p Param) String() string {
-	var cpp strings.Builder
-	cpp.WriteString(p.Prototype())
-	if p.Id != "" && !jnapi.IsIgnoreId(p.Id) && p.Id != jn.Anonymous {
-		cpp.WriteByte(' ')
-		cpp.WriteString(p.OutId())
-	}
-	return cpp.String()
-}
-
-func (p *Param) Prototype() string {
-	var cpp strings.Builder
-	if p.Variadic {
-		cpp.WriteString("slice<")
-		cpp.WriteString(p.Type.String())
-		cpp.WriteByte('>')
-	} else {
-		cpp.WriteString(p.Type.String())
-	}
-	if p.Reference {
-		cpp.WriteByte('&')
-	}
-	return cpp.String()
-}
@@ -27,10 +27,6 @@ type RetType struct {
 	Identifiers Toks
 }
 
-func (rt RetType) String() string {
-	return rt.Type.String()
-}
-
 func (rt *RetType) AnyVar() bool {
 	for _, tok := range rt.Identifiers {
 		if !jnapi.IsIgnoreId(tok.Kind) {
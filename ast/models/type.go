@@ -21,10 +21,7 @@
 package models
 
 import (
-	"strings"
-
 	"github.com/DeRuneLabs/jane/lexer"
-	"github.com/DeRuneLabs/jane/package/jnapi"
 )
 
 type (
@@ -41,17 +38,3 @@ type Type struct {
 	Used    bool
 	Generic bool
 }
-
-func (t Type) String() string {
-	var cpp strings.Builder
-	cpp.WriteString("typedef ")
-	cpp.WriteString(t.Type.String())
-	cpp.WriteByte(' ')
-	if t.Generic {
-		cpp.WriteString(jnapi.AsId(t.Id))
-	} else {
-		cpp.WriteString(jnapi.OutId(t.Id, t.Tok.File))
-	}
-	cpp.WriteByte(';')
-	return cpp.String()
-}
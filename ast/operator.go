@@ -70,3 +70,23 @@ func existOperator(kind string, operators []string) bool {
 	}
 	return false
 }
+
+// BinaryPrecedence returns precedence level of binary operator.
+// Returns 0 if kind is not binary operator.
+func BinaryPrecedence(kind string) int {
+	switch kind {
+	case tokens.STAR, tokens.PERCENT, tokens.SOLIDUS,
+		tokens.RSHIFT, tokens.LSHIFT, tokens.AMPER:
+		return 5
+	case tokens.PLUS, tokens.MINUS, tokens.VLINE, tokens.CARET:
+		return 4
+	case tokens.EQUALS, tokens.NOT_EQUALS, tokens.LESS,
+		tokens.LESS_EQUAL, tokens.GREAT, tokens.GREAT_EQUAL:
+		return 3
+	case tokens.AND:
+		return 2
+	case tokens.OR:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package cpp implements C++ backend of Jane.
+//
+// Backend generates C++ code from typed IR of checked packages.
+// Generated code is depends on API headers of Jane.
+package cpp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+)
+
+// generator generates C++ code and holds indentation state.
+type generator struct {
+	indentKind  string
+	indentCount int
+	indent      int
+	lines       bool
+}
+
+// Generate returns C++ code of package.
+// Indentation of generated code is indentCount times of indentKind.
+// #line directives are emitted before statements and declarations
+// that have source position if lines is true.
+func Generate(pkg *ir.Package, indentKind string, indentCount int, lines bool) string {
+	g := &generator{
+		indentKind:  indentKind,
+		indentCount: indentCount,
+		lines:       lines,
+	}
+	return g.pkg(pkg)
+}
+
+// line returns #line directive of tok that followed by indentation.
+// Returns empty string if directives are disabled or tok has no file.
+func (g *generator) line(tok models.Tok) string {
+	if !g.lines || tok.File == nil {
+		return ""
+	}
+	return lineDirective(tok.Row, tok.File.Path()) + "\n" + g.indentString()
+}
+
+// reset returns indented #line directive line that resets mapping
+// of code after it to generated code.
+// Returns empty string if directives are disabled.
+func (g *generator) reset() string {
+	if !g.lines {
+		return ""
+	}
+	return g.indentString() + lineDirective(1, generatedPath) + "\n"
+}
+
+func (g *generator) indentString() string {
+	return strings.Repeat(g.indentKind, g.indent*g.indentCount)
+}
+
+func (g *generator) addIndent() { g.indent++ }
+
+func (g *generator) doneIndent() { g.indent-- }
+
+func (g *generator) pkg(pkg *ir.Package) string {
+	var cpp strings.Builder
+	for _, link := range pkg.Links {
+		cpp.WriteString(`#include "`)
+		cpp.WriteString(link)
+		cpp.WriteString("\"\n")
+	}
+	cpp.WriteByte('\n')
+	for _, t := range pkg.Types {
+		cpp.WriteString(g.line(t.Tok))
+		cpp.WriteString(g.typeAlias(t))
+		cpp.WriteByte('\n')
+	}
+	cpp.WriteByte('\n')
+	for _, e := range pkg.Enums {
+		cpp.WriteString(g.line(e.Ast.Tok))
+		cpp.WriteString(g.enum(e))
+		cpp.WriteString("\n\n")
+	}
+	for _, t := range pkg.Traits {
+		cpp.WriteString(g.line(t.Ast.Tok))
+		cpp.WriteString(g.trait(t))
+		cpp.WriteString("\n\n")
+	}
+	for _, s := range pkg.Structs {
+		cpp.WriteString(g.line(s.Ast.Tok))
+		cpp.WriteString(g.structPrototype(s))
+		cpp.WriteByte('\n')
+	}
+	for _, f := range pkg.Funcs {
+		cpp.WriteString(g.funcPrototype(f))
+		cpp.WriteByte('\n')
+	}
+	for _, s := range pkg.Structs {
+		cpp.WriteString(g.jnstruct(s))
+		cpp.WriteString("\n\n")
+	}
+	cpp.WriteString("\n\n")
+	for _, v := range pkg.Globals {
+		cpp.WriteString(g.line(v.Tok))
+		cpp.WriteString(g.varDecl(v))
+		cpp.WriteByte('\n')
+	}
+	cpp.WriteString("\n\n")
+	for _, f := range pkg.Funcs {
+		cpp.WriteString(g.fn(f))
+		cpp.WriteString("\n\n")
+	}
+	cpp.WriteString(g.reset())
+	cpp.WriteString(g.initializerCaller(pkg.Inits))
+	if pkg.IsTest {
+		cpp.WriteString("\n\n")
+		cpp.WriteString(g.testRunner(pkg.Tests))
+	}
+	return cpp.String()
+}
+
+func (g *generator) initializerCaller(inits []*ir.Func) string {
+	var cpp strings.Builder
+	cpp.WriteString("void ")
+	cpp.WriteString(jnapi.InitializerCaller)
+	cpp.WriteString("(void) {")
+	g.addIndent()
+	indent := g.indentString()
+	g.doneIndent()
+	for _, f := range inits {
+		cpp.WriteByte('\n')
+		cpp.WriteString(indent)
+		cpp.WriteString(funcOutId(f))
+		cpp.WriteString("();")
+	}
+	cpp.WriteString("\n}")
+	return cpp.String()
+}
+
+func (g *generator) testCase(f *ir.Func) string {
+	id := strconv.Quote(f.Ast.Id)
+	var cpp strings.Builder
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("std::cout << \"=== RUN   \" << ")
+	cpp.WriteString(id)
+	cpp.WriteString(" << std::endl;\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("try {\n")
+	g.addIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(funcOutId(f))
+	cpp.WriteString("();\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("++_passed;\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("std::cout << \"--- PASS: \" << ")
+	cpp.WriteString(id)
+	cpp.WriteString(" << std::endl;\n")
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("} catch (trait<JNID(Error)> _error) {\n")
+	g.addIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("++_failed;\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("std::cout << \"--- FAIL: \" << ")
+	cpp.WriteString(id)
+	cpp.WriteString(" << \": \" << _error.get().error() << std::endl;\n")
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("}\n")
+	return cpp.String()
+}
+
+func (g *generator) testIf(cpp *strings.Builder, cond string, stmts ...string) {
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("if (")
+	cpp.WriteString(cond)
+	cpp.WriteString(") {\n")
+	g.addIndent()
+	for _, s := range stmts {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(s)
+		cpp.WriteByte('\n')
+	}
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("}\n")
+}
+
+// testRunner returns entry point that runs tests and reports results.
+func (g *generator) testRunner(tests []*ir.Func) string {
+	var cpp strings.Builder
+	cpp.WriteString("void ")
+	cpp.WriteString(jnapi.OutId(jn.EntryPoint, nil))
+	cpp.WriteString("(void) {\n")
+	g.addIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("u64_jnt _passed{0}, _failed{0};\n")
+	for _, f := range tests {
+		cpp.WriteString(g.testCase(f))
+	}
+	g.testIf(&cpp, "_passed+_failed == 0", "std::cout << \"no tests to run\" << std::endl;", "return;")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("std::cout << _passed << \" passed, \" << _failed << \" failed\" << std::endl;\n")
+	g.testIf(&cpp, "_failed != 0", "std::exit(EXIT_FAILURE);")
+	g.doneIndent()
+	cpp.WriteString("}")
+	return cpp.String()
+}
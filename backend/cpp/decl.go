@@ -0,0 +1,388 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+)
+
+func funcOutId(f *ir.Func) string {
+	if f.Entry {
+		return jnapi.OutId(f.Ast.Id, nil)
+	}
+	return f.Ast.OutId()
+}
+
+func isOutableAttribute(kind string) bool {
+	return kind == jn.Attribute_Inline
+}
+
+func attributes(attributes []models.Attribute) string {
+	var cpp strings.Builder
+	for _, attr := range attributes {
+		if isOutableAttribute(attr.Tag) {
+			cpp.WriteString(attr.String())
+			cpp.WriteByte(' ')
+		}
+	}
+	return cpp.String()
+}
+
+func (g *generator) funcDeclHead(f *ir.Func) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.line(f.Ast.Tok))
+	if generics := genericsDecl(f.Ast.Generics); generics != "" {
+		cpp.WriteString(generics)
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.line(f.Ast.Tok))
+	}
+	cpp.WriteString(attributes(f.Ast.Attributes))
+	cpp.WriteString(g.typ(f.Ast.RetType.Type))
+	cpp.WriteByte(' ')
+	cpp.WriteString(funcOutId(f))
+	return cpp.String()
+}
+
+func (g *generator) fn(f *ir.Func) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.funcDeclHead(f))
+	cpp.WriteString(g.params(f.Ast.Params))
+	cpp.WriteByte(' ')
+	cpp.WriteString(g.block(f.Body))
+	return cpp.String()
+}
+
+func (g *generator) funcPrototype(f *ir.Func) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.funcDeclHead(f))
+	cpp.WriteString(g.prototypeParams(f.Ast.Params))
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func (g *generator) typeAlias(t *ir.TypeAlias) string {
+	var cpp strings.Builder
+	cpp.WriteString("typedef ")
+	cpp.WriteString(g.typ(t.Ast.Type))
+	cpp.WriteByte(' ')
+	if t.Ast.Generic {
+		cpp.WriteString(jnapi.AsId(t.Ast.Id))
+	} else {
+		cpp.WriteString(jnapi.OutId(t.Ast.Id, t.Ast.Tok.File))
+	}
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func (g *generator) enum(e *ir.Enum) string {
+	var cpp strings.Builder
+	cpp.WriteString("enum ")
+	cpp.WriteString(jnapi.OutId(e.Ast.Id, e.Ast.Tok.File))
+	cpp.WriteByte(':')
+	cpp.WriteString(g.typ(e.Ast.Type))
+	cpp.WriteString(" {\n")
+	g.addIndent()
+	for _, item := range e.Items {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.line(item.Ast.Tok))
+		cpp.WriteString(jnapi.OutId(item.Ast.Id, item.Ast.Tok.File))
+		cpp.WriteString(" = ")
+		cpp.WriteString(g.expr(item.Value))
+		cpp.WriteString(",\n")
+	}
+	g.doneIndent()
+	cpp.WriteString("};")
+	return cpp.String()
+}
+
+func traitOutId(t *ir.Trait) string {
+	return jnapi.OutId(t.Ast.Id, t.Ast.Tok.File)
+}
+
+func (g *generator) trait(t *ir.Trait) string {
+	var cpp strings.Builder
+	cpp.WriteString("struct ")
+	cpp.WriteString(traitOutId(t))
+	cpp.WriteString(" {\n")
+	g.addIndent()
+	is := g.indentString()
+	for _, f := range t.Ast.Funcs {
+		cpp.WriteString(is)
+		cpp.WriteString(g.line(f.Tok))
+		cpp.WriteString("virtual ")
+		cpp.WriteString(g.typ(f.RetType.Type))
+		cpp.WriteByte(' ')
+		cpp.WriteString(f.Id)
+		cpp.WriteString(g.params(f.Params))
+		cpp.WriteString(" = 0;\n")
+	}
+	g.doneIndent()
+	cpp.WriteString("};")
+	return cpp.String()
+}
+
+func (g *generator) varDecl(v *ir.VarDecl) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.typ(v.Var.Type))
+	cpp.WriteByte(' ')
+	cpp.WriteString(v.Var.OutId())
+	expr := g.expr(v.Init)
+	if expr != "" {
+		cpp.WriteString(" = ")
+		cpp.WriteString(expr)
+	} else {
+		cpp.WriteString(jnapi.DefaultExpr)
+	}
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func (g *generator) field(v *models.Var) string {
+	var cpp strings.Builder
+	if v.Const {
+		cpp.WriteString("const ")
+	}
+	cpp.WriteString(g.typ(v.Type))
+	cpp.WriteByte(' ')
+	cpp.WriteString(v.OutId())
+	cpp.WriteString(jnapi.DefaultExpr)
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func structOutId(s *ir.Struct) string {
+	return jnapi.OutId(s.Ast.Id, s.Ast.Tok.File)
+}
+
+// structGenerics returns template declaration and
+// template arguments of struct operators.
+func structGenerics(s *ir.Struct) (def string, serie string) {
+	if len(s.Ast.Generics) == 0 {
+		return "", ""
+	}
+	var cppDef strings.Builder
+	cppDef.WriteString("template<typename ")
+	var cppSerie strings.Builder
+	cppSerie.WriteByte('<')
+	for i := range s.Ast.Generics {
+		cppSerie.WriteByte('T')
+		cppSerie.WriteString(strconv.Itoa(i))
+		cppSerie.WriteByte(',')
+	}
+	serie = cppSerie.String()[:cppSerie.Len()-1] + ">"
+	cppDef.WriteString(serie[1:])
+	cppDef.WriteByte('\n')
+	return cppDef.String(), serie
+}
+
+func (g *generator) structOperators(s *ir.Struct) string {
+	outid := structOutId(s)
+	genericsDef, genericsSerie := structGenerics(s)
+	var cpp strings.Builder
+	cpp.WriteString(g.indentString())
+	if l, _ := cpp.WriteString(genericsDef); l > 0 {
+		cpp.WriteString(g.indentString())
+	}
+	cpp.WriteString("inline bool operator==(const ")
+	cpp.WriteString(outid)
+	cpp.WriteString(genericsSerie)
+	cpp.WriteString(" &_Src) {")
+	if len(s.Fields) > 0 {
+		g.addIndent()
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		var expr strings.Builder
+		expr.WriteString("return ")
+		g.addIndent()
+		for _, f := range s.Fields {
+			expr.WriteByte('\n')
+			expr.WriteString(g.indentString())
+			expr.WriteString("this->")
+			fid := f.OutId()
+			expr.WriteString(fid)
+			expr.WriteString(" == _Src.")
+			expr.WriteString(fid)
+			expr.WriteString(" &&")
+		}
+		g.doneIndent()
+		cpp.WriteString(expr.String()[:expr.Len()-3])
+		cpp.WriteString(";\n")
+		g.doneIndent()
+		cpp.WriteString(g.indentString())
+		cpp.WriteByte('}')
+	} else {
+		cpp.WriteString(" return true; }")
+	}
+	cpp.WriteString("\n\n")
+	cpp.WriteString(g.indentString())
+	if l, _ := cpp.WriteString(genericsDef); l > 0 {
+		cpp.WriteString(g.indentString())
+	}
+	cpp.WriteString("inline bool operator!=(const ")
+	cpp.WriteString(outid)
+	cpp.WriteString(genericsSerie)
+	cpp.WriteString(" &_Src) { return !this->operator==(_Src); }")
+	return cpp.String()
+}
+
+func (g *generator) structConstructor(s *ir.Struct) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(structOutId(s))
+	cpp.WriteString(g.params(s.Constructor.Params))
+	cpp.WriteString(" noexcept {")
+	if len(s.Fields) > 0 {
+		g.addIndent()
+		for i, f := range s.Fields {
+			cpp.WriteByte('\n')
+			cpp.WriteString(g.indentString())
+			cpp.WriteString("this->")
+			cpp.WriteString(f.OutId())
+			cpp.WriteString(" = __jnc_must_heap(")
+			cpp.WriteString(s.Constructor.Params[i].OutId())
+			cpp.WriteString(");")
+		}
+		g.doneIndent()
+		cpp.WriteByte('\n')
+	}
+	cpp.WriteString(g.indentString())
+	cpp.WriteByte('}')
+	return cpp.String()
+}
+
+func structTraits(s *ir.Struct) string {
+	if len(s.Traits) == 0 {
+		return ""
+	}
+	var cpp strings.Builder
+	cpp.WriteString(": ")
+	for _, t := range s.Traits {
+		cpp.WriteString("public ")
+		cpp.WriteString(traitOutId(t))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1]
+}
+
+func (g *generator) structPrototype(s *ir.Struct) string {
+	var cpp strings.Builder
+	cpp.WriteString(genericsDecl(s.Ast.Generics))
+	cpp.WriteString(" struct ")
+	cpp.WriteString(structOutId(s))
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func (g *generator) structDecl(s *ir.Struct) string {
+	var cpp strings.Builder
+	generics := genericsDecl(s.Ast.Generics)
+	if generics != "" {
+		cpp.WriteString(g.line(s.Ast.Tok))
+	}
+	cpp.WriteString(generics)
+	cpp.WriteByte('\n')
+	cpp.WriteString(g.line(s.Ast.Tok))
+	cpp.WriteString("struct ")
+	cpp.WriteString(structOutId(s))
+	cpp.WriteString(structTraits(s))
+	cpp.WriteString(" {\n")
+	g.addIndent()
+	for _, f := range s.Fields {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.line(f.Token))
+		cpp.WriteString(g.field(f))
+		cpp.WriteByte('\n')
+	}
+	cpp.WriteString(g.reset())
+	if len(s.Fields) > 0 {
+		cpp.WriteString("\n\n")
+		cpp.WriteString(g.structConstructor(s))
+		cpp.WriteString("\n\n")
+	}
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(structOutId(s))
+	cpp.WriteString("(void) noexcept {}\n\n")
+	for _, f := range s.Funcs {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.fn(f))
+		cpp.WriteString("\n\n")
+	}
+	cpp.WriteString(g.reset())
+	cpp.WriteString(g.structOperators(s))
+	cpp.WriteByte('\n')
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("};")
+	return cpp.String()
+}
+
+func (g *generator) structOstream(s *ir.Struct) string {
+	var cpp strings.Builder
+	genericsDef, genericsSerie := structGenerics(s)
+	cpp.WriteString(g.indentString())
+	if l, _ := cpp.WriteString(genericsDef); l > 0 {
+		cpp.WriteString(g.indentString())
+	}
+	cpp.WriteString("std::ostream &operator<<(std::ostream &_Stream, const ")
+	cpp.WriteString(structOutId(s))
+	cpp.WriteString(genericsSerie)
+	cpp.WriteString(" &_Src) {\n")
+	g.addIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(`_Stream << "`)
+	cpp.WriteString(s.Ast.Id)
+	cpp.WriteString("{\";\n")
+	for i, field := range s.Ast.Fields {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(`_Stream << "`)
+		cpp.WriteString(field.Id)
+		cpp.WriteString(`:" << _Src.`)
+		cpp.WriteString(field.OutId())
+		if i+1 < len(s.Ast.Fields) {
+			cpp.WriteString(" << \", \"")
+		}
+		cpp.WriteString(";\n")
+	}
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("_Stream << \"}\";\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("return _Stream;\n")
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString("}")
+	return cpp.String()
+}
+
+func (g *generator) jnstruct(s *ir.Struct) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.structDecl(s))
+	cpp.WriteString("\n\n")
+	cpp.WriteString(g.reset())
+	cpp.WriteString(g.structOstream(s))
+	return cpp.String()
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+// Severities of C++ compiler diagnostics.
+const (
+	severityError      = "error"
+	severityFatalError = "fatal error"
+	severityWarning    = "warning"
+	severityNote       = "note"
+)
+
+var (
+	// diagnosticLine matches "path:row:column: severity: message",
+	// column is optional.
+	diagnosticLine = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)? (fatal error|error|warning|note): (.*)$`)
+	// contextLine matches instantiation contexts such as
+	// "path:row:column:   required from here".
+	contextLine = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)?\s+(?:required|instantiated) from`)
+
+	jnidPattern  = regexp.MustCompile(`JNID\((\w+)\)`)
+	outIdPattern = regexp.MustCompile(`\bf[0-9a-f]{1,8}_(\w+)`)
+	typePattern  = regexp.MustCompile(`\b(\w+)_jnt\b`)
+	idPattern    = regexp.MustCompile(`(^|[^\w])_([a-z]\w*)`)
+)
+
+// Demangle replaces mangled identifiers of generated code in s
+// with identifiers of Jane.
+func Demangle(s string) string {
+	s = jnidPattern.ReplaceAllString(s, "$1")
+	s = outIdPattern.ReplaceAllString(s, "$1")
+	s = typePattern.ReplaceAllString(s, "$1")
+	return idPattern.ReplaceAllStringFunc(s, func(m string) string {
+		i := strings.IndexByte(m, '_')
+		if strings.HasPrefix(m[i:], "_jnc") {
+			return m
+		}
+		return m[:i] + m[i+1:]
+	})
+}
+
+// position is Jane source position of C++ diagnostic.
+type position struct {
+	path string
+	row  int
+}
+
+// diagnostics maps diagnostics of C++ compiler to Jane.
+type diagnostics struct {
+	cppPath  string
+	sm       *SourceMap
+	lines    map[int]Line
+	files    map[string]*jnio.File
+	errors   []jnlog.CompilerLog
+	warnings []jnlog.CompilerLog
+	context  *position
+	last     *jnlog.CompilerLog
+}
+
+// Diagnostics parses output of C++ compiler and returns diagnostics
+// that mapped to Jane sources. cppPath is path of compiled code and sm is
+// line map of it. Positions in Jane sources are used as is, so code that
+// has #line directives is mapped even if sm is nil.
+//
+// Errors without Jane position are returned as flat errors, warnings
+// without Jane position are ignored.
+func Diagnostics(output, cppPath string, sm *SourceMap) (errors, warnings []jnlog.CompilerLog) {
+	d := &diagnostics{
+		cppPath: cppPath,
+		sm:      sm,
+		lines:   map[int]Line{},
+		files:   map[string]*jnio.File{},
+	}
+	if sm != nil {
+		for _, l := range sm.Lines {
+			d.lines[l.Line] = l
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		d.parse(strings.TrimRight(line, "\r"))
+	}
+	return d.errors, d.warnings
+}
+
+// locate returns Jane position of C++ position.
+// Returns nil if position is not mapped.
+func (d *diagnostics) locate(path, row string) *position {
+	r, err := strconv.Atoi(row)
+	if err != nil {
+		return nil
+	}
+	if filepath.Ext(path) == jn.SrcExt {
+		return &position{path: path, row: r}
+	}
+	if d.sm == nil || filepath.Base(path) != filepath.Base(d.cppPath) {
+		return nil
+	}
+	l, ok := d.lines[r]
+	if !ok {
+		return nil
+	}
+	return &position{path: d.sm.Sources[l.Source], row: l.Row}
+}
+
+func (d *diagnostics) parse(line string) {
+	if m := contextLine.FindStringSubmatch(line); m != nil {
+		if pos := d.locate(m[1], m[2]); pos != nil {
+			d.context = pos
+		}
+		return
+	}
+	m := diagnosticLine.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	pos := d.locate(m[1], m[2])
+	msg := Demangle(m[5])
+	switch m[4] {
+	case severityNote:
+		if d.last != nil && pos != nil {
+			d.last.Notes = append(d.last.Notes, msg)
+		}
+		return
+	case severityWarning:
+		if pos == nil {
+			d.last = nil
+			return
+		}
+		d.warnings = append(d.warnings, d.log(pos, jnlog.Warning, "cxx_warning", jn.GetWarning("cxx_warning", msg)))
+		d.last = &d.warnings[len(d.warnings)-1]
+	default:
+		if pos == nil {
+			pos = d.context
+		}
+		if pos == nil {
+			msg = filepath.Base(m[1]) + ":" + m[2] + ": " + msg
+		}
+		d.errors = append(d.errors, d.log(pos, jnlog.Error, "cxx_error", jn.GetError("cxx_error", msg)))
+		d.last = &d.errors[len(d.errors)-1]
+	}
+	d.context = nil
+}
+
+// log returns log of position.
+// Log is flat error if position is nil.
+// Column is first column of source line that is not whitespace.
+func (d *diagnostics) log(pos *position, t uint8, key, msg string) jnlog.CompilerLog {
+	if pos == nil {
+		return jnlog.CompilerLog{Type: jnlog.FlatError, Key: key, Message: msg}
+	}
+	log := jnlog.CompilerLog{
+		Type:    t,
+		Row:     pos.row,
+		Column:  1,
+		Path:    pos.path,
+		Key:     key,
+		Message: msg,
+	}
+	f, ok := d.files[pos.path]
+	if !ok {
+		f, _ = jnio.OpenJn(pos.path)
+		d.files[pos.path] = f
+	}
+	if f == nil {
+		return log
+	}
+	log.Line = f.Line(pos.row)
+	code := strings.TrimLeft(log.Line, " \t")
+	// tab is four columns like lexer.
+	for _, r := range log.Line[:len(log.Line)-len(code)] {
+		if r == '\t' {
+			log.Column += 4
+		} else {
+			log.Column++
+		}
+	}
+	log.Length = utf8.RuneCountInString(strings.TrimRight(code, " \t"))
+	return log
+}
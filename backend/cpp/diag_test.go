@@ -0,0 +1,163 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+func TestDemangle(t *testing.T) {
+	cases := []struct {
+		s    string
+		want string
+	}{
+		{"JNID(count)", "count"},
+		{"'fa1b2c3d4_point' has no member named '_z'", "'point' has no member named 'z'"},
+		{"cannot convert 'i32_jnt' to 'str_jnt'", "cannot convert 'i32' to 'str'"},
+		{"'_jnc_initializer' was not declared", "'_jnc_initializer' was not declared"},
+		{"'_Src' in operator", "'_Src' in operator"},
+		{"no match for 'operator+'", "no match for 'operator+'"},
+	}
+	for _, c := range cases {
+		if got := Demangle(c.s); got != c.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", c.s, got, c.want)
+		}
+	}
+}
+
+// writeSource writes src to main.jn of temporary directory
+// and returns path of it.
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.jn")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiagnosticsSourceMap(t *testing.T) {
+	path := writeSource(t, "main() {\n\tx: = 1\n    y: = x  \n}\n")
+	sm := &SourceMap{
+		Version: 1,
+		File:    "out.cpp",
+		Sources: []string{path},
+		Lines: []Line{
+			{Line: 10, Source: 0, Row: 2},
+			{Line: 11, Source: 0, Row: 3},
+		},
+	}
+	output := "/tmp/build/out.cpp: In function 'void fa1b2c3d4_main()':\n" +
+		"/tmp/build/out.cpp:10:5: error: 'JNID(x)' was not declared in this scope\n" +
+		"/tmp/build/out.cpp:11:9: warning: unused variable '_y' [-Wunused-variable]\n" +
+		"/tmp/build/out.cpp:11:9: note: declared here\n"
+	errors, warnings := Diagnostics(output, "out.cpp", sm)
+	wantErrors := []jnlog.CompilerLog{{
+		Type:    jnlog.Error,
+		Row:     2,
+		Column:  5,
+		Length:  6,
+		Path:    path,
+		Line:    "\tx: = 1",
+		Key:     "cxx_error",
+		Message: "C++: 'x' was not declared in this scope",
+	}}
+	wantWarnings := []jnlog.CompilerLog{{
+		Type:    jnlog.Warning,
+		Row:     3,
+		Column:  5,
+		Length:  6,
+		Path:    path,
+		Line:    "    y: = x  ",
+		Key:     "cxx_warning",
+		Message: "C++: unused variable 'y' [-Wunused-variable]",
+		Notes:   []string{"declared here"},
+	}}
+	if !reflect.DeepEqual(errors, wantErrors) {
+		t.Errorf("errors:\n%+v\nwant:\n%+v", errors, wantErrors)
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("warnings:\n%+v\nwant:\n%+v", warnings, wantWarnings)
+	}
+}
+
+func TestDiagnosticsLineDirectives(t *testing.T) {
+	path := writeSource(t, "main() {\n\tx: = 1\n}\n")
+	output := path + ":2:12: error: expected ';' before '}' token\n"
+	errors, _ := Diagnostics(output, "out.cpp", nil)
+	if len(errors) != 1 {
+		t.Fatalf("errors are %+v", errors)
+	}
+	e := errors[0]
+	if e.Type != jnlog.Error || e.Path != path || e.Row != 2 || e.Column != 5 {
+		t.Errorf("error is %s:%d:%d (type %d), want %s:2:5", e.Path, e.Row, e.Column, e.Type, path)
+	}
+}
+
+func TestDiagnosticsUnmapped(t *testing.T) {
+	sm := &SourceMap{Version: 1, File: "out.cpp", Sources: []string{}, Lines: []Line{}}
+	output := "/tmp/build/out.cpp:40:3: warning: comparison of integer expressions\n" +
+		"/tmp/build/out.cpp:40:3: note: ignored because warning is not mapped\n" +
+		"/usr/include/api/str.hpp:12:7: error: no match for 'operator+'\n" +
+		"compilation terminated.\n"
+	errors, warnings := Diagnostics(output, "out.cpp", sm)
+	if len(warnings) != 0 {
+		t.Errorf("unmapped warnings are reported: %+v", warnings)
+	}
+	want := []jnlog.CompilerLog{{
+		Type:    jnlog.FlatError,
+		Key:     "cxx_error",
+		Message: "C++: str.hpp:12: no match for 'operator+'",
+	}}
+	if !reflect.DeepEqual(errors, want) {
+		t.Errorf("errors:\n%+v\nwant:\n%+v", errors, want)
+	}
+}
+
+func TestDiagnosticsContext(t *testing.T) {
+	path := writeSource(t, "main() {\n\tx: = sum[str](\"a\")\n}\n")
+	sm := &SourceMap{
+		Version: 1,
+		File:    "out.cpp",
+		Sources: []string{path},
+		Lines:   []Line{{Line: 20, Source: 0, Row: 2}},
+	}
+	output := "/usr/include/api/jn.hpp: In instantiation of 'T sum(T)':\n" +
+		"/tmp/build/out.cpp:20:18:   required from here\n" +
+		"/usr/include/api/jn.hpp:8:14: error: invalid operands of types\n" +
+		"/usr/include/api/jn.hpp:9:1: error: second error without context\n"
+	errors, _ := Diagnostics(output, "/tmp/build/out.cpp", sm)
+	if len(errors) != 2 {
+		t.Fatalf("errors are %+v", errors)
+	}
+	if e := errors[0]; e.Type != jnlog.Error || e.Path != path || e.Row != 2 {
+		t.Errorf("error in instantiation is %s:%d (type %d), want %s:2", e.Path, e.Row, e.Type, path)
+	}
+	// Context is consumed by first diagnostic.
+	if e := errors[1]; e.Type != jnlog.FlatError || e.Message != "C++: jn.hpp:9: second error without context" {
+		t.Errorf("second error is %+v", e)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+func (g *generator) expr(e ir.Expr) string {
+	switch t := e.(type) {
+	case nil:
+		return ""
+	case *ir.Const:
+		return constExpr(t)
+	case *ir.VarRef:
+		return t.Var.OutId()
+	case *ir.SelfRef:
+		return jnapi.CppSelf
+	case *ir.FuncRef:
+		if t.Entry {
+			return jnapi.OutId(t.Func.Id, nil)
+		}
+		return t.Func.OutId()
+	case *ir.LinkRef:
+		return t.Func.Id
+	case *ir.EnumRef:
+		if t.Enum.Tok.Id == tokens.NA {
+			return jnapi.OutId(t.Enum.Id, nil)
+		}
+		return jnapi.OutId(t.Enum.Id, t.Enum.Tok.File)
+	case *ir.StructRef:
+		return g.structType(t.Type)
+	case *ir.EnumItemRef:
+		return g.expr(t.Enum) + tokens.DOUBLE_COLON + jnapi.OutId(t.Item.Id, t.Item.Tok.File)
+	case *ir.Binary:
+		return "(" + g.expr(t.X) + t.Op + g.expr(t.Y) + ")"
+	case *ir.Unary:
+		if t.Op == tokens.CARET {
+			return "~" + g.expr(t.X)
+		}
+		return t.Op + g.expr(t.X)
+	case *ir.AddrOf:
+		if t.Heap {
+			return "__jnc_ptr_of(&" + g.expr(t.X) + ")"
+		}
+		return "__jnc_not_heap_ptr_of(&" + g.expr(t.X) + ")"
+	case *ir.Paren:
+		return "(" + g.expr(t.X) + ")"
+	case *ir.Cast:
+		return "(" + g.typ(t.Type) + ")(" + g.expr(t.X) + ")"
+	case *ir.Conv:
+		return "tostr(" + g.expr(t.X) + ")"
+	case *ir.Call:
+		return g.call(t)
+	case *ir.Field:
+		var cpp strings.Builder
+		if t.X != nil {
+			cpp.WriteString(g.expr(t.X))
+			cpp.WriteString(accessor(t.X.DataType()))
+		}
+		if t.Var.Tag != nil {
+			cpp.WriteString(t.Var.Tag.(string))
+		} else {
+			cpp.WriteString(t.Var.OutId())
+		}
+		return cpp.String()
+	case *ir.Method:
+		return g.expr(t.X) + accessor(t.X.DataType()) + t.Func.Id
+	case *ir.TraitData:
+		return g.expr(t.X) + ".get()"
+	case *ir.Index:
+		return g.expr(t.X) + "[" + g.expr(t.Index) + "]"
+	case *ir.Slice:
+		return g.slice(t)
+	case *ir.Composite:
+		return g.typ(t.Type) + g.exprs("({", t.Elems, "})")
+	case *ir.MapLit:
+		return g.mapLit(t)
+	case *ir.StructLit:
+		return g.typ(t.Type) + g.exprs("(", t.Args, ")")
+	case *ir.Tuple:
+		return g.exprs("std::make_tuple(", t.Values, ")")
+	case *ir.Closure:
+		return g.closure(t)
+	case *ir.Default:
+		if t.Type.Kind == "" {
+			return jnapi.DefaultExpr
+		}
+		return g.typ(t.Type) + jnapi.DefaultExpr
+	case *ir.MustHeap:
+		return "__jnc_must_heap(" + g.expr(t.X) + ")"
+	case *ir.Offsetof:
+		return "offsetof(" + g.typ(t.Struct) + ", " + t.Field.OutId() + ")"
+	case *ir.StaticAssert:
+		return "static_assert(" + g.expr(t.Cond) + ", " + jnapi.ToCppStr(t.Msg) + ")"
+	}
+	return ""
+}
+
+// exprs returns comma separated expressions between open and close.
+func (g *generator) exprs(open string, exprs []ir.Expr, close string) string {
+	var cpp strings.Builder
+	cpp.WriteString(open)
+	for i, e := range exprs {
+		if i > 0 {
+			cpp.WriteByte(',')
+		}
+		cpp.WriteString(g.expr(e))
+	}
+	cpp.WriteString(close)
+	return cpp.String()
+}
+
+func accessor(t ir.Type) string {
+	if t.Kind != "" && t.Kind[0] == '*' {
+		return "->"
+	}
+	return tokens.DOT
+}
+
+func constExpr(c *ir.Const) string {
+	if c.Char {
+		return "0x" + strconv.FormatInt(c.Value.(int64), 16)
+	}
+	switch t := c.Value.(type) {
+	case string:
+		return jnapi.ToStr([]byte(t))
+	case bool:
+		if t {
+			return tokens.TRUE
+		}
+		return tokens.FALSE
+	case uint64:
+		return jntype.CppId(c.Type.Id) + "{" + strconv.FormatUint(t, 10) + "}"
+	case int64:
+		return jntype.CppId(c.Type.Id) + "{" + strconv.FormatInt(t, 10) + "}"
+	case float64:
+		return jntype.CppId(c.Type.Id) + "{" + fmt.Sprint(t) + "}"
+	}
+	return tokens.NIL
+}
+
+func (g *generator) genericArgs(generics []ir.Type) string {
+	if len(generics) == 0 {
+		return ""
+	}
+	var cpp strings.Builder
+	cpp.WriteByte('<')
+	for i, t := range generics {
+		if i > 0 {
+			cpp.WriteByte(',')
+		}
+		cpp.WriteString(g.typ(t))
+	}
+	cpp.WriteByte('>')
+	return cpp.String()
+}
+
+func (g *generator) call(c *ir.Call) string {
+	if c.TupleArgs {
+		args := append([]ir.Expr{c.Fn}, c.Args...)
+		return "tuple_as_args" + g.genericArgs(c.Generics) + g.exprs("(", args, ")")
+	}
+	return g.expr(c.Fn) + g.genericArgs(c.Generics) + g.exprs("(", c.Args, ")")
+}
+
+func (g *generator) slice(s *ir.Slice) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.expr(s.X))
+	cpp.WriteString(".___slice(")
+	if s.Low != nil {
+		cpp.WriteString(g.expr(s.Low))
+	} else {
+		cpp.WriteByte('0')
+	}
+	if s.High != nil {
+		cpp.WriteByte(',')
+		cpp.WriteString(g.expr(s.High))
+	}
+	cpp.WriteByte(')')
+	return cpp.String()
+}
+
+func (g *generator) mapLit(m *ir.MapLit) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.typ(m.Type))
+	cpp.WriteByte('{')
+	for i, k := range m.Keys {
+		cpp.WriteByte('{')
+		cpp.WriteString(g.expr(k))
+		cpp.WriteByte(',')
+		cpp.WriteString(g.expr(m.Values[i]))
+		cpp.WriteString("},")
+	}
+	cpp.WriteByte('}')
+	return cpp.String()
+}
+
+func (g *generator) closure(c *ir.Closure) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.funcType(c.Func, false))
+	cpp.WriteString("([")
+	for i, v := range c.Captures {
+		if i > 0 {
+			cpp.WriteByte(',')
+		}
+		id := v.OutId()
+		cpp.WriteString(id)
+		if accessor(v.Type) == "->" {
+			cpp.WriteByte('=')
+			cpp.WriteString(id)
+			cpp.WriteString(".__must_heap()")
+		}
+	}
+	cpp.WriteByte(']')
+	cpp.WriteString(g.params(c.Func.Params))
+	cpp.WriteString(" mutable -> ")
+	cpp.WriteString(g.typ(c.Func.RetType.Type))
+	cpp.WriteByte(' ')
+	cpp.WriteString(g.block(c.Body))
+	cpp.WriteByte(')')
+	return cpp.String()
+}
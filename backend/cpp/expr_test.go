@@ -0,0 +1,49 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"testing"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+func TestStaticAssertMessage(t *testing.T) {
+	cond := &ir.Const{Type: ir.Type{Id: jntype.Bool}, Value: true}
+	cases := []struct {
+		msg  string
+		want string
+	}{
+		{`size mismatch`, `static_assert(true, "size mismatch")`},
+		{`size of \"point\" is not 8`, `static_assert(true, "size of \042point\042 is not 8")`},
+		{`path C:\\jane`, `static_assert(true, "path C:\134jane")`},
+		{`first\nsecond`, `static_assert(true, "first\012second")`},
+		{`\u00e7`, `static_assert(true, "\303\247")`},
+	}
+	g := new(generator)
+	for _, c := range cases {
+		got := g.expr(&ir.StaticAssert{Type: cond.Type, Cond: cond, Msg: c.msg})
+		if got != c.want {
+			t.Errorf("message %s:\ngot  %s\nwant %s", c.msg, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"strconv"
+	"strings"
+)
+
+const linePrefix = "#line "
+
+// generatedPath is path of #line directives that resets mapping to generated
+// code itself. MapLines replaces them with directives of generated file, so
+// code that synthesized by backend is not mapped to rows of Jane sources.
+const generatedPath = "<generated>"
+
+// SourceMap maps lines of generated C++ code to positions of Jane sources.
+type SourceMap struct {
+	Version int `json:"version"`
+	// File is name of generated file.
+	File string `json:"file"`
+	// Sources are paths of Jane sources.
+	Sources []string `json:"sources"`
+	Lines   []Line   `json:"lines"`
+}
+
+// Line is mapping of generated line.
+// Lines are starts at 1, Source is index of source path.
+type Line struct {
+	Line   int `json:"line"`
+	Source int `json:"source"`
+	Row    int `json:"row"`
+}
+
+func lineDirective(row int, path string) string {
+	path = strings.ReplaceAll(path, `\`, `\\`)
+	path = strings.ReplaceAll(path, `"`, `\"`)
+	return linePrefix + strconv.Itoa(row) + ` "` + path + `"`
+}
+
+// parseLineDirective returns row and path of #line directive.
+// Reports false if line is not directive.
+func parseLineDirective(line string) (row int, path string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, linePrefix) {
+		return 0, "", false
+	}
+	line = line[len(linePrefix):]
+	i := strings.IndexByte(line, ' ')
+	if i == -1 {
+		return 0, "", false
+	}
+	row, err := strconv.Atoi(line[:i])
+	if err != nil {
+		return 0, "", false
+	}
+	path, err = strconv.Unquote(line[i+1:])
+	if err != nil {
+		return 0, "", false
+	}
+	return row, path, true
+}
+
+// MapLines returns source map of code that generated with #line directives.
+// Lines are mapped like compilers do for directives, so each line after
+// directive is maps to next row. Directives are removed from returned code
+// and source map is describes code without them if strip is true.
+// Lines after resets of generated code are not mapped, resets are
+// replaced with directives of file.
+func MapLines(code, file string, strip bool) (string, *SourceMap) {
+	sm := &SourceMap{
+		Version: 1,
+		File:    file,
+		Sources: []string{},
+		Lines:   []Line{},
+	}
+	sources := map[string]int{}
+	lines := strings.SplitAfter(code, "\n")
+	var out strings.Builder
+	n := 0
+	source, row := -1, 0
+	for _, line := range lines {
+		if r, path, ok := parseLineDirective(line); ok && path == generatedPath {
+			source = -1
+			if strip {
+				continue
+			}
+			n++
+			out.WriteString(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+			out.WriteString(lineDirective(n+1, file))
+			out.WriteByte('\n')
+			continue
+		} else if ok {
+			i, ok := sources[path]
+			if !ok {
+				i = len(sm.Sources)
+				sources[path] = i
+				sm.Sources = append(sm.Sources, path)
+			}
+			source, row = i, r
+			if strip {
+				continue
+			}
+			n++
+			out.WriteString(line)
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		n++
+		out.WriteString(line)
+		if source != -1 {
+			sm.Lines = append(sm.Lines, Line{Line: n, Source: source, Row: row})
+			row++
+		}
+	}
+	return out.String(), sm
+}
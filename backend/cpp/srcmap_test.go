@@ -0,0 +1,198 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+func TestLineDirective(t *testing.T) {
+	paths := []string{
+		"main.jn",
+		`C:\jane\main.jn`,
+		`dir/"quoted".jn`,
+		generatedPath,
+	}
+	for _, path := range paths {
+		line := lineDirective(12, path)
+		row, got, ok := parseLineDirective("  " + line + "\n")
+		if !ok || row != 12 || got != path {
+			t.Errorf("%s: parsed as %d %q %v", line, row, got, ok)
+		}
+	}
+	for _, line := range []string{
+		"int x;",
+		"#line",
+		"#line x \"main.jn\"",
+		"#line 1 main.jn",
+		"#include \"main.jn\"",
+	} {
+		if _, _, ok := parseLineDirective(line); ok {
+			t.Errorf("%s: parsed as directive", line)
+		}
+	}
+}
+
+// mapLinesCode is generated code with directives of two sources
+// and a reset after them.
+var mapLinesCode = strings.Join([]string{
+	`#include "api.hpp"`,
+	`#line 3 "main.jn"`,
+	`int a;`,
+	`int b;`,
+	`#line 7 "lib.jn"`,
+	`int c;`,
+	`    #line 1 "<generated>"`,
+	`int d;`,
+	`#line 9 "main.jn"`,
+	`int e;`,
+	``,
+}, "\n")
+
+func TestMapLines(t *testing.T) {
+	code, sm := MapLines(mapLinesCode, "out.cpp", false)
+	wantCode := strings.Join([]string{
+		`#include "api.hpp"`,
+		`#line 3 "main.jn"`,
+		`int a;`,
+		`int b;`,
+		`#line 7 "lib.jn"`,
+		`int c;`,
+		`    #line 8 "out.cpp"`,
+		`int d;`,
+		`#line 9 "main.jn"`,
+		`int e;`,
+		``,
+	}, "\n")
+	if code != wantCode {
+		t.Errorf("code:\n%s\nwant:\n%s", code, wantCode)
+	}
+	want := &SourceMap{
+		Version: 1,
+		File:    "out.cpp",
+		Sources: []string{"main.jn", "lib.jn"},
+		Lines: []Line{
+			{Line: 3, Source: 0, Row: 3},
+			{Line: 4, Source: 0, Row: 4},
+			{Line: 6, Source: 1, Row: 7},
+			{Line: 10, Source: 0, Row: 9},
+		},
+	}
+	if !reflect.DeepEqual(sm, want) {
+		t.Errorf("source map:\n%+v\nwant:\n%+v", sm, want)
+	}
+}
+
+func TestMapLinesStrip(t *testing.T) {
+	code, sm := MapLines(mapLinesCode, "out.cpp", true)
+	wantCode := "#include \"api.hpp\"\nint a;\nint b;\nint c;\nint d;\nint e;\n"
+	if code != wantCode {
+		t.Errorf("code:\n%s\nwant:\n%s", code, wantCode)
+	}
+	want := []Line{
+		{Line: 2, Source: 0, Row: 3},
+		{Line: 3, Source: 0, Row: 4},
+		{Line: 4, Source: 1, Row: 7},
+		{Line: 6, Source: 0, Row: 9},
+	}
+	if !reflect.DeepEqual(sm.Lines, want) {
+		t.Errorf("lines:\n%+v\nwant:\n%+v", sm.Lines, want)
+	}
+}
+
+const generateSource = `struct point {
+	x: int
+	y: int
+}
+
+double(x int) int {
+	ret x * 2
+}
+
+main() {
+	p: = point{1, 2}
+	p.y = double(p.x)
+}
+`
+
+// generate returns C++ code of src, with #line directives if lines is true.
+func generate(t *testing.T, src string, lines bool) string {
+	t.Helper()
+	fsys := fstest.MapFS{"main.jn": {Data: []byte(src)}}
+	f, err := jnio.ReadJn(fsys, "main.jn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(f)
+	p.Env = parser.NewEnv(fsys, "std", nil)
+	p.Parsef(true, false)
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	return Generate(p.IR(), "\t", 1, lines)
+}
+
+func TestGenerateLines(t *testing.T) {
+	code, sm := MapLines(generate(t, generateSource, true), "out.cpp", true)
+	if strings.Contains(code, linePrefix) {
+		t.Fatalf("stripped code has directives:\n%s", code)
+	}
+	if len(sm.Sources) != 1 || sm.Sources[0] != "main.jn" {
+		t.Fatalf("sources are %v", sm.Sources)
+	}
+	lines := strings.Split(code, "\n")
+	rows := map[int]string{}
+	for _, l := range sm.Lines {
+		rows[l.Row] += strings.TrimSpace(lines[l.Line-1]) + "\n"
+	}
+	for row, want := range map[int]string{
+		2:  "x",
+		3:  "y",
+		7:  "ret",
+		11: "point",
+		12: "double",
+	} {
+		if !strings.Contains(rows[row], want) {
+			t.Errorf("row %d is mapped to:\n%s\nwant line with %q", row, rows[row], want)
+		}
+	}
+	// Synthesized code such as stream operators of structs and
+	// initializer caller must not be mapped to rows of source.
+	for _, l := range sm.Lines {
+		line := lines[l.Line-1]
+		if strings.Contains(line, "operator") || strings.Contains(line, "jnc_initializer") {
+			t.Errorf("synthesized line %d is mapped to row %d: %s", l.Line, l.Row, line)
+		}
+	}
+}
+
+func TestGenerateWithoutLines(t *testing.T) {
+	code := generate(t, generateSource, false)
+	if strings.Contains(code, linePrefix) {
+		t.Errorf("code has directives:\n%s", code)
+	}
+}
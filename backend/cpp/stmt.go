@@ -0,0 +1,405 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+func (g *generator) block(b *ir.Block) string {
+	g.addIndent()
+	defer g.doneIndent()
+	var cpp strings.Builder
+	cpp.WriteByte('{')
+	if b != nil {
+		for _, s := range b.Stmts {
+			cpp.WriteByte('\n')
+			cpp.WriteString(g.indentString())
+			cpp.WriteString(g.line(s.Position().Tok))
+			cpp.WriteString(g.stmt(s))
+		}
+	}
+	cpp.WriteByte('\n')
+	cpp.WriteString(strings.Repeat(g.indentKind, (g.indent-1)*g.indentCount))
+	cpp.WriteByte('}')
+	return cpp.String()
+}
+
+func (g *generator) stmt(s ir.Stmt) string {
+	switch t := s.(type) {
+	case *ir.Block:
+		return g.block(t)
+	case *ir.ExprStmt:
+		return g.expr(t.X) + ";"
+	case *ir.VarDecl:
+		return g.varDecl(t)
+	case *ir.Assign:
+		return g.assign(t)
+	case *ir.IncDec:
+		return g.expr(t.X) + t.Op + ";"
+	case *ir.TupleAssign:
+		return g.tupleAssign(t)
+	case *ir.If:
+		return g.ifStmt(t)
+	case *ir.Loop:
+		return "while (true) " + g.block(t.Body)
+	case *ir.While:
+		return "while (" + g.expr(t.Cond) + ") " + g.block(t.Body)
+	case *ir.For:
+		return g.forStmt(t)
+	case *ir.Foreach:
+		return g.foreach(t)
+	case *ir.Match:
+		return g.match(t)
+	case *ir.Fallthrough:
+		return "goto " + caseBeginLabel(t.Case.Next) + ";"
+	case *ir.Break:
+		if t.Match != nil {
+			return "goto " + matchEndLabel(t.Match) + ";"
+		}
+		return "break;"
+	case *ir.Continue:
+		return "continue;"
+	case *ir.Goto:
+		return "goto " + t.Label + ";"
+	case *ir.Label:
+		return t.Label + ":;"
+	case *ir.Ret:
+		if t.X == nil {
+			return "return;"
+		}
+		return "return " + g.expr(t.X) + ";"
+	case *ir.Defer:
+		return jnapi.ToDeferredCall(g.expr(t.Call))
+	case *ir.Co:
+		return jnapi.ToConcurrentCall(g.expr(t.Call))
+	case *ir.Try:
+		return g.try(t)
+	case *ir.Comment:
+		return "// " + t.Text
+	case *ir.TypeAlias:
+		return g.typeAlias(t)
+	}
+	return ""
+}
+
+func (g *generator) assign(a *ir.Assign) string {
+	var cpp strings.Builder
+	if a.Left != nil {
+		cpp.WriteString(g.expr(a.Left))
+		cpp.WriteString(a.Op)
+	}
+	cpp.WriteString(g.expr(a.Right))
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func (g *generator) tupleLefts(a *ir.TupleAssign) string {
+	var cpp strings.Builder
+	for _, d := range a.Decls {
+		cpp.WriteString(g.varDecl(d))
+		cpp.WriteByte(' ')
+	}
+	cpp.WriteString("std::tie(")
+	for i, left := range a.Left {
+		if i > 0 {
+			cpp.WriteByte(',')
+		}
+		if left == nil {
+			cpp.WriteString(jnapi.CppIgnore)
+		} else {
+			cpp.WriteString(g.expr(left))
+		}
+	}
+	cpp.WriteByte(')')
+	cpp.WriteString(a.Op)
+	return cpp.String()
+}
+
+func (g *generator) tupleAssign(a *ir.TupleAssign) string {
+	var cpp strings.Builder
+	switch {
+	case len(a.Right) == 1:
+		// Multiple returns of function call.
+		cpp.WriteString(g.tupleLefts(a))
+		cpp.WriteString(g.expr(a.Right[0]))
+	case !hasLeft(a):
+		for i, right := range a.Right {
+			if i > 0 {
+				cpp.WriteByte(';')
+			}
+			cpp.WriteString(g.expr(right))
+		}
+	default:
+		cpp.WriteString(g.tupleLefts(a))
+		cpp.WriteString(g.exprs("std::make_tuple(", a.Right, ")"))
+	}
+	cpp.WriteByte(';')
+	return cpp.String()
+}
+
+func hasLeft(a *ir.TupleAssign) bool {
+	for _, left := range a.Left {
+		if left != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) ifStmt(i *ir.If) string {
+	var cpp strings.Builder
+	cpp.WriteString("if (")
+	cpp.WriteString(g.expr(i.Cond))
+	cpp.WriteString(") ")
+	cpp.WriteString(g.block(i.Then))
+	switch t := i.Else.(type) {
+	case *ir.If:
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		cpp.WriteString("else ")
+		cpp.WriteString(g.ifStmt(t))
+	case *ir.Block:
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		cpp.WriteString("else ")
+		cpp.WriteString(g.block(t))
+	}
+	return cpp.String()
+}
+
+func (g *generator) forStmt(f *ir.For) string {
+	var cpp strings.Builder
+	cpp.WriteString("for (")
+	if f.Init != nil {
+		cpp.WriteString(g.stmt(f.Init))
+	} else {
+		cpp.WriteString("; ")
+	}
+	cpp.WriteString(g.expr(f.Cond))
+	cpp.WriteString("; ")
+	if f.Post != nil {
+		s := g.stmt(f.Post)
+		cpp.WriteString(s[:len(s)-1])
+	}
+	cpp.WriteString(") ")
+	cpp.WriteString(g.block(f.Body))
+	return cpp.String()
+}
+
+func (g *generator) foreachFunc(f *ir.Foreach) string {
+	var cpp strings.Builder
+	cpp.WriteString(">(")
+	cpp.WriteString(g.expr(f.X))
+	cpp.WriteString(", [&](")
+	cpp.WriteString(g.typ(f.Key.Type))
+	cpp.WriteByte(' ')
+	cpp.WriteString(f.Key.OutId())
+	if !jnapi.IsIgnoreId(f.Value.Id) {
+		cpp.WriteByte(',')
+		cpp.WriteString(g.typ(f.Value.Type))
+		cpp.WriteByte(' ')
+		cpp.WriteString(f.Value.OutId())
+	}
+	cpp.WriteString(") -> void ")
+	cpp.WriteString(g.block(f.Body))
+	cpp.WriteString(");")
+	return cpp.String()
+}
+
+func (g *generator) foreach(f *ir.Foreach) string {
+	var cpp strings.Builder
+	if jnapi.IsIgnoreId(f.Key.Id) {
+		cpp.WriteString("for (auto ")
+		cpp.WriteString(f.Value.OutId())
+		cpp.WriteString(" : ")
+		cpp.WriteString(g.expr(f.X))
+		cpp.WriteString(") ")
+		cpp.WriteString(g.block(f.Body))
+		return cpp.String()
+	}
+	switch f.Type.Id {
+	case jntype.Str, jntype.Slice, jntype.Array:
+		cpp.WriteString("foreach<")
+		cpp.WriteString(g.typ(f.Type))
+		cpp.WriteByte(',')
+		cpp.WriteString(g.typ(f.Key.Type))
+		if !jnapi.IsIgnoreId(f.Value.Id) {
+			cpp.WriteByte(',')
+			cpp.WriteString(g.typ(f.Value.Type))
+		}
+	case jntype.Map:
+		types := f.Type.Tag.([]ir.Type)
+		cpp.WriteString("foreach<")
+		cpp.WriteString(g.typ(types[0]))
+		cpp.WriteByte(',')
+		cpp.WriteString(g.typ(types[1]))
+	default:
+		return ""
+	}
+	cpp.WriteString(g.foreachFunc(f))
+	return cpp.String()
+}
+
+func tokLabel(prefix string, row, column int) string {
+	var cpp strings.Builder
+	cpp.WriteString(prefix)
+	cpp.WriteString(strconv.FormatInt(int64(row), 10))
+	cpp.WriteString(strconv.FormatInt(int64(column), 10))
+	return cpp.String()
+}
+
+func caseBeginLabel(c *ir.Case) string {
+	return tokLabel("case_begin_", c.Tok.Row, c.Tok.Column)
+}
+
+func caseEndLabel(c *ir.Case) string {
+	return tokLabel("case_end_", c.Tok.Row, c.Tok.Column)
+}
+
+func matchEndLabel(m *ir.Match) string {
+	return tokLabel("match_end_", m.Tok.Row, m.Tok.Column)
+}
+
+func (g *generator) matchCase(c *ir.Case, matchExpr string) string {
+	endlabel := caseEndLabel(c)
+	var cpp strings.Builder
+	if len(c.Exprs) > 0 {
+		cpp.WriteString("if (!(")
+		for i, expr := range c.Exprs {
+			cpp.WriteString(g.expr(expr))
+			if matchExpr != "" {
+				cpp.WriteString(" == ")
+				cpp.WriteString(matchExpr)
+			}
+			if i+1 < len(c.Exprs) {
+				cpp.WriteString(" || ")
+			}
+		}
+		cpp.WriteString(")) { goto ")
+		cpp.WriteString(endlabel)
+		cpp.WriteString("; }\n")
+	}
+	if c.Body != nil && len(c.Body.Stmts) > 0 {
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(caseBeginLabel(c))
+		cpp.WriteString(":;\n")
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.block(c.Body))
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		cpp.WriteString("goto ")
+		cpp.WriteString(matchEndLabel(c.Match))
+		cpp.WriteString(";\n")
+	}
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(endlabel)
+	cpp.WriteString(":;")
+	return cpp.String()
+}
+
+func (g *generator) matchExpr(m *ir.Match) string {
+	if len(m.Cases) == 0 {
+		if m.Default != nil {
+			return g.matchCase(m.Default, "")
+		}
+		return ""
+	}
+	var cpp strings.Builder
+	cpp.WriteString("{\n")
+	g.addIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(g.typ(m.Type))
+	cpp.WriteString(" expr{")
+	cpp.WriteString(g.expr(m.X))
+	cpp.WriteString("};\n")
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(g.matchCase(m.Cases[0], "expr"))
+	for _, c := range m.Cases[1:] {
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.indentString())
+		cpp.WriteString(g.matchCase(c, "expr"))
+	}
+	if m.Default != nil {
+		cpp.WriteString(g.matchCase(m.Default, ""))
+	}
+	cpp.WriteByte('\n')
+	g.doneIndent()
+	cpp.WriteString(g.indentString())
+	cpp.WriteByte('}')
+	return cpp.String()
+}
+
+func (g *generator) matchBool(m *ir.Match) string {
+	var cpp strings.Builder
+	if len(m.Cases) > 0 {
+		cpp.WriteString(g.matchCase(m.Cases[0], ""))
+		for _, c := range m.Cases[1:] {
+			cpp.WriteByte('\n')
+			cpp.WriteString(g.indentString())
+			cpp.WriteString(g.matchCase(c, ""))
+		}
+	}
+	if m.Default != nil {
+		cpp.WriteByte('\n')
+		cpp.WriteString(g.matchCase(m.Default, ""))
+		cpp.WriteByte('\n')
+	}
+	return cpp.String()
+}
+
+func (g *generator) match(m *ir.Match) string {
+	var cpp strings.Builder
+	if m.X != nil {
+		cpp.WriteString(g.matchExpr(m))
+	} else {
+		cpp.WriteString(g.matchBool(m))
+	}
+	cpp.WriteByte('\n')
+	cpp.WriteString(g.indentString())
+	cpp.WriteString(matchEndLabel(m))
+	cpp.WriteString(":;")
+	return cpp.String()
+}
+
+func (g *generator) try(t *ir.Try) string {
+	var cpp strings.Builder
+	cpp.WriteString("try ")
+	cpp.WriteString(g.block(t.Body))
+	cpp.WriteString(" catch(trait<JNID(Error)> ")
+	cpp.WriteString(t.Param.OutId())
+	cpp.WriteString(") ")
+	if c, ok := t.Handler.(*ir.Closure); ok {
+		cpp.WriteString(g.block(c.Body))
+	} else {
+		cpp.WriteString("{ ")
+		cpp.WriteString(g.expr(t.Handler))
+		cpp.WriteByte('(')
+		cpp.WriteString(t.Param.OutId())
+		cpp.WriteString("); }")
+	}
+	return cpp.String()
+}
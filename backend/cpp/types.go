@@ -0,0 +1,248 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cpp
+
+import (
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+func (g *generator) typ(t ir.Type) (s string) {
+	t.SetToOriginal()
+	if t.MultiTyped {
+		return g.multiType(t)
+	}
+	i := strings.LastIndex(t.Kind, tokens.DOUBLE_COLON)
+	if i != -1 {
+		t.Kind = t.Kind[i+len(tokens.DOUBLE_COLON):]
+	}
+	pointers := t.Pointers()
+	defer func() {
+		var cpp strings.Builder
+		for range pointers {
+			cpp.WriteString("ptr<")
+		}
+		cpp.WriteString(s)
+		for range pointers {
+			cpp.WriteString(">")
+		}
+		s = cpp.String()
+	}()
+	t.Kind = t.Kind[len(pointers):]
+	switch t.Id {
+	case jntype.Slice:
+		return g.sliceType(t)
+	case jntype.Array:
+		return g.arrayType(t)
+	case jntype.Map:
+		return g.mapType(t)
+	}
+	switch t.Tag.(type) {
+	case models.CompiledStruct:
+		return g.structType(t)
+	}
+	switch t.Id {
+	case jntype.Id:
+		if t.Generic {
+			return jnapi.AsId(t.Kind)
+		}
+		return jnapi.OutId(t.Kind, t.Tok.File)
+	case jntype.Enum:
+		return
+	case jntype.Trait:
+		return traitType(t)
+	case jntype.Struct:
+		return g.structType(t)
+	case jntype.Func:
+		return g.funcType(t.Tag.(*models.Func), t.Pure)
+	default:
+		return jntype.CppId(t.Id)
+	}
+}
+
+func (g *generator) sliceType(t ir.Type) string {
+	var cpp strings.Builder
+	cpp.WriteString("slice<")
+	comp := *t.ComponentType
+	comp.Pure = t.Pure
+	cpp.WriteString(g.typ(comp))
+	cpp.WriteByte('>')
+	return cpp.String()
+}
+
+func (g *generator) arrayType(t ir.Type) string {
+	var cpp strings.Builder
+	cpp.WriteString("array<")
+	comp := *t.ComponentType
+	comp.Pure = t.Pure
+	cpp.WriteString(g.typ(comp))
+	cpp.WriteByte(',')
+	cpp.WriteString(g.expr(t.Size.Expr.Model))
+	cpp.WriteByte('>')
+	return cpp.String()
+}
+
+func (g *generator) mapType(t ir.Type) string {
+	var cpp strings.Builder
+	types := t.Tag.([]ir.Type)
+	cpp.WriteString("map<")
+	key := types[0]
+	key.Pure = t.Pure
+	cpp.WriteString(g.typ(key))
+	cpp.WriteByte(',')
+	value := types[1]
+	value.Pure = t.Pure
+	cpp.WriteString(g.typ(value))
+	cpp.WriteByte('>')
+	return cpp.String()
+}
+
+func traitType(t ir.Type) string {
+	var cpp strings.Builder
+	id, _ := t.KindId()
+	cpp.WriteString("trait<")
+	cpp.WriteString(jnapi.OutId(id, t.Tok.File))
+	cpp.WriteByte('>')
+	return cpp.String()
+}
+
+func (g *generator) structType(t ir.Type) string {
+	var cpp strings.Builder
+	s := t.Tag.(models.CompiledStruct)
+	cpp.WriteString(s.OutId())
+	types := s.Generics()
+	if len(types) == 0 {
+		return cpp.String()
+	}
+	cpp.WriteByte('<')
+	for _, gt := range types {
+		gt.Pure = t.Pure
+		cpp.WriteString(g.typ(gt))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1] + ">"
+}
+
+func (g *generator) funcType(f *models.Func, pure bool) string {
+	var cpp strings.Builder
+	cpp.WriteString("func<std::function<")
+	ret := f.RetType.Type
+	ret.Pure = pure
+	cpp.WriteString(g.typ(ret))
+	cpp.WriteByte('(')
+	if len(f.Params) > 0 {
+		for _, param := range f.Params {
+			param.Type.Pure = pure
+			cpp.WriteString(g.paramPrototype(&param))
+			cpp.WriteByte(',')
+		}
+		s := cpp.String()[:cpp.Len()-1]
+		cpp.Reset()
+		cpp.WriteString(s)
+	} else {
+		cpp.WriteString("void")
+	}
+	cpp.WriteString(")>>")
+	return cpp.String()
+}
+
+func (g *generator) multiType(t ir.Type) string {
+	types := t.Tag.([]ir.Type)
+	var cpp strings.Builder
+	cpp.WriteString("std::tuple<")
+	for _, mt := range types {
+		mt.Pure = t.Pure
+		cpp.WriteString(g.typ(mt))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1] + ">" + t.Pointers()
+}
+
+func (g *generator) paramPrototype(p *models.Param) string {
+	var cpp strings.Builder
+	if p.Variadic {
+		cpp.WriteString("slice<")
+		cpp.WriteString(g.typ(p.Type))
+		cpp.WriteByte('>')
+	} else {
+		cpp.WriteString(g.typ(p.Type))
+	}
+	if p.Reference {
+		cpp.WriteByte('&')
+	}
+	return cpp.String()
+}
+
+func (g *generator) param(p *models.Param) string {
+	var cpp strings.Builder
+	cpp.WriteString(g.paramPrototype(p))
+	if p.Id != "" && !jnapi.IsIgnoreId(p.Id) && p.Id != jn.Anonymous {
+		cpp.WriteByte(' ')
+		cpp.WriteString(p.OutId())
+	}
+	return cpp.String()
+}
+
+func (g *generator) params(params []models.Param) string {
+	if len(params) == 0 {
+		return "(void)"
+	}
+	var cpp strings.Builder
+	cpp.WriteByte('(')
+	for i := range params {
+		cpp.WriteString(g.param(&params[i]))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1] + ")"
+}
+
+func (g *generator) prototypeParams(params []models.Param) string {
+	if len(params) == 0 {
+		return "(void)"
+	}
+	var cpp strings.Builder
+	cpp.WriteByte('(')
+	for i := range params {
+		cpp.WriteString(g.paramPrototype(&params[i]))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1] + ")"
+}
+
+func genericsDecl(generics []*models.GenericType) string {
+	if len(generics) == 0 {
+		return ""
+	}
+	var cpp strings.Builder
+	cpp.WriteString("template<")
+	for _, generic := range generics {
+		cpp.WriteString("typename ")
+		cpp.WriteString(jnapi.AsId(generic.Id))
+		cpp.WriteByte(',')
+	}
+	return cpp.String()[:cpp.Len()-1] + ">"
+}
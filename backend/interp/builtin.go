@@ -0,0 +1,288 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// natives are built-in functions by identifier.
+var natives map[string]native
+
+func init() {
+	natives = map[string]native{
+		"print":   printNative,
+		"println": printlnNative,
+		"panic":   panicNative,
+		"recover": func(*frame, *ir.Call, []any) any { return nil },
+		"new":     newNative,
+		"make":    makeNative,
+		"copy":    copyNative,
+		"append":  appendNative,
+		"sizeof":  layoutNative,
+		"alignof": layoutNative,
+	}
+}
+
+// layoutNative is unfolded sizeof or alignof call.
+// Layouts of types are known by C++ compiler only.
+func layoutNative(f *frame, c *ir.Call, _ []any) any {
+	panic(&fatal{err: errors.New(jn.GetError("interp_layout", f.genericOf(c, c.Type).Kind))})
+}
+
+func printNative(f *frame, c *ir.Call, args []any) any {
+	f.in.write(f.stream(args[0], c.Args[0].DataType()))
+	return nil
+}
+
+func printlnNative(f *frame, c *ir.Call, args []any) any {
+	f.in.write(f.stream(args[0], c.Args[0].DataType()) + "\n")
+	return nil
+}
+
+func panicNative(_ *frame, _ *ir.Call, args []any) any {
+	err := args[0]
+	if s, ok := err.(*structval); ok {
+		err = trait{data: copyValue(s)}
+	}
+	panic(&jnpanic{err: err})
+}
+
+// genericOf returns type of first generic type of call.
+// Type is taken from t if call has not generics.
+func (f *frame) genericOf(c *ir.Call, t ir.Type) ir.Type {
+	if len(c.Generics) > 0 {
+		return f.resolve(c.Generics[0])
+	}
+	return f.resolve(t)
+}
+
+func newNative(f *frame, c *ir.Call, _ []any) any {
+	t := c.Type
+	if t.Kind != "" {
+		t.Kind = t.Kind[1:]
+	}
+	p := new(any)
+	*p = f.zero(f.genericOf(c, t))
+	return p
+}
+
+func makeNative(f *frame, c *ir.Call, args []any) any {
+	n := toint(args[0])
+	if n < 0 {
+		return slice{}
+	}
+	var t ir.Type
+	if c.Type.ComponentType != nil {
+		t = *c.Type.ComponentType
+	}
+	t = f.genericOf(c, t)
+	elems := make([]any, n)
+	for i := range elems {
+		elems[i] = f.zero(t)
+	}
+	return slice{elems: elems}
+}
+
+func copyNative(_ *frame, _ *ir.Call, args []any) any {
+	dest, _ := args[0].(slice)
+	src, _ := args[1].(slice)
+	n := len(dest.elems)
+	if len(src.elems) < n {
+		n = len(src.elems)
+	}
+	for i := 0; i < n; i++ {
+		dest.elems[i] = copyValue(src.elems[i])
+	}
+	return int64(n)
+}
+
+func appendNative(_ *frame, _ *ir.Call, args []any) any {
+	src, _ := args[0].(slice)
+	var components slice
+	if len(args) > 1 {
+		components, _ = args[1].(slice)
+	}
+	elems := make([]any, 0, len(src.elems)+len(components.elems))
+	elems = append(elems, copyElems(src.elems)...)
+	elems = append(elems, copyElems(components.elems)...)
+	if len(elems) == 0 {
+		return slice{}
+	}
+	return slice{elems: elems}
+}
+
+// builtinMethod returns method of built-in type by identifier.
+func builtinMethod(x any, id string) native {
+	switch v := x.(type) {
+	case string:
+		return strMethod(v, id)
+	case slice:
+		if id == "empty" {
+			return func(*frame, *ir.Call, []any) any { return len(v.elems) == 0 }
+		}
+	case *array:
+		if id == "empty" {
+			return func(*frame, *ir.Call, []any) any { return len(v.elems) == 0 }
+		}
+	case *mapval:
+		return mapMethod(v, id)
+	}
+	return nil
+}
+
+func mapMethod(m *mapval, id string) native {
+	switch id {
+	case "clear":
+		return func(*frame, *ir.Call, []any) any {
+			m.keys = nil
+			m.entries = map[any]*entry{}
+			return nil
+		}
+	case "keys", "values":
+		return func(*frame, *ir.Call, []any) any {
+			var elems []any
+			m.each(func(e *entry) {
+				if id == "keys" {
+					elems = append(elems, copyValue(e.key))
+				} else {
+					elems = append(elems, copyValue(e.value))
+				}
+			})
+			return slice{elems: elems}
+		}
+	case "empty":
+		return func(*frame, *ir.Call, []any) any { return len(m.keys) == 0 }
+	case "has":
+		return func(_ *frame, _ *ir.Call, args []any) any { return m.get(args[0]) != nil }
+	case "del":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			m.del(args[0])
+			return nil
+		}
+	}
+	return nil
+}
+
+func strMethod(s, id string) native {
+	switch id {
+	case "empty":
+		return func(*frame, *ir.Call, []any) any { return s == "" }
+	case "has_prefix":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return strings.HasPrefix(s, args[0].(string))
+		}
+	case "has_suffix":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return strings.HasSuffix(s, args[0].(string))
+		}
+	case "find":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return int64(strings.Index(s, args[0].(string)))
+		}
+	case "rfind":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return int64(strings.LastIndex(s, args[0].(string)))
+		}
+	case "trim":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			bytes := args[0].(string)
+			for i := 0; i < len(s); i++ {
+				if strings.IndexByte(bytes, s[i]) == -1 {
+					return s[i:]
+				}
+			}
+			return ""
+		}
+	case "rtrim":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			bytes := args[0].(string)
+			for i := len(s) - 1; i >= 0; i-- {
+				if strings.IndexByte(bytes, s[i]) == -1 {
+					return s[:i+1]
+				}
+			}
+			return ""
+		}
+	case "split":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return split(s, args[0].(string), toint(numconv(args[1], jntype.I64)))
+		}
+	case "replace":
+		return func(_ *frame, _ *ir.Call, args []any) any {
+			return replace(s, args[0].(string), args[1].(string), toint(args[2]))
+		}
+	}
+	return nil
+}
+
+// split is same with split method of str in C++ API.
+func split(s, sub string, n int64) slice {
+	var parts []any
+	if n == 0 || sub == "" {
+		return slice{}
+	}
+	count := int64(0)
+	for {
+		i := strings.Index(s, sub)
+		if i == -1 {
+			break
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sub):]
+		count++
+		if n > 0 && count >= n {
+			break
+		}
+	}
+	if len(parts) > 0 && (n < 0 || count < n) {
+		parts = append(parts, s)
+	}
+	return slice{elems: parts}
+}
+
+// replace is same with replace method of str in C++ API.
+func replace(s, sub, new string, n int64) string {
+	if n == 0 || sub == "" {
+		return s
+	}
+	count := int64(0)
+	start := 0
+	for {
+		i := strings.Index(s[start:], sub)
+		if i == -1 {
+			break
+		}
+		i += start
+		s = s[:i] + new + s[i+len(sub):]
+		start = i + len(new)
+		count++
+		if n > 0 && count >= n {
+			break
+		}
+	}
+	return s
+}
@@ -0,0 +1,759 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// frame is execution state of function call.
+type frame struct {
+	in    *Interp
+	fn    *models.Func
+	vars  map[varKey]*any
+	env   map[varKey]*any
+	self  *any
+	gens  map[string]ir.Type
+	ret   any
+	label string
+	fall  *ir.Case
+	match *ir.Match
+}
+
+func fatalf(format string, args ...any) {
+	panic(&fatal{err: errors.New(fmt.Sprintf(format, args...))})
+}
+
+// runtimePanic panics with error message like runtime of C++ API.
+func runtimePanic(msg string) {
+	panic(&jnpanic{err: trait{data: errorval(msg)}})
+}
+
+func nilPanic() {
+	runtimePanic("invalid memory address or nil pointer deference")
+}
+
+func indexPanic(i int64) {
+	runtimePanic("index out of range [" + strconv.FormatInt(i, 10) + "]")
+}
+
+func checkIndex(i int64, n int) int {
+	if i < 0 || i >= int64(n) {
+		indexPanic(i)
+	}
+	return int(i)
+}
+
+func truth(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+func (f *frame) lookup(k varKey) *any {
+	if p := f.find(k); p != nil {
+		return p
+	}
+	fatalf("undefined variable: %s", k.id)
+	return nil
+}
+
+func (f *frame) find(k varKey) *any {
+	if p := f.vars[k]; p != nil {
+		return p
+	}
+	if p := f.env[k]; p != nil {
+		return p
+	}
+	return f.in.globals[k]
+}
+
+// funcOf returns function of declaration.
+// Built-in functions have not declaration tokens.
+func (in *Interp) funcOf(ast *models.Func) *function {
+	if f := in.funcs[ast]; f != nil {
+		return &function{ast: ast, body: f.Body}
+	}
+	if n := natives[ast.Id]; n != nil && ast.Tok.Id == tokens.NA {
+		return &function{ast: ast, native: n}
+	}
+	fatalf("function is not loaded: %s", ast.Id)
+	return nil
+}
+
+func (f *frame) eval(e ir.Expr) any {
+	switch t := e.(type) {
+	case *ir.Const:
+		return constant(t)
+	case *ir.VarRef:
+		return *f.lookup(keyOf(t.Var))
+	case *ir.SelfRef:
+		return f.self
+	case *ir.FuncRef:
+		return f.in.funcOf(t.Func)
+	case *ir.LinkRef:
+		panic(&fatal{err: errors.New(jn.GetError("interp_cpp_link", t.Func.Id))})
+	case *ir.Offsetof:
+		panic(&fatal{err: errors.New(jn.GetError("interp_layout", t.Struct.Kind))})
+	case *ir.EnumItemRef:
+		return f.convert(f.eval(t.Item.Expr.Model), t.Type)
+	case *ir.Binary:
+		return f.binary(t)
+	case *ir.Unary:
+		return f.unary(t)
+	case *ir.AddrOf:
+		return f.addr(t.X)
+	case *ir.Paren:
+		return f.eval(t.X)
+	case *ir.Cast:
+		return f.cast(t)
+	case *ir.Conv:
+		return format(f.eval(t.X))
+	case *ir.Call:
+		return f.call(t)
+	case *ir.Field:
+		return *f.field(t)
+	case *ir.Method:
+		return f.method(t)
+	case *ir.TraitData:
+		return traitData(f.eval(t.X))
+	case *ir.Index:
+		return f.index(t)
+	case *ir.Slice:
+		return f.slicing(t)
+	case *ir.Composite:
+		return f.composite(t)
+	case *ir.MapLit:
+		return f.mapLit(t)
+	case *ir.StructLit:
+		return f.structLit(t)
+	case *ir.Tuple:
+		values := make(tuple, len(t.Values))
+		for i, v := range t.Values {
+			values[i] = f.eval(v)
+		}
+		return values
+	case *ir.Closure:
+		return f.closure(t)
+	case *ir.Default:
+		if t.Type.Kind == "" {
+			return nil
+		}
+		return f.zero(t.Type)
+	case *ir.MustHeap:
+		return f.eval(t.X)
+	}
+	return nil
+}
+
+func constant(c *ir.Const) any {
+	switch t := c.Value.(type) {
+	case nil, bool:
+		return t
+	case string:
+		return jnapi.Unescape(t)
+	}
+	if jntype.IsNumeric(jntype.GetRealCode(c.Type.Id)) {
+		return numconv(c.Value, c.Type.Id)
+	}
+	return c.Value
+}
+
+// numericId returns numeric type of operation.
+// Type of value is used if type is not numeric.
+func (f *frame) numericId(t ir.Type, v any) uint8 {
+	t = f.resolve(t)
+	if t.Id == jntype.Enum && !isPtr(t) {
+		t = t.Tag.(*models.Enum).Type
+	}
+	id := jntype.GetRealCode(t.Id)
+	if jntype.IsNumeric(id) {
+		return id
+	}
+	switch v.(type) {
+	case int64:
+		return jntype.I64
+	case uint64:
+		return jntype.U64
+	case float64:
+		return jntype.F64
+	}
+	return jntype.Void
+}
+
+// shiftType returns type of shift of operand type t.
+// Checker types shifts as u64, but C++ shifts in type of left operand
+// and promotes integers that smaller than int.
+func (f *frame) shiftType(t ir.Type) ir.Type {
+	t = f.resolve(t)
+	switch jntype.GetRealCode(t.Id) {
+	case jntype.I8, jntype.I16, jntype.U8, jntype.U16:
+		return ir.Type{Id: jntype.I32, Kind: jntype.TypeMap[jntype.I32]}
+	}
+	return t
+}
+
+func (f *frame) binary(b *ir.Binary) any {
+	switch b.Op {
+	case tokens.AND:
+		return truth(f.eval(b.X)) && truth(f.eval(b.Y))
+	case tokens.OR:
+		return truth(f.eval(b.X)) || truth(f.eval(b.Y))
+	}
+	x, y := f.eval(b.X), f.eval(b.Y)
+	switch b.Op {
+	case tokens.EQUALS:
+		return equal(x, y)
+	case tokens.NOT_EQUALS:
+		return !equal(x, y)
+	case tokens.LESS:
+		return compare(x, y) == -1
+	case tokens.GREAT:
+		return compare(x, y) == 1
+	case tokens.LESS_EQUAL:
+		c := compare(x, y)
+		return c == -1 || c == 0
+	case tokens.GREAT_EQUAL:
+		c := compare(x, y)
+		return c == 1 || c == 0
+	}
+	t := b.Type
+	if b.Op == tokens.LSHIFT || b.Op == tokens.RSHIFT {
+		t = f.shiftType(b.X.DataType())
+	}
+	return f.arith(b.Op, x, y, t)
+}
+
+// arith returns result of arithmetic operation as type.
+func (f *frame) arith(op string, x, y any, t ir.Type) any {
+	if s, ok := x.(string); ok {
+		ys, _ := y.(string)
+		return s + ys
+	}
+	id := f.numericId(t, x)
+	switch {
+	case jntype.IsFloat(id):
+		a, b := tofloat(x), tofloat(y)
+		var r float64
+		switch op {
+		case tokens.PLUS:
+			r = a + b
+		case tokens.MINUS:
+			r = a - b
+		case tokens.STAR:
+			r = a * b
+		case tokens.SOLIDUS:
+			r = a / b
+		case tokens.PERCENT:
+			r = math.Mod(a, b)
+		}
+		return numconv(r, id)
+	case jntype.IsSignedInteger(id):
+		a, b := toint(x), toint(y)
+		var r int64
+		switch op {
+		case tokens.PLUS:
+			r = a + b
+		case tokens.MINUS:
+			r = a - b
+		case tokens.STAR:
+			r = a * b
+		case tokens.SOLIDUS, tokens.PERCENT:
+			if b == 0 {
+				runtimePanic("divide by zero")
+			}
+			if op == tokens.SOLIDUS {
+				r = a / b
+			} else {
+				r = a % b
+			}
+		case tokens.AMPER:
+			r = a & b
+		case tokens.VLINE:
+			r = a | b
+		case tokens.CARET:
+			r = a ^ b
+		case tokens.LSHIFT:
+			r = a << touint(y)
+		case tokens.RSHIFT:
+			r = a >> touint(y)
+		}
+		return numconv(r, id)
+	case jntype.IsUnsignedInteger(id):
+		a, b := touint(x), touint(y)
+		var r uint64
+		switch op {
+		case tokens.PLUS:
+			r = a + b
+		case tokens.MINUS:
+			r = a - b
+		case tokens.STAR:
+			r = a * b
+		case tokens.SOLIDUS, tokens.PERCENT:
+			if b == 0 {
+				runtimePanic("divide by zero")
+			}
+			if op == tokens.SOLIDUS {
+				r = a / b
+			} else {
+				r = a % b
+			}
+		case tokens.AMPER:
+			r = a & b
+		case tokens.VLINE:
+			r = a | b
+		case tokens.CARET:
+			r = a ^ b
+		case tokens.LSHIFT:
+			r = a << b
+		case tokens.RSHIFT:
+			r = a >> b
+		}
+		return numconv(r, id)
+	}
+	return nil
+}
+
+func (f *frame) unary(u *ir.Unary) any {
+	x := f.eval(u.X)
+	switch u.Op {
+	case tokens.MINUS:
+		id := f.numericId(u.Type, x)
+		switch {
+		case jntype.IsFloat(id):
+			return numconv(-tofloat(x), id)
+		case jntype.IsSignedInteger(id):
+			return numconv(-toint(x), id)
+		}
+		return numconv(-touint(x), id)
+	case tokens.CARET:
+		id := f.numericId(u.Type, x)
+		if jntype.IsSignedInteger(id) {
+			return numconv(^toint(x), id)
+		}
+		return numconv(^touint(x), id)
+	case tokens.EXCLAMATION:
+		return !truth(x)
+	case tokens.STAR:
+		return *deref(x)
+	}
+	return x
+}
+
+func deref(v any) *any {
+	p, _ := v.(*any)
+	if p == nil {
+		nilPanic()
+	}
+	return p
+}
+
+// addr returns location of expression.
+// Temporary location is returned for expressions that not addressable.
+func (f *frame) addr(e ir.Expr) *any {
+	switch t := e.(type) {
+	case *ir.VarRef:
+		return f.lookup(keyOf(t.Var))
+	case *ir.Paren:
+		return f.addr(t.X)
+	case *ir.MustHeap:
+		return f.addr(t.X)
+	case *ir.Unary:
+		if t.Op == tokens.STAR {
+			return deref(f.eval(t.X))
+		}
+	case *ir.Field:
+		return f.field(t)
+	case *ir.Index:
+		if p := f.indexRef(t); p != nil {
+			return p
+		}
+	}
+	p := new(any)
+	*p = f.eval(e)
+	return p
+}
+
+// structOf returns structure of value, pointers and traits are dereferenced.
+func structOf(v any) *structval {
+	for {
+		switch t := v.(type) {
+		case *structval:
+			return t
+		case *any:
+			if t == nil {
+				nilPanic()
+			}
+			v = *t
+		case trait:
+			v = traitData(t)
+		default:
+			nilPanic()
+		}
+	}
+}
+
+func traitData(v any) any {
+	t, _ := v.(trait)
+	if t.data == nil {
+		nilPanic()
+	}
+	return t.data
+}
+
+func (f *frame) field(t *ir.Field) *any {
+	p := new(any)
+	switch {
+	case t.X == nil:
+		*p = f.eval(t.Var.Expr.Model)
+		return p
+	case t.Var.Tag == "len()":
+		*p = length(f.eval(t.X))
+		return p
+	}
+	s := structOf(f.eval(t.X))
+	i, ok := f.in.fields[t.Var]
+	if !ok {
+		i = -1
+		for j, field := range s.s.Fields {
+			if field.Id == t.Var.Id {
+				i = j
+				break
+			}
+		}
+		if i == -1 {
+			fatalf("field is not loaded: %s", t.Var.Id)
+		}
+	}
+	return &s.fields[i]
+}
+
+func length(v any) int64 {
+	switch t := v.(type) {
+	case *any:
+		return length(*deref(t))
+	case string:
+		return int64(len(t))
+	case slice:
+		return int64(len(t.elems))
+	case *array:
+		return int64(len(t.elems))
+	case *mapval:
+		return int64(len(t.keys))
+	}
+	return 0
+}
+
+// receiver returns self pointer for method call on expression.
+func (f *frame) receiver(x ir.Expr) *any {
+	if isPtr(x.DataType()) {
+		return deref(f.eval(x))
+	}
+	return f.addr(x)
+}
+
+func (f *frame) method(m *ir.Method) any {
+	if irf := f.in.funcs[m.Func]; irf != nil {
+		self := f.receiver(m.X)
+		s := structOf(self)
+		return &function{ast: m.Func, body: irf.Body, self: self, gens: s.gens}
+	}
+	x := f.eval(m.X)
+	if p, ok := x.(*any); ok {
+		x = *deref(p)
+	}
+	switch t := x.(type) {
+	case *structval:
+		return f.in.methodOf(t, m.Func.Id)
+	case errorval:
+		return &function{ast: m.Func, native: func(*frame, *ir.Call, []any) any {
+			return string(t)
+		}}
+	}
+	n := builtinMethod(x, m.Func.Id)
+	if n == nil {
+		fatalf("method is not supported: %s", m.Func.Id)
+	}
+	return &function{ast: m.Func, native: n}
+}
+
+// methodOf returns method of structure by identifier.
+// It is used for dynamic dispatch of traits.
+func (in *Interp) methodOf(s *structval, id string) *function {
+	for _, f := range s.s.Funcs {
+		if f.Ast.Id == id {
+			self := new(any)
+			*self = s
+			return &function{ast: f.Ast, body: f.Body, self: self, gens: s.gens}
+		}
+	}
+	fatalf("method is not loaded: %s.%s", s.s.Ast.Id, id)
+	return nil
+}
+
+// args returns function and arguments of call.
+func (f *frame) args(c *ir.Call) (*function, []any) {
+	fn, _ := f.eval(c.Fn).(*function)
+	if fn == nil {
+		nilPanic()
+	}
+	var args []any
+	exprs := c.Args
+	if c.TupleArgs {
+		args = append(args, f.eval(exprs[0]).(tuple)...)
+		exprs = exprs[1:]
+	}
+	for _, arg := range exprs {
+		args = append(args, f.eval(arg))
+	}
+	return fn, args
+}
+
+func (f *frame) call(c *ir.Call) any {
+	fn, args := f.args(c)
+	if fn.native != nil {
+		return fn.native(f, c, args)
+	}
+	return f.in.call(fn, args, genericMap(f, fn.ast.Generics, c.Generics))
+}
+
+// call calls function with arguments.
+// Generics are types of generic types of function.
+func (in *Interp) call(fn *function, args []any, generics map[string]ir.Type) any {
+	if fn.native != nil {
+		return fn.native(&frame{in: in}, &ir.Call{}, args)
+	}
+	f := &frame{
+		in:   in,
+		fn:   fn.ast,
+		vars: map[varKey]*any{},
+		env:  fn.env,
+		self: fn.self,
+		gens: fn.gens,
+	}
+	if len(generics) > 0 {
+		f.gens = map[string]ir.Type{}
+		for id, t := range fn.gens {
+			f.gens[id] = t
+		}
+		for id, t := range generics {
+			f.gens[id] = t
+		}
+	}
+	for i := range fn.ast.Params {
+		p := &fn.ast.Params[i]
+		var v any
+		if i < len(args) {
+			v = args[i]
+		}
+		if !p.Variadic {
+			v = f.convert(v, p.Type)
+		} else if v == nil {
+			v = slice{}
+		}
+		f.vars[paramKey(p)] = &v
+	}
+	if f.block(fn.body) == ctrlRet {
+		return f.ret
+	}
+	return nil
+}
+
+func (f *frame) index(t *ir.Index) any {
+	x := f.eval(t.X)
+	if s, ok := x.(string); ok {
+		return uint64(s[checkIndex(toint(f.eval(t.Index)), len(s))])
+	}
+	return *f.elem(t, x)
+}
+
+func (f *frame) indexRef(t *ir.Index) *any {
+	x := f.eval(t.X)
+	if _, ok := x.(string); ok {
+		return nil
+	}
+	return f.elem(t, x)
+}
+
+// elem returns location of element of slice, array or map.
+func (f *frame) elem(t *ir.Index, x any) *any {
+	i := f.eval(t.Index)
+	switch v := x.(type) {
+	case slice:
+		return &v.elems[checkIndex(toint(i), len(v.elems))]
+	case *array:
+		return &v.elems[checkIndex(toint(i), len(v.elems))]
+	case *mapval:
+		types := f.resolve(t.X.DataType()).Tag.([]ir.Type)
+		return v.ref(f.convert(i, types[0]), func() any { return f.zero(types[1]) })
+	}
+	nilPanic()
+	return nil
+}
+
+func (f *frame) slicing(s *ir.Slice) any {
+	x := f.eval(s.X)
+	n := length(x)
+	low, high := int64(0), n
+	if s.Low != nil {
+		low = toint(f.eval(s.Low))
+	}
+	if s.High != nil {
+		high = toint(f.eval(s.High))
+	}
+	if low < 0 || high < 0 || low > high || high > n {
+		runtimePanic(fmt.Sprintf("index out of range [%d:%d]", low, high))
+	}
+	switch v := x.(type) {
+	case string:
+		return v[low:high]
+	case slice:
+		if low == high {
+			return slice{}
+		}
+		return slice{elems: v.elems[low:high]}
+	case *array:
+		if low == high {
+			return slice{}
+		}
+		return slice{elems: copyElems(v.elems[low:high])}
+	}
+	return nil
+}
+
+func (f *frame) composite(c *ir.Composite) any {
+	t := f.resolve(c.Type)
+	elems := make([]any, len(c.Elems))
+	for i, e := range c.Elems {
+		elems[i] = f.eval(e)
+		if t.ComponentType != nil {
+			elems[i] = f.convert(elems[i], *t.ComponentType)
+		}
+	}
+	if t.Id == jntype.Array {
+		for len(elems) < t.Size.N {
+			elems = append(elems, f.zero(*t.ComponentType))
+		}
+		return &array{elems: elems}
+	}
+	return slice{elems: elems}
+}
+
+func (f *frame) mapLit(m *ir.MapLit) any {
+	types := f.resolve(m.Type).Tag.([]ir.Type)
+	v := newMap()
+	for i, k := range m.Keys {
+		v.set(f.convert(f.eval(k), types[0]), f.convert(f.eval(m.Values[i]), types[1]))
+	}
+	return v
+}
+
+func (f *frame) structLit(l *ir.StructLit) any {
+	s := f.in.newStruct(f, f.resolve(l.Type))
+	sf := &frame{in: f.in, gens: s.gens}
+	for i, arg := range l.Args {
+		if i < len(s.fields) {
+			s.fields[i] = sf.convert(f.eval(arg), s.s.Fields[i].Type)
+		}
+	}
+	return s
+}
+
+// closure returns function of closure.
+// Captured variables are copied like C++ lambdas that captures by value.
+func (f *frame) closure(c *ir.Closure) any {
+	env := make(map[varKey]*any, len(f.env)+len(c.Captures))
+	for k, p := range f.env {
+		env[k] = p
+	}
+	for _, v := range c.Captures {
+		k := keyOf(v)
+		if p := f.find(k); p != nil {
+			v := copyValue(*p)
+			env[k] = &v
+		}
+	}
+	return &function{ast: c.Func, body: c.Body, env: env, self: f.self, gens: f.gens}
+}
+
+func (f *frame) cast(c *ir.Cast) any {
+	t := f.resolve(c.Type)
+	x := f.eval(c.X)
+	if a, ok := x.(anyval); ok && t.Id != jntype.Any {
+		x = a.v
+	}
+	switch {
+	case isPtr(t):
+		return x
+	case t.Id == jntype.Str:
+		s, ok := x.(slice)
+		if !ok {
+			return x
+		}
+		var sb strings.Builder
+		component := f.resolve(c.X.DataType()).ComponentType
+		runes := component != nil && jntype.GetRealCode(component.Id) == jntype.I32
+		for _, elem := range s.elems {
+			if runes {
+				sb.WriteRune(rune(toint(elem)))
+			} else {
+				sb.WriteByte(byte(touint(elem)))
+			}
+		}
+		return sb.String()
+	case t.Id == jntype.Slice:
+		s, ok := x.(string)
+		if !ok {
+			return x
+		}
+		var elems []any
+		if t.ComponentType != nil && jntype.GetRealCode(t.ComponentType.Id) == jntype.I32 {
+			for len(s) > 0 {
+				r, n := utf8.DecodeRuneInString(s)
+				elems = append(elems, int64(r))
+				s = s[n:]
+			}
+		} else {
+			elems = make([]any, len(s))
+			for i := range elems {
+				elems[i] = uint64(s[i])
+			}
+		}
+		return slice{elems: elems}
+	case t.Id == jntype.UIntptr:
+		if p, ok := x.(*any); ok {
+			return uint64(reflect.ValueOf(p).Pointer())
+		}
+	}
+	return f.convert(x, t)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// format returns string form of value.
+// Forms are same with output streams of C++ API.
+func format(v any) string {
+	var sb strings.Builder
+	writeValue(&sb, v)
+	return sb.String()
+}
+
+// stream returns form of value for standard output stream.
+// Unlike format, byte integers are written as characters
+// except struct fields, like stream operators of C++ API.
+func (f *frame) stream(v any, t ir.Type) string {
+	var sb strings.Builder
+	f.writeStream(&sb, v, t)
+	return sb.String()
+}
+
+func (f *frame) writeStream(sb *strings.Builder, v any, t ir.Type) {
+	t = f.resolve(t)
+	if isPtr(t) {
+		writeValue(sb, v)
+		return
+	}
+	switch id := jntype.GetRealCode(t.Id); {
+	case id == jntype.I8 || id == jntype.U8:
+		switch n := v.(type) {
+		case int64:
+			sb.WriteByte(byte(n))
+			return
+		case uint64:
+			sb.WriteByte(byte(n))
+			return
+		}
+	case (id == jntype.Slice || id == jntype.Array) && t.ComponentType != nil:
+		var elems []any
+		switch x := v.(type) {
+		case slice:
+			elems = x.elems
+		case *array:
+			elems = x.elems
+		}
+		sb.WriteByte('[')
+		for i, elem := range elems {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			f.writeStream(sb, elem, *t.ComponentType)
+		}
+		sb.WriteByte(']')
+		return
+	case id == jntype.Map:
+		m, ok := v.(*mapval)
+		if !ok {
+			break
+		}
+		types := t.Tag.([]ir.Type)
+		sb.WriteByte('{')
+		i := 0
+		m.each(func(e *entry) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			f.writeStream(sb, e.key, types[0])
+			sb.WriteByte(':')
+			f.writeStream(sb, e.value, types[1])
+			i++
+		})
+		sb.WriteByte('}')
+		return
+	}
+	writeValue(sb, v)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', 6, 64)
+}
+
+func writeElems(sb *strings.Builder, elems []any, open, close byte) {
+	sb.WriteByte(open)
+	for i, elem := range elems {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeValue(sb, elem)
+	}
+	sb.WriteByte(close)
+}
+
+func writeValue(sb *strings.Builder, v any) {
+	switch t := v.(type) {
+	case nil:
+		sb.WriteByte('0')
+	case int64:
+		sb.WriteString(strconv.FormatInt(t, 10))
+	case uint64:
+		sb.WriteString(strconv.FormatUint(t, 10))
+	case float64:
+		sb.WriteString(formatFloat(t))
+	case bool:
+		sb.WriteString(strconv.FormatBool(t))
+	case string:
+		sb.WriteString(t)
+	case slice:
+		writeElems(sb, t.elems, '[', ']')
+	case *array:
+		writeElems(sb, t.elems, '[', ']')
+	case tuple:
+		writeElems(sb, t, '(', ')')
+	case *mapval:
+		sb.WriteByte('{')
+		i := 0
+		t.each(func(e *entry) {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeValue(sb, e.key)
+			sb.WriteByte(':')
+			writeValue(sb, e.value)
+			i++
+		})
+		sb.WriteByte('}')
+	case *structval:
+		sb.WriteString(t.s.Ast.Id)
+		sb.WriteByte('{')
+		for i, field := range t.s.Fields {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(field.Id)
+			sb.WriteByte(':')
+			writeValue(sb, t.fields[i])
+		}
+		sb.WriteByte('}')
+	case anyval:
+		if t.v == nil {
+			sb.WriteByte('0')
+		} else {
+			sb.WriteString("<any>")
+		}
+	case trait:
+		fmt.Fprintf(sb, "%p", t.data)
+	case errorval:
+		sb.WriteString(string(t))
+	default:
+		fmt.Fprintf(sb, "%p", v)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package interp implements tree-walking interpreter for IR of Jane programs.
+//
+// Interpreter executes checked programs directly, without C++ toolchain.
+// Values are follows semantics of C++ API of Jane, such as
+// value semantics of arrays, maps and structures, shared buffers of slices
+// and block scoped deferred calls.
+package interp
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+)
+
+// ExitPanic is exit code of programs that terminated by panic.
+const ExitPanic = 2
+
+// Panic is uncaught panic of program.
+type Panic struct {
+	// Err is trait value of Error trait.
+	Err any
+	msg string
+}
+
+func (p *Panic) Error() string { return p.msg }
+
+// fatal is error that stops interpreter, such as unsupported features.
+type fatal struct {
+	err error
+}
+
+// Interp executes IR of Jane programs.
+// Definitions of loaded packages are stays alive between calls,
+// so interpreter is useable incrementally.
+type Interp struct {
+	out     *bufio.Writer
+	outMu   sync.Mutex
+	funcs   map[*models.Func]*ir.Func
+	structs map[string]*ir.Struct
+	fields  map[*models.Var]int
+	globals map[varKey]*any
+	inits   []*ir.Func
+	inited  map[*models.Func]bool
+	entry   *ir.Func
+}
+
+// New returns new interpreter that writes outputs of program to stdout.
+func New(stdout io.Writer) *Interp {
+	return &Interp{
+		out:     bufio.NewWriter(stdout),
+		funcs:   map[*models.Func]*ir.Func{},
+		structs: map[string]*ir.Struct{},
+		fields:  map[*models.Var]int{},
+		globals: map[varKey]*any{},
+		inited:  map[*models.Func]bool{},
+	}
+}
+
+// Load declares definitions of package and initializes global variables.
+// Globals and initializers of previously loaded packages are not reset,
+// C++ links are not reported here, calling them at runtime is an error.
+func (in *Interp) Load(pkg *ir.Package) (err error) {
+	for _, s := range pkg.Structs {
+		in.structs[structOutId(s)] = s
+		for i, field := range s.Fields {
+			in.fields[field] = i
+		}
+		for _, f := range s.Funcs {
+			in.funcs[f.Ast] = f
+		}
+	}
+	for _, f := range pkg.Funcs {
+		in.funcs[f.Ast] = f
+		if f.Entry {
+			in.entry = f
+		}
+	}
+	for _, f := range pkg.Inits {
+		if !in.inited[f.Ast] {
+			in.inited[f.Ast] = true
+			in.inits = append(in.inits, f)
+		}
+	}
+	return in.protect(func() {
+		f := &frame{in: in, vars: in.globals}
+		for _, g := range pkg.Globals {
+			if _, ok := in.globals[keyOf(g.Var)]; !ok {
+				f.varDecl(g)
+			}
+		}
+	})
+}
+
+// Run calls initializers and entry point of loaded packages.
+// Returned error is *Panic if program is terminated by panic.
+func (in *Interp) Run() error {
+	return in.protect(func() {
+		in.initialize()
+		if in.entry != nil {
+			in.call(in.funcOf(in.entry.Ast), nil, nil)
+		}
+	})
+}
+
+// initialize calls initializers that not called yet.
+func (in *Interp) initialize() {
+	inits := in.inits
+	in.inits = nil
+	for _, init := range inits {
+		in.call(in.funcOf(init.Ast), nil, nil)
+	}
+}
+
+// protect runs fn and returns panics of program and fatal errors as error.
+func (in *Interp) protect(fn func()) (err error) {
+	defer func() {
+		in.flush()
+		switch t := recover().(type) {
+		case nil:
+		case *jnpanic:
+			err = &Panic{Err: t.err, msg: in.errorMessage(t.err)}
+		case *fatal:
+			err = t.err
+		default:
+			panic(t)
+		}
+	}()
+	fn()
+	return nil
+}
+
+func (in *Interp) write(s string) {
+	in.outMu.Lock()
+	in.out.WriteString(s)
+	in.outMu.Unlock()
+}
+
+func (in *Interp) flush() {
+	in.outMu.Lock()
+	in.out.Flush()
+	in.outMu.Unlock()
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+// load parses and checks src as main package and loads it to interpreter.
+// Source is written to own directory, so it is not shares local package.
+func load(t *testing.T, src string) (*Interp, *bytes.Buffer) {
+	t.Helper()
+	stdlib, err := filepath.Abs(filepath.Join("..", "..", jn.Stdlib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "main.jn")
+	if err := os.WriteFile(path, []byte(src), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := jnio.ReadJn(jnio.OS, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := parser.New(f)
+	p.Env = parser.NewEnv(jnio.OS, stdlib, nil)
+	p.Parsef(true, false)
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	out := new(bytes.Buffer)
+	in := New(out)
+	if err := in.Load(p.IR()); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return in, out
+}
+
+// TestRunGolden runs testdata/*.jn and compares outputs with
+// testdata/*.golden files. Outputs of golden files are same with
+// outputs of programs that compiled with C++ backend.
+func TestRunGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.jn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test sources")
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".jn"), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden, err := os.ReadFile(strings.TrimSuffix(path, ".jn") + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			in, out := load(t, string(src))
+			if err := in.Run(); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if out.String() != string(golden) {
+				t.Errorf("output differs from golden file:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		out  string
+		msg  string
+	}{
+		{
+			name: "index",
+			src:  "main() {\n\tdefer println(\"deferred\")\n\txs: = []int{1}\n\ti: = 3\n\tprintln(xs[i])\n}\n",
+			// Deferred calls are not evaluated for uncaught panics like C++.
+			out: "",
+			msg: "index out of range [3]",
+		},
+		{
+			name: "divide",
+			src:  "main() {\n\tprintln(\"start\")\n\tx: = 0\n\tprintln(1 / x)\n}\n",
+			out:  "start\n",
+			msg:  "divide by zero",
+		},
+		{
+			name: "error",
+			src: `struct failure {
+	message: str
+}
+
+impl Error for failure {
+	&error() str {
+		ret "failure: " + .message
+	}
+}
+
+main() {
+	panic(failure{"boom"})
+}
+`,
+			msg: "failure: boom",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in, out := load(t, c.src)
+			err := in.Run()
+			var p *Panic
+			if !errors.As(err, &p) {
+				t.Fatalf("program is not panicked: %v", err)
+			}
+			if p.Error() != c.msg {
+				t.Errorf("message: got %q, want %q", p.Error(), c.msg)
+			}
+			if out.String() != c.out {
+				t.Errorf("output: got %q, want %q", out, c.out)
+			}
+		})
+	}
+}
+
+func TestRunCppLink(t *testing.T) {
+	in, _ := load(t, "cpp sqrt(x f64) f64\n\nmain() {\n\t_ = cpp.sqrt(4)\n}\n")
+	err := in.Run()
+	if err == nil {
+		t.Fatal("C++ link is called")
+	}
+	var p *Panic
+	if errors.As(err, &p) {
+		t.Fatalf("C++ link is reported as panic: %v", err)
+	}
+	if !strings.Contains(err.Error(), "sqrt") {
+		t.Errorf("error is not mentions link: %v", err)
+	}
+}
+
+// TestRunGlobals initializes globals once even if package loaded again.
+func TestRunGlobals(t *testing.T) {
+	in, out := load(t, "count: = 1\n\nmain() {\n\tcount++\n\tprintln(count)\n}\n")
+	for i := 0; i < 2; i++ {
+		if err := in.Run(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.String() != "2\n3\n" {
+		t.Errorf("output: %q", out)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import "github.com/DeRuneLabs/jane/ir"
+
+// Session executes statements of entry point one by one,
+// such as inputs of read-eval-print loops.
+// Variables of executed statements are stays alive between executions.
+type Session struct {
+	f *frame
+}
+
+// NewSession returns new session of interpreter.
+func (in *Interp) NewSession() *Session {
+	return &Session{f: &frame{in: in, vars: map[varKey]*any{}}}
+}
+
+// Exec executes statements in scope of entry point of last loaded package.
+// Deferred calls of statements are called at end of execution.
+// Returned error is *Panic if statements are terminated by panic.
+func (s *Session) Exec(stmts []ir.Stmt) error {
+	return s.run(func() {
+		s.f.block(&ir.Block{Stmts: stmts})
+	})
+}
+
+// Eval evaluates expression in session and returns value as
+// written by println function.
+func (s *Session) Eval(x ir.Expr) (v string, err error) {
+	err = s.run(func() {
+		v = s.f.stream(s.f.eval(x), x.DataType())
+	})
+	return v, err
+}
+
+func (s *Session) run(fn func()) error {
+	in := s.f.in
+	return in.protect(func() {
+		in.initialize()
+		if in.entry != nil {
+			s.f.fn = in.entry.Ast
+		}
+		fn()
+	})
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"os"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// ctrl is control flow result of statement.
+type ctrl int
+
+const (
+	ctrlNone ctrl = iota
+	ctrlBreak
+	ctrlContinue
+	ctrlRet
+	ctrlGoto
+	ctrlFallthrough
+	ctrlBreakMatch
+)
+
+// block executes block.
+// Deferred calls are evaluated at exit of block like C++ destructors.
+// C++ unwinds blocks only if panic is caught, so deferred calls of
+// panicked blocks are evaluated by try statement that catches panic.
+func (f *frame) block(b *ir.Block) ctrl {
+	if b == nil {
+		return ctrlNone
+	}
+	var defers []ir.Expr
+	evalDefers := func() {
+		for i := len(defers) - 1; i >= 0; i-- {
+			f.eval(defers[i])
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if p, ok := r.(*jnpanic); ok && len(defers) > 0 {
+				p.unwind = append(p.unwind, evalDefers)
+			}
+			panic(r)
+		}
+		evalDefers()
+	}()
+	stmts := b.Stmts
+	for i := 0; i < len(stmts); i++ {
+		if d, ok := stmts[i].(*ir.Defer); ok {
+			defers = append(defers, d.Call)
+			continue
+		}
+		c := f.stmt(stmts[i])
+		if c == ctrlGoto {
+			if j := labelIndex(stmts, f.label); j != -1 {
+				i = j
+				continue
+			}
+		}
+		if c != ctrlNone {
+			return c
+		}
+	}
+	return ctrlNone
+}
+
+func labelIndex(stmts []ir.Stmt, label string) int {
+	for i, s := range stmts {
+		if l, ok := s.(*ir.Label); ok && l.Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *frame) stmt(s ir.Stmt) ctrl {
+	switch t := s.(type) {
+	case *ir.Block:
+		return f.block(t)
+	case *ir.ExprStmt:
+		f.eval(t.X)
+	case *ir.VarDecl:
+		f.varDecl(t)
+	case *ir.Assign:
+		f.assign(t)
+	case *ir.IncDec:
+		op := tokens.PLUS
+		if t.Op == tokens.DOUBLE_MINUS {
+			op = tokens.MINUS
+		}
+		p := f.addr(t.X)
+		*p = f.arith(op, *p, int64(1), t.X.DataType())
+	case *ir.TupleAssign:
+		f.tupleAssign(t)
+	case *ir.If:
+		return f.ifStmt(t)
+	case *ir.Loop:
+		return f.loop(nil, t.Body, nil)
+	case *ir.While:
+		return f.loop(t.Cond, t.Body, nil)
+	case *ir.For:
+		if t.Init != nil {
+			f.stmt(t.Init)
+		}
+		return f.loop(t.Cond, t.Body, t.Post)
+	case *ir.Foreach:
+		return f.foreach(t)
+	case *ir.Match:
+		return f.matchStmt(t)
+	case *ir.Fallthrough:
+		f.fall = t.Case
+		return ctrlFallthrough
+	case *ir.Break:
+		if t.Match != nil {
+			f.match = t.Match
+			return ctrlBreakMatch
+		}
+		return ctrlBreak
+	case *ir.Continue:
+		return ctrlContinue
+	case *ir.Goto:
+		f.label = t.Label
+		return ctrlGoto
+	case *ir.Ret:
+		if t.X != nil {
+			f.ret = f.retValue(f.eval(t.X))
+		}
+		return ctrlRet
+	case *ir.Co:
+		f.co(t)
+	case *ir.Try:
+		return f.try(t)
+	}
+	return ctrlNone
+}
+
+func (f *frame) varDecl(d *ir.VarDecl) {
+	var v any
+	if d.Init != nil {
+		v = f.convert(f.eval(d.Init), d.Var.Type)
+	} else {
+		v = f.zero(d.Var.Type)
+	}
+	f.vars[keyOf(d.Var)] = &v
+}
+
+func isAssignOp(op string) bool {
+	return op == tokens.EQUAL || op == tokens.COLON+tokens.EQUAL
+}
+
+func (f *frame) store(left ir.Expr, op string, v any) {
+	t := left.DataType()
+	if index, ok := left.(*ir.Index); ok && f.resolve(index.X.DataType()).Id == jntype.Str {
+		p := f.addr(index.X)
+		s := []byte((*p).(string))
+		i := checkIndex(toint(f.eval(index.Index)), len(s))
+		if !isAssignOp(op) {
+			v = f.arith(op[:len(op)-1], uint64(s[i]), v, t)
+		}
+		s[i] = byte(touint(v))
+		*p = string(s)
+		return
+	}
+	p := f.addr(left)
+	if !isAssignOp(op) {
+		v = f.arith(op[:len(op)-1], *p, v, t)
+	}
+	*p = f.convert(v, t)
+}
+
+func (f *frame) assign(a *ir.Assign) {
+	v := f.eval(a.Right)
+	if a.Left != nil {
+		f.store(a.Left, a.Op, v)
+	}
+}
+
+func (f *frame) tupleAssign(a *ir.TupleAssign) {
+	for _, d := range a.Decls {
+		f.varDecl(d)
+	}
+	var values []any
+	if len(a.Right) == 1 {
+		values, _ = f.eval(a.Right[0]).(tuple)
+	} else {
+		for _, right := range a.Right {
+			values = append(values, f.eval(right))
+		}
+	}
+	for i, left := range a.Left {
+		if left != nil && i < len(values) {
+			f.store(left, a.Op, values[i])
+		}
+	}
+}
+
+// retValue returns return value for return type of function.
+func (f *frame) retValue(v any) any {
+	if f.fn == nil {
+		return v
+	}
+	t := f.fn.RetType.Type
+	if !t.MultiTyped {
+		return f.convert(v, t)
+	}
+	values, _ := v.(tuple)
+	types := t.Tag.([]ir.Type)
+	ret := make(tuple, len(values))
+	for i, v := range values {
+		if i < len(types) {
+			ret[i] = f.convert(v, types[i])
+		}
+	}
+	return ret
+}
+
+func (f *frame) ifStmt(i *ir.If) ctrl {
+	for {
+		if truth(f.eval(i.Cond)) {
+			return f.block(i.Then)
+		}
+		switch t := i.Else.(type) {
+		case *ir.If:
+			i = t
+		case *ir.Block:
+			return f.block(t)
+		default:
+			return ctrlNone
+		}
+	}
+}
+
+// iteration returns true if iteration is continues after result of body.
+func iteration(c *ctrl) bool {
+	switch *c {
+	case ctrlNone, ctrlContinue:
+		*c = ctrlNone
+		return true
+	case ctrlBreak:
+		*c = ctrlNone
+	}
+	return false
+}
+
+func (f *frame) loop(cond ir.Expr, body *ir.Block, post ir.Stmt) ctrl {
+	for cond == nil || truth(f.eval(cond)) {
+		if c := f.block(body); !iteration(&c) {
+			return c
+		}
+		if post != nil {
+			f.stmt(post)
+		}
+	}
+	return ctrlNone
+}
+
+func (f *frame) foreach(t *ir.Foreach) ctrl {
+	keyed := !jnapi.IsIgnoreId(t.Key.Id)
+	valued := !jnapi.IsIgnoreId(t.Value.Id)
+	body := func(k, v any) ctrl {
+		if keyed {
+			k = f.convert(k, t.Key.Type)
+			f.vars[keyOf(t.Key)] = &k
+		}
+		if valued {
+			v = f.convert(v, t.Value.Type)
+			f.vars[keyOf(t.Value)] = &v
+		}
+		return f.block(t.Body)
+	}
+	if !keyed {
+		// Range iteration over values.
+		keyed, valued = false, true
+	}
+	switch x := f.eval(t.X).(type) {
+	case string:
+		for i := 0; i < len(x); i++ {
+			if c := body(int64(i), uint64(x[i])); !iteration(&c) {
+				return c
+			}
+		}
+	case slice:
+		for i, elem := range x.elems {
+			if c := body(int64(i), elem); !iteration(&c) {
+				return c
+			}
+		}
+	case *array:
+		for i, elem := range x.elems {
+			if c := body(int64(i), elem); !iteration(&c) {
+				return c
+			}
+		}
+	case *mapval:
+		var entries []*entry
+		x.each(func(e *entry) { entries = append(entries, e) })
+		for _, e := range entries {
+			if c := body(e.key, e.value); !iteration(&c) {
+				return c
+			}
+		}
+	}
+	return ctrlNone
+}
+
+func (f *frame) matchCase(m *ir.Match, x any) *ir.Case {
+	for _, c := range m.Cases {
+		for _, e := range c.Exprs {
+			v := f.eval(e)
+			if (m.X == nil && truth(v)) || (m.X != nil && equal(v, x)) {
+				return c
+			}
+		}
+	}
+	return m.Default
+}
+
+func (f *frame) matchStmt(m *ir.Match) ctrl {
+	var x any
+	if m.X != nil {
+		x = f.convert(f.eval(m.X), m.Type)
+	}
+	c := f.matchCase(m, x)
+	for c != nil {
+		switch ctl := f.block(c.Body); ctl {
+		case ctrlFallthrough:
+			c = f.fall.Next
+		case ctrlBreakMatch:
+			if f.match != m {
+				return ctl
+			}
+			return ctrlNone
+		default:
+			return ctl
+		}
+	}
+	return ctrlNone
+}
+
+// co calls function concurrently.
+// Function and arguments are evaluated before starting of goroutine.
+func (f *frame) co(c *ir.Co) {
+	call, ok := c.Call.(*ir.Call)
+	if !ok {
+		return
+	}
+	fn, args := f.args(call)
+	var generics map[string]ir.Type
+	if fn.native == nil {
+		generics = genericMap(f, fn.ast.Generics, call.Generics)
+	}
+	go func() {
+		defer func() {
+			switch t := recover().(type) {
+			case nil:
+			case *jnpanic:
+				f.in.write("panic: " + f.in.errorMessage(t.err) + "\n")
+				f.in.flush()
+				os.Exit(ExitPanic)
+			default:
+				panic(t)
+			}
+		}()
+		f.in.call(fn, args, generics)
+	}()
+}
+
+// protect executes block and returns panic of block.
+func (f *frame) protect(b *ir.Block) (c ctrl, p *jnpanic) {
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if p, ok = r.(*jnpanic); !ok {
+				panic(r)
+			}
+		}
+	}()
+	return f.block(b), nil
+}
+
+func (f *frame) try(t *ir.Try) ctrl {
+	c, p := f.protect(t.Body)
+	if p == nil {
+		return c
+	}
+	for _, unwind := range p.unwind {
+		unwind()
+	}
+	err := p.err
+	f.vars[paramKey(t.Param)] = &err
+	if closure, ok := t.Handler.(*ir.Closure); ok {
+		return f.block(closure.Body)
+	}
+	if fn, ok := f.eval(t.Handler).(*function); ok {
+		f.in.call(fn, []any{err}, nil)
+	}
+	return ctrlNone
+}
+
+// errorMessage returns message of Error trait value.
+func (in *Interp) errorMessage(err any) string {
+	t, _ := err.(trait)
+	switch data := t.data.(type) {
+	case errorval:
+		return string(data)
+	case *structval:
+		msg, _ := in.call(in.methodOf(data, "error"), nil, nil).(string)
+		return msg
+	}
+	return ""
+}
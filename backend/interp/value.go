@@ -0,0 +1,577 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package interp
+
+import (
+	"math"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// Values are represented with following Go types:
+//
+//	signed integers:   int64
+//	unsigned integers: uint64
+//	floats:            float64
+//	bool:              bool
+//	str:               string
+//	pointers:          *any
+//	slices:            slice
+//	arrays:            *array
+//	maps:              *mapval
+//	structures:        *structval
+//	traits:            trait
+//	any:               anyval
+//	functions:         *function
+//	multiple values:   tuple
+//
+// Nil pointers, traits, functions and anys are nil.
+
+// varKey identifies variable.
+// Declarations and references are not share same models,
+// so variables are identified by identifier and position.
+type varKey struct {
+	file   *jnio.File
+	row    int
+	column int
+	id     string
+}
+
+func keyOf(v *models.Var) varKey {
+	return varKey{v.Token.File, v.Token.Row, v.Token.Column, v.Id}
+}
+
+func paramKey(p *models.Param) varKey {
+	return varKey{p.Tok.File, p.Tok.Row, p.Tok.Column, p.Id}
+}
+
+// slice is slice value.
+// Slicing results are shares buffer of source slice.
+type slice struct {
+	elems []any
+}
+
+type array struct {
+	elems []any
+}
+
+type entry struct {
+	key   any
+	value any
+}
+
+// mapval is map value.
+// Entries are kept in insertion order.
+type mapval struct {
+	keys    []any
+	entries map[any]*entry
+}
+
+type structval struct {
+	s      *ir.Struct
+	gens   map[string]ir.Type
+	fields []any
+}
+
+// trait is trait value.
+// Data is *structval or errorval, copies are shares data.
+type trait struct {
+	data any
+}
+
+// errorval is Error implementation of runtime panics.
+type errorval string
+
+type anyval struct {
+	v any
+}
+
+// native is built-in function.
+type native func(f *frame, c *ir.Call, args []any) any
+
+type function struct {
+	ast    *models.Func
+	body   *ir.Block
+	env    map[varKey]*any
+	self   *any
+	gens   map[string]ir.Type
+	native native
+}
+
+type tuple []any
+
+// jnpanic is Go panic of Jane panics, err is trait value of Error.
+// unwind is deferred calls of panicked blocks, innermost first.
+type jnpanic struct {
+	err    any
+	unwind []func()
+}
+
+// hashed is key of map for values that not comparable by Go.
+type hashed string
+
+func newMap() *mapval {
+	return &mapval{entries: map[any]*entry{}}
+}
+
+func hashKey(k any) any {
+	switch k.(type) {
+	case nil, int64, uint64, float64, bool, string:
+		return k
+	}
+	return hashed(format(k))
+}
+
+func (m *mapval) get(k any) *entry {
+	return m.entries[hashKey(k)]
+}
+
+// ref returns value of key, inserts default value if key is not exist.
+func (m *mapval) ref(k any, def func() any) *any {
+	h := hashKey(k)
+	e := m.entries[h]
+	if e == nil {
+		e = &entry{key: k, value: def()}
+		m.entries[h] = e
+		m.keys = append(m.keys, h)
+	}
+	return &e.value
+}
+
+func (m *mapval) set(k, v any) {
+	*m.ref(k, func() any { return nil }) = v
+}
+
+func (m *mapval) del(k any) {
+	h := hashKey(k)
+	if m.entries[h] == nil {
+		return
+	}
+	delete(m.entries, h)
+	for i, key := range m.keys {
+		if key == h {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (m *mapval) each(fn func(e *entry)) {
+	for _, h := range m.keys {
+		fn(m.entries[h])
+	}
+}
+
+// copyValue returns copy of value types.
+func copyValue(v any) any {
+	switch t := v.(type) {
+	case *array:
+		return &array{elems: copyElems(t.elems)}
+	case *structval:
+		return &structval{s: t.s, gens: t.gens, fields: copyElems(t.fields)}
+	case *mapval:
+		m := newMap()
+		t.each(func(e *entry) { m.set(e.key, copyValue(e.value)) })
+		return m
+	case tuple:
+		return tuple(copyElems(t))
+	}
+	return v
+}
+
+func copyElems(elems []any) []any {
+	c := make([]any, len(elems))
+	for i, elem := range elems {
+		c[i] = copyValue(elem)
+	}
+	return c
+}
+
+func isPtr(t ir.Type) bool {
+	return t.Kind != "" && t.Kind[0] == '*'
+}
+
+// resolve returns type of generic type.
+func (f *frame) resolve(t ir.Type) ir.Type {
+	if len(f.gens) == 0 || (t.Id != jntype.Id && !t.Generic) {
+		return t
+	}
+	if g, ok := f.gens[t.Kind]; ok {
+		return g
+	}
+	return t
+}
+
+// zero returns default value of type.
+func (f *frame) zero(t ir.Type) any {
+	t = f.resolve(t)
+	switch {
+	case isPtr(t):
+		return nil
+	case t.MultiTyped:
+		types := t.Tag.([]ir.Type)
+		values := make(tuple, len(types))
+		for i, t := range types {
+			values[i] = f.zero(t)
+		}
+		return values
+	}
+	id := jntype.GetRealCode(t.Id)
+	switch {
+	case jntype.IsSignedInteger(id):
+		return int64(0)
+	case jntype.IsUnsignedInteger(id):
+		return uint64(0)
+	case jntype.IsFloat(id):
+		return float64(0)
+	}
+	switch t.Id {
+	case jntype.Bool:
+		return false
+	case jntype.Str:
+		return ""
+	case jntype.Slice:
+		return slice{}
+	case jntype.Array:
+		elems := make([]any, t.Size.N)
+		for i := range elems {
+			elems[i] = f.zero(*t.ComponentType)
+		}
+		return &array{elems: elems}
+	case jntype.Map:
+		return newMap()
+	case jntype.Struct:
+		return f.in.newStruct(f, t)
+	case jntype.Enum:
+		return f.zero(t.Tag.(*models.Enum).Type)
+	}
+	return nil
+}
+
+// convert returns value for storing into location of type.
+// Value types are copied and numeric values are fits into type.
+func (f *frame) convert(v any, t ir.Type) any {
+	t = f.resolve(t)
+	switch {
+	case isPtr(t) || t.MultiTyped:
+		return v
+	case v == nil:
+		return f.zero(t)
+	}
+	id := jntype.GetRealCode(t.Id)
+	if jntype.IsNumeric(id) {
+		return numconv(v, id)
+	}
+	switch t.Id {
+	case jntype.Enum:
+		return f.convert(v, t.Tag.(*models.Enum).Type)
+	case jntype.Trait:
+		if s, ok := v.(*structval); ok {
+			return trait{data: copyValue(s)}
+		}
+		return v
+	case jntype.Any:
+		if _, ok := v.(anyval); !ok {
+			return anyval{v: copyValue(v)}
+		}
+		return v
+	}
+	return copyValue(v)
+}
+
+// numconv returns numeric value as numeric type.
+func numconv(v any, id uint8) any {
+	id = jntype.GetRealCode(id)
+	switch id {
+	case jntype.F32:
+		return float64(float32(tofloat(v)))
+	case jntype.F64:
+		return tofloat(v)
+	case jntype.I8:
+		return int64(int8(toint(v)))
+	case jntype.I16:
+		return int64(int16(toint(v)))
+	case jntype.I32:
+		return int64(int32(toint(v)))
+	case jntype.I64:
+		return toint(v)
+	case jntype.U8:
+		return uint64(uint8(touint(v)))
+	case jntype.U16:
+		return uint64(uint16(touint(v)))
+	case jntype.U32:
+		return uint64(uint32(touint(v)))
+	case jntype.U64:
+		return touint(v)
+	}
+	return v
+}
+
+func toint(v any) int64 {
+	switch t := v.(type) {
+	case int64:
+		return t
+	case uint64:
+		return int64(t)
+	case float64:
+		return int64(t)
+	case anyval:
+		return toint(t.v)
+	}
+	return 0
+}
+
+func touint(v any) uint64 {
+	switch t := v.(type) {
+	case int64:
+		return uint64(t)
+	case uint64:
+		return t
+	case float64:
+		if t < 0 {
+			return uint64(int64(t))
+		}
+		return uint64(t)
+	case anyval:
+		return touint(t.v)
+	}
+	return 0
+}
+
+func tofloat(v any) float64 {
+	switch t := v.(type) {
+	case int64:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case float64:
+		return t
+	case anyval:
+		return tofloat(t.v)
+	}
+	return 0
+}
+
+func isNil(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case slice:
+		return t.elems == nil
+	case *mapval:
+		return len(t.keys) == 0
+	case trait:
+		return t.data == nil
+	case anyval:
+		return t.v == nil
+	case *any:
+		return t == nil
+	case *function:
+		return t == nil
+	}
+	return false
+}
+
+// unordered is result of comparison of NaN values.
+const unordered = 2
+
+// compare compares numeric or str values.
+// Returns -1, 0, 1 or unordered.
+func compare(x, y any) int {
+	if a, ok := x.(anyval); ok {
+		x = a.v
+	}
+	if b, ok := y.(anyval); ok {
+		y = b.v
+	}
+	switch a := x.(type) {
+	case string:
+		b, _ := y.(string)
+		return strings.Compare(a, b)
+	case float64:
+		return compareFloat(a, tofloat(y))
+	case int64:
+		switch b := y.(type) {
+		case float64:
+			return compareFloat(float64(a), b)
+		case uint64:
+			if a < 0 {
+				return -1
+			}
+			return compareUint(uint64(a), b)
+		}
+		b := toint(y)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case uint64:
+		switch b := y.(type) {
+		case float64:
+			return compareFloat(float64(a), b)
+		case int64:
+			if b < 0 {
+				return 1
+			}
+		}
+		return compareUint(a, touint(y))
+	case bool:
+		b, _ := y.(bool)
+		if a == b {
+			return 0
+		}
+		return 1
+	}
+	return unordered
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case math.IsNaN(a) || math.IsNaN(b):
+		return unordered
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func equal(x, y any) bool {
+	switch {
+	case x == nil:
+		return isNil(y)
+	case y == nil:
+		return isNil(x)
+	}
+	switch a := x.(type) {
+	case int64, uint64, float64, string, bool:
+		return compare(x, y) == 0
+	case slice:
+		b, ok := y.(slice)
+		return ok && equalElems(a.elems, b.elems)
+	case *array:
+		b, ok := y.(*array)
+		return ok && equalElems(a.elems, b.elems)
+	case tuple:
+		b, ok := y.(tuple)
+		return ok && equalElems(a, b)
+	case *structval:
+		b, ok := y.(*structval)
+		return ok && equalElems(a.fields, b.fields)
+	case *mapval:
+		b, ok := y.(*mapval)
+		if !ok || len(a.keys) != len(b.keys) {
+			return false
+		}
+		for _, h := range a.keys {
+			e := b.entries[h]
+			if e == nil || !equal(a.entries[h].value, e.value) {
+				return false
+			}
+		}
+		return true
+	case anyval:
+		if b, ok := y.(anyval); ok {
+			return equal(a.v, b.v)
+		}
+		return equal(a.v, y)
+	case trait:
+		b, ok := y.(trait)
+		return ok && a.data == b.data
+	case *any:
+		b, ok := y.(*any)
+		return ok && a == b
+	case *function:
+		b, ok := y.(*function)
+		return ok && a == b
+	}
+	return false
+}
+
+func equalElems(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// newStruct returns structure instance of type with default values.
+func (in *Interp) newStruct(f *frame, t ir.Type) *structval {
+	cs := t.Tag.(models.CompiledStruct)
+	s := in.structs[cs.OutId()]
+	if s == nil {
+		fatalf("struct is not loaded: " + t.Kind)
+	}
+	sv := &structval{s: s, gens: genericMap(f, s.Ast.Generics, cs.Generics())}
+	sf := &frame{in: in, gens: sv.gens}
+	sv.fields = make([]any, len(s.Fields))
+	for i, field := range s.Fields {
+		if field.Expr.Model != nil {
+			sv.fields[i] = sf.convert(sf.eval(field.Expr.Model), field.Type)
+		} else {
+			sv.fields[i] = sf.zero(field.Type)
+		}
+	}
+	return sv
+}
+
+func structOutId(s *ir.Struct) string {
+	return jnapi.OutId(s.Ast.Id, s.Ast.Tok.File)
+}
+
+// genericMap returns generic types by identifiers,
+// types are resolved by frame.
+func genericMap(f *frame, generics []*models.GenericType, types []ir.Type) map[string]ir.Type {
+	if len(generics) == 0 || len(types) == 0 {
+		return nil
+	}
+	gens := map[string]ir.Type{}
+	for i, g := range generics {
+		if i < len(types) {
+			gens[g.Id] = f.resolve(types[i])
+		}
+	}
+	return gens
+}
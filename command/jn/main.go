@@ -21,23 +21,37 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
+	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
 
+	backend "github.com/DeRuneLabs/jane/backend/cpp"
+	"github.com/DeRuneLabs/jane/backend/interp"
 	"github.com/DeRuneLabs/jane/documenter"
+	"github.com/DeRuneLabs/jane/formatter"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lsp"
 	"github.com/DeRuneLabs/jane/package/jn"
 	"github.com/DeRuneLabs/jane/package/jnapi"
 	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
 	"github.com/DeRuneLabs/jane/package/jnset"
 	"github.com/DeRuneLabs/jane/parser"
+	"github.com/DeRuneLabs/jane/repl"
 )
 
 type Parser = parser.Parser
@@ -47,27 +61,162 @@ const (
 	commandVersion = "version"
 	commandInit    = "init"
 	commandDoc     = "doc"
+	commandRun     = "run"
+	commandTest    = "test"
+	commandFmt     = "fmt"
+	commandLsp     = "lsp"
+	commandRepl    = "repl"
+	commandBuild   = "build"
 )
 
 const (
-	localizationErrors   = "error.json"
-	localizationWarnings = "warning.json"
+	exitSuccess  = 0
+	exitCompile  = 1
+	exitUsage    = 2
+	exitSettings = 3
+	exitIO       = 4
 )
 
-var helpmap = [...][2]string{
-	0: {commandHelp, "Show help."},
-	1: {commandVersion, "Show version."},
-	2: {commandInit, "Initialize new project here."},
-	3: {commandDoc, "Documentize Jn source code."},
+const (
+	diagnosticsText  = "text"
+	diagnosticsJSON  = "json"
+	diagnosticsSARIF = "sarif"
+)
+
+const (
+	localizationErrors   = "errors.json"
+	localizationWarnings = "warnings.json"
+	localizationNotes    = "notes.json"
+)
+
+// sourceMapExt is extension of source map that appended to C++ output path.
+const sourceMapExt = ".map"
+
+type overrides [][2]string
+
+func (o *overrides) String() string {
+	parts := make([]string, len(*o))
+	for i, pair := range *o {
+		parts[i] = pair[0] + "=" + pair[1]
+	}
+	return strings.Join(parts, ",")
 }
 
-func help(cmd string) {
-	if cmd != "" {
-		println("This mod can only be used as single")
-		return
+func (o *overrides) Set(value string) error {
+	i := strings.IndexByte(value, '=')
+	if i < 1 {
+		return errors.New(jn.GetError("invalid_set_override", value))
+	}
+	*o = append(*o, [2]string{value[:i], value[i+1:]})
+	return nil
+}
+
+func (o *overrides) key(key, value string) {
+	if value != "" {
+		*o = append(*o, [2]string{key, value})
 	}
-	max := len(helpmap[0][0])
-	for _, key := range helpmap {
+}
+
+type command struct {
+	name  string
+	usage string
+	desc  string
+	flags *flag.FlagSet
+	fn    func(args, rest []string)
+}
+
+var (
+	setOverrides overrides
+	outName      string
+	outDir       string
+	mode         string
+	werror       bool
+	lineDirs     bool
+	noDce        bool
+	sourceMap    bool
+	diagnostics  string
+	diagOutput   string
+	testRun      string
+	testFilter   *regexp.Regexp
+	fmtWrite     bool
+	fmtCheck     bool
+	useInterp    bool
+)
+
+var commands []*command
+
+func setFlag(fs *flag.FlagSet) {
+	fs.Var(&setOverrides, "set", "Override a jn.set key: key=value (repeatable).")
+}
+
+func outDirFlag(fs *flag.FlagSet) {
+	fs.StringVar(&outDir, "out-dir", "", "Output directory of generated code (cxx_out_dir).")
+}
+
+func lineDirectivesFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&lineDirs, "line-directives", false, "Emit #line directives of Jane sources into C++ (line_directives).")
+}
+
+func noDceFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&noDce, "no-dce", false, "Emit unreachable definitions into C++ (dce=false).")
+}
+
+func werrorFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&werror, "werror", false, "Treat warnings as errors (werror).")
+}
+
+func diagnosticsFlag(fs *flag.FlagSet) {
+	fs.StringVar(&diagnostics, "diagnostics-format", diagnosticsText, "Diagnostics output format: text, json or sarif.")
+	fs.StringVar(&diagOutput, "diagnostics-output", "", "Write json or sarif diagnostics to file.")
+}
+
+func newCommand(name, usage, desc string, fn func(args, rest []string)) *command {
+	cmd := &command{
+		name:  name,
+		usage: usage,
+		desc:  desc,
+		flags: flag.NewFlagSet(name, flag.ContinueOnError),
+		fn:    fn,
+	}
+	cmd.flags.SetOutput(io.Discard)
+	return cmd
+}
+
+func commandByName(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func helpmap() [][2]string {
+	m := make([][2]string, len(commands))
+	for i, cmd := range commands {
+		m[i] = [2]string{cmd.name, cmd.desc}
+	}
+	return m
+}
+
+func flagmap(cmd *command) [][2]string {
+	var m [][2]string
+	cmd.flags.VisitAll(func(f *flag.Flag) {
+		name := "-" + f.Name
+		if len(f.Name) > 1 {
+			name = "-" + name
+		}
+		if _, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok {
+			name += " <value>"
+		}
+		m = append(m, [2]string{name, f.Usage})
+	})
+	return m
+}
+
+func helpTable(parts [][2]string) string {
+	max := 0
+	for _, key := range parts {
 		len := len(key[0])
 		if len > max {
 			max = len
@@ -75,143 +224,436 @@ func help(cmd string) {
 	}
 	var sb strings.Builder
 	const space = 5
-	for _, part := range helpmap {
+	for _, part := range parts {
 		sb.WriteString(part[0])
 		sb.WriteString(strings.Repeat(" ", (max-len(part[0]))+space))
 		sb.WriteString(part[1])
 		sb.WriteByte('\n')
 	}
-	println(sb.String()[:sb.Len()-1])
+	return sb.String()
+}
+
+func commandHelpText(cmd *command) string {
+	var sb strings.Builder
+	sb.WriteString("usage: jane ")
+	sb.WriteString(cmd.name)
+	if cmd.usage != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(cmd.usage)
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(cmd.desc)
+	sb.WriteByte('\n')
+	flags := flagmap(cmd)
+	if len(flags) > 0 {
+		sb.WriteString("\noptions:\n")
+		for _, line := range strings.SplitAfter(helpTable(flags), "\n") {
+			if line != "" {
+				sb.WriteString("  ")
+				sb.WriteString(line)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func help(args, _ []string) {
+	switch len(args) {
+	case 0:
+		table := helpTable(helpmap())
+		println(table[:len(table)-1])
+	case 1:
+		cmd := commandByName(args[0])
+		if cmd == nil {
+			println(jn.GetError("undefined_command", args[0]))
+			exit(exitUsage)
+		}
+		text := commandHelpText(cmd)
+		println(text[:len(text)-1])
+	default:
+		println("This mod can only be used as single")
+	}
 }
 
-func version(cmd string) {
-	if cmd != "" {
+func version(args, _ []string) {
+	if len(args) > 0 {
 		println("This mod can only be used as single!")
 		return
 	}
 	println("jn version", jn.Version)
 }
 
-func initProject(cmd string) {
-	if cmd != "" {
+func initProject(args, _ []string) {
+	if len(args) > 0 {
 		println("This module can only be used as single!")
 		return
 	}
 	bytes, err := json.MarshalIndent(*jnset.Default, "", "\t")
 	if err != nil {
 		println(err)
-		os.Exit(0)
+		exit(exitSettings)
 	}
 	err = ioutil.WriteFile(jn.SettingsFile, bytes, 0666)
 	if err != nil {
 		println(err.Error())
-		os.Exit(0)
+		exit(exitIO)
 	}
 	println("Initialized project.")
 }
 
-func doc(cmd string) {
-	cmd = strings.TrimSpace(cmd)
-	paths := strings.SplitN(cmd, " ", -1)
+func doc(paths, _ []string) {
+	code := exitSuccess
 	for _, path := range paths {
-		path = strings.TrimSpace(path)
 		p := compile(path, false, true, true)
 		if p == nil {
+			code = exitIO
 			continue
 		}
 		if printlogs(p) {
 			fmt.Println(jn.GetError("doc_couldnt_generated", path))
+			code = exitCompile
 			continue
 		}
 		docjson, err := documenter.Doc(p)
 		if err != nil {
 			fmt.Println(jn.GetError("error", err.Error()))
+			code = exitCompile
 			continue
 		}
 		path = path[:len(path)-len(jn.SrcExt)]
 		path = filepath.Join(jn.Set.CppOutDir, path+jn.DocExt)
 		writeOutput(path, docjson)
 	}
+	exit(code)
 }
 
-func processCommand(namespace, cmd string) bool {
-	switch namespace {
-	case commandHelp:
-		help(cmd)
-	case commandVersion:
-		version(cmd)
-	case commandInit:
-		initProject(cmd)
-	case commandDoc:
-		doc(cmd)
-	default:
-		return false
+// sourceFiles returns source files of paths.
+// directories are replaced with their useable source files.
+func sourceFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && strings.HasSuffix(name, jn.SrcExt) && jnio.IsUseable(name) {
+				files = append(files, filepath.Join(path, name))
+			}
+		}
 	}
-	return true
+	return files, nil
 }
 
-func init() {
-	execp, err := os.Executable()
+func format(paths, _ []string) {
+	if len(paths) == 0 {
+		println(jn.GetError("missing_source_path"))
+		exit(exitUsage)
+	}
+	files, err := sourceFiles(paths)
 	if err != nil {
 		println(err.Error())
-		os.Exit(0)
+		exit(exitIO)
 	}
-	execp = filepath.Dir(execp)
-	jn.ExecPath = execp
-	jn.StdlibPath = filepath.Join(jn.ExecPath, jn.Stdlib)
-	jnapi.JNCHeader = filepath.Join(jn.ExecPath, "api")
-	jnapi.JNCHeader = filepath.Join(jnapi.JNCHeader, "jnc.hpp")
-	jn.LangsPath = filepath.Join(jn.ExecPath, jn.Localizations)
+	info, err := os.Stat(jn.SettingsFile)
+	if err == nil && !info.IsDir() {
+		loadJnSet()
+	} else {
+		jn.Set = jnset.Default
+	}
+	indent := strings.Repeat(jn.Set.Indent, jn.Set.IndentCount)
+	code := exitSuccess
+	for _, path := range files {
+		f, err := jnio.OpenJn(path)
+		if err != nil {
+			println(err.Error())
+			code = exitIO
+			continue
+		}
+		src, logs := formatter.Format(f, indent)
+		if len(logs) > 0 {
+			printLogs(logs, nil)
+			code = exitCompile
+			continue
+		}
+		switch {
+		case fmtCheck:
+			if src != string(f.Data) {
+				fmt.Println(path)
+				code = exitCompile
+			}
+		case fmtWrite:
+			if src == string(f.Data) {
+				break
+			}
+			err = os.WriteFile(path, []byte(src), 0o666)
+			if err != nil {
+				println(err.Error())
+				code = exitIO
+			}
+		default:
+			fmt.Print(src)
+		}
+	}
+	exit(code)
+}
 
-	if len(os.Args) < 2 {
-		os.Exit(0)
+func serveLsp(args, _ []string) {
+	if len(args) > 0 {
+		println("This module can only be used as single!")
+		exit(exitUsage)
 	}
-	var sb strings.Builder
-	for _, arg := range os.Args[1:] {
-		sb.WriteString(" " + arg)
+	info, err := os.Stat(jn.SettingsFile)
+	if err == nil && !info.IsDir() {
+		loadJnSet()
+	} else {
+		jn.Set = jnset.Default
 	}
-	os.Args[0] = sb.String()[1:]
-	arg := os.Args[0]
-	i := strings.Index(arg, " ")
-	if i == -1 {
-		i = len(arg)
+	// stdout is reserved for protocol messages
+	server := lsp.NewServer(os.Stdin, os.Stdout)
+	server.Log = log.New(os.Stderr, "jane lsp: ", 0)
+	if err := server.Run(); err != nil {
+		println(err.Error())
+		exit(exitIO)
+	}
+}
+
+func startRepl(args, _ []string) {
+	if len(args) > 0 {
+		println("This module can only be used as single!")
+		exit(exitUsage)
+	}
+	info, err := os.Stat(jn.SettingsFile)
+	if err == nil && !info.IsDir() {
+		loadJnSet()
+	} else {
+		jn.Set = jnset.Default
 	}
-	if processCommand(arg[:i], arg[i:]) {
-		os.Exit(0)
+	r := repl.New(os.Stdin, os.Stdout)
+	r.Color = colorful()
+	if err := r.Run(); err != nil {
+		println(err.Error())
+		exit(exitIO)
 	}
 }
 
-func loadLangWarns(path string, infos []fs.FileInfo) {
-	i := -1
-	for j, f := range infos {
-		if f.IsDir() || f.Name() != localizationWarnings {
-			continue
+func singlePath(args []string) string {
+	switch len(args) {
+	case 0:
+		println(jn.GetError("missing_source_path"))
+		exit(exitUsage)
+	case 1:
+	default:
+		println(jn.GetError("argument_overflow"))
+		exit(exitUsage)
+	}
+	return args[0]
+}
+
+func run(args, rest []string) {
+	diagnosed.stderr = true
+	path := singlePath(args)
+	if useInterp {
+		exit(interpret(path))
+	}
+	cpp, sm, code := transpile(path, true)
+	if code != exitSuccess {
+		exit(code)
+	}
+	execute(cpp, sm, rest)
+}
+
+// execute compiles cpp into temporary directory and runs it.
+// exits with exit code of program if it fails.
+func execute(cpp string, sm *backend.SourceMap, args []string) {
+	dir, err := os.MkdirTemp("", "jane-run-")
+	if err != nil {
+		println(err.Error())
+		exit(exitIO)
+	}
+	defer os.RemoveAll(dir)
+	cppPath := filepath.Join(dir, jn.Set.CppOutName)
+	outPath := filepath.Join(dir, jn.Set.OutName)
+	writeOutput(cppPath, cpp)
+	err = compileCpp(cppPath, outPath, sm)
+	if err != nil {
+		println(jn.GetError("cxx_compile_failed", err.Error()))
+		os.RemoveAll(dir)
+		exit(exitCompile)
+	}
+	// diagnostics are not mixed with output of program
+	flushDiagnostics()
+	program := exec.Command(outPath, args...)
+	program.Stdin = os.Stdin
+	program.Stdout = os.Stdout
+	program.Stderr = os.Stderr
+	err = program.Run()
+	if err != nil {
+		code := exitIO
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else {
+			println(err.Error())
 		}
-		i = j
-		path = filepath.Join(path, f.Name())
-		break
+		os.RemoveAll(dir)
+		exit(code)
 	}
-	if i == -1 {
-		return
+}
+
+// packageMainFile returns first useable source file of directory.
+func packageMainFile(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
 	}
-	bytes, err := ioutil.ReadFile(path)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, jn.SrcExt) && jnio.IsUseable(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", errors.New(jn.GetError("no_source_files", dir))
+	}
+	sort.Strings(names)
+	return filepath.Join(dir, names[0]), nil
+}
+
+func test(args, _ []string) {
+	diagnosed.stderr = true
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	path, err := packageMainFile(singlePath(args))
 	if err != nil {
-		println("Language's warnings couldn't loaded (uses default);")
 		println(err.Error())
-		return
+		exit(exitIO)
 	}
-	err = json.Unmarshal(bytes, &jn.Warnings)
+	testFilter, err = regexp.Compile(testRun)
+	if err != nil {
+		println(jn.GetError("invalid_test_pattern", err.Error()))
+		exit(exitUsage)
+	}
+	cpp, sm, code := transpile(path, true)
+	if code != exitSuccess {
+		exit(code)
+	}
+	execute(cpp, sm, nil)
+}
+
+func build(args, _ []string) {
+	path := singlePath(args)
+	cpp, sm, code := transpile(path, false)
+	if code != exitSuccess {
+		exit(code)
+	}
+	path = filepath.Join(jn.Set.CppOutDir, jn.Set.CppOutName)
+	if jn.Set.SourceMap {
+		writeSourceMap(path+sourceMapExt, sm)
+	}
+	doSpell(path, cpp, sm)
+}
+
+// parseArgs parses flags of command from args and returns positional
+// arguments. flags and positional arguments may be interleaved.
+// arguments after the "--" terminator are returned as rest.
+func parseArgs(cmd *command, args []string) (positional, rest []string, err error) {
+	for i, arg := range args {
+		if arg == "--" {
+			rest = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+	for {
+		err = cmd.flags.Parse(args)
+		if err != nil {
+			return
+		}
+		args = cmd.flags.Args()
+		if len(args) == 0 {
+			return
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func init() {
+	execp, err := os.Executable()
 	if err != nil {
-		println("Language's warnings couldn't loaded (uses default);")
 		println(err.Error())
-		return
+		exit(exitIO)
+	}
+	execp = filepath.Dir(execp)
+	jn.ExecPath = execp
+	jn.StdlibPath = filepath.Join(jn.ExecPath, jn.Stdlib)
+	jnapi.JNCHeader = filepath.Join(jn.ExecPath, "api")
+	jnapi.JNCHeader = filepath.Join(jnapi.JNCHeader, "jnc.hpp")
+	jn.LangsPath = filepath.Join(jn.ExecPath, jn.Localizations)
+
+	buildCmd := newCommand(commandBuild, "[options] <file.jn>", "Transpile (or compile) Jn program.", build)
+	buildCmd.flags.StringVar(&outName, "o", "", "Output binary name (out_name).")
+	buildCmd.flags.StringVar(&mode, "mode", "", "Compiler mode: transpile or compile (mode).")
+	outDirFlag(buildCmd.flags)
+	werrorFlag(buildCmd.flags)
+	lineDirectivesFlag(buildCmd.flags)
+	noDceFlag(buildCmd.flags)
+	buildCmd.flags.BoolVar(&sourceMap, "source-map", false, "Write JSON source map of C++ output (source_map).")
+	diagnosticsFlag(buildCmd.flags)
+	setFlag(buildCmd.flags)
+	runCmd := newCommand(commandRun, "[options] <file.jn> [-- args...]", "Compile and run Jn program.", run)
+	runCmd.flags.BoolVar(&useInterp, "interp", false, "Run program with interpreter instead of C++ compiler.")
+	werrorFlag(runCmd.flags)
+	lineDirectivesFlag(runCmd.flags)
+	noDceFlag(runCmd.flags)
+	diagnosticsFlag(runCmd.flags)
+	setFlag(runCmd.flags)
+	testCmd := newCommand(commandTest, "[options] [dir]", "Run @test functions of Jn package.", test)
+	testCmd.flags.StringVar(&testRun, "run", "", "Run only tests matching regular expression.")
+	werrorFlag(testCmd.flags)
+	lineDirectivesFlag(testCmd.flags)
+	noDceFlag(testCmd.flags)
+	diagnosticsFlag(testCmd.flags)
+	setFlag(testCmd.flags)
+	fmtCmd := newCommand(commandFmt, "[options] <file.jn|dir>...", "Format Jn source code.", format)
+	fmtCmd.flags.BoolVar(&fmtWrite, "w", false, "Write result to source file instead of stdout.")
+	fmtCmd.flags.BoolVar(&fmtCheck, "check", false, "List files whose formatting differs and exit with error.")
+	docCmd := newCommand(commandDoc, "[options] <file.jn>...", "Documentize Jn source code.", doc)
+	outDirFlag(docCmd.flags)
+	werrorFlag(docCmd.flags)
+	diagnosticsFlag(docCmd.flags)
+	setFlag(docCmd.flags)
+	commands = []*command{
+		newCommand(commandHelp, "[command]", "Show help.", help),
+		newCommand(commandVersion, "", "Show version.", version),
+		newCommand(commandInit, "", "Initialize new project here.", initProject),
+		buildCmd,
+		runCmd,
+		testCmd,
+		fmtCmd,
+		docCmd,
+		newCommand(commandLsp, "", "Run language server over stdio.", serveLsp),
+		newCommand(commandRepl, "", "Run interactive read-eval-print loop.", startRepl),
 	}
 }
 
-func loadLangErrs(path string, infos []fs.FileInfo) {
+// loadLangMessages loads messages of file name in language directory
+// into messages. kind is used for error descriptions.
+func loadLangMessages(path string, infos []fs.FileInfo, name, kind string, messages *map[string]string) {
 	i := -1
 	for j, f := range infos {
-		if f.IsDir() || f.Name() != localizationErrors {
+		if f.IsDir() || f.Name() != name {
 			continue
 		}
 		i = j
@@ -223,13 +665,13 @@ func loadLangErrs(path string, infos []fs.FileInfo) {
 	}
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		println("Language's errors couldn't loaded (uses default);")
+		println("Language's " + kind + " couldn't loaded (uses default);")
 		println(err.Error())
 		return
 	}
-	err = json.Unmarshal(bytes, &jn.Errors)
+	err = json.Unmarshal(bytes, messages)
 	if err != nil {
-		println("Language's errors couldn't loaded (uses default);")
+		println("Language's " + kind + " couldn't loaded (uses default);")
 		println(err.Error())
 		return
 	}
@@ -247,82 +689,252 @@ func loadLang() {
 		println(err.Error())
 		return
 	}
-	loadLangWarns(path, infos)
-	loadLangErrs(path, infos)
+	loadLangMessages(path, infos, localizationWarnings, "warnings", &jn.Warnings)
+	loadLangMessages(path, infos, localizationErrors, "errors", &jn.Errors)
+	loadLangMessages(path, infos, localizationNotes, "notes", &jn.Notes)
+}
+
+func setKey(field string) string {
+	key, _ := reflect.TypeOf(jn.Set).Elem().FieldByName(field)
+	tag := string(key.Tag)
+	return tag[6 : len(tag)-1]
 }
 
 func checkMode() {
 	lower := strings.ToLower(jn.Set.Mode)
 	if lower != jnset.ModeTranspile &&
 		lower != jnset.ModeCompile {
-		key, _ := reflect.TypeOf(jn.Set).Elem().FieldByName("Mode")
-		tag := string(key.Tag)
-		tag = tag[6 : len(tag)-1]
-		println(jn.GetError("invalid_value_for_key", jn.Set.Mode, tag))
-		os.Exit(0)
+		println(jn.GetError("invalid_value_for_key", jn.Set.Mode, setKey("Mode")))
+		exit(exitSettings)
 	}
 	jn.Set.Mode = lower
 }
 
+func checkCompiler() {
+	jn.Set.Compiler = strings.TrimSpace(jn.Set.Compiler)
+	if jn.Set.Compiler == "" {
+		println(jn.GetError("invalid_value_for_key", jn.Set.Compiler, setKey("Compiler")))
+		exit(exitSettings)
+	}
+	for _, level := range jnset.Optimizations {
+		if jn.Set.Optimization == level {
+			return
+		}
+	}
+	println(jn.GetError("invalid_value_for_key", jn.Set.Optimization, setKey("Optimization")))
+	exit(exitSettings)
+}
+
+func overrideSet(key, value string) {
+	set := reflect.ValueOf(jn.Set).Elem()
+	for i := 0; i < set.NumField(); i++ {
+		if set.Type().Field(i).Tag.Get("json") != key {
+			continue
+		}
+		var err error
+		field := set.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			var n int
+			n, err = strconv.Atoi(value)
+			field.SetInt(int64(n))
+		case reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(value)
+			field.SetBool(b)
+		case reflect.Slice:
+			parts := []string{}
+			if value != "" {
+				parts = strings.Split(value, ",")
+			}
+			field.Set(reflect.ValueOf(parts))
+		}
+		if err != nil {
+			println(jn.GetError("invalid_value_for_key", value, key))
+			exit(exitSettings)
+		}
+		return
+	}
+	println(jn.GetError("undefined_set_key", key))
+	exit(exitSettings)
+}
+
+func applyOverrides() {
+	var all overrides
+	all.key(setKey("Mode"), mode)
+	all.key(setKey("CppOutDir"), outDir)
+	all.key(setKey("OutName"), outName)
+	if werror {
+		all.key(setKey("Werror"), "true")
+	}
+	if lineDirs {
+		all.key(setKey("LineDirectives"), "true")
+	}
+	if sourceMap {
+		all.key(setKey("SourceMap"), "true")
+	}
+	if noDce {
+		all.key(setKey("Dce"), "false")
+	}
+	all = append(all, setOverrides...)
+	for _, pair := range all {
+		overrideSet(pair[0], pair[1])
+	}
+}
+
 func loadJnSet() {
 	info, err := os.Stat(jn.SettingsFile)
 	if err != nil || info.IsDir() {
 		println(`JN settings file ("` + jn.SettingsFile + `") is not found!`)
-		os.Exit(0)
+		exit(exitSettings)
 	}
 	bytes, err := os.ReadFile(jn.SettingsFile)
 	if err != nil {
 		println(err.Error())
-		os.Exit(0)
+		exit(exitIO)
 	}
 	jn.Set, err = jnset.Load(bytes)
 	if err != nil {
 		println("X settings has errors;")
 		println(err.Error())
-		os.Exit(0)
+		exit(exitSettings)
 	}
+	applyOverrides()
 	loadLang()
 	checkMode()
+	checkCompiler()
 }
 
-func printlogs(p *Parser) bool {
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
+
+// diagnosed is logs of invocation for json and sarif formats.
+// logs are written as one document by flushDiagnostics.
+var diagnosed struct {
+	errors   []jnlog.CompilerLog
+	warnings []jnlog.CompilerLog
+	pending  bool
+	// stderr reports document is written to stderr,
+	// stdout is reserved for output of program.
+	stderr bool
+}
+
+func diagnosticsDocument(errors, warnings []jnlog.CompilerLog) ([]byte, error) {
+	logs := append(warnings[:len(warnings):len(warnings)], errors...)
+	switch diagnostics {
+	case diagnosticsSARIF:
+		return jnlog.SARIF(logs, "jane", jn.Version)
+	default:
+		return jnlog.JSON(logs)
+	}
+}
+
+// flushDiagnostics writes collected logs of invocation
+// if format is json or sarif.
+func flushDiagnostics() {
+	if !diagnosed.pending {
+		return
+	}
+	diagnosed.pending = false
+	bytes, err := diagnosticsDocument(diagnosed.errors, diagnosed.warnings)
+	if err != nil {
+		println(err.Error())
+		os.Exit(exitIO)
+	}
+	bytes = append(bytes, '\n')
+	switch {
+	case diagOutput != "":
+		err = os.WriteFile(diagOutput, bytes, 0o666)
+	case diagnosed.stderr:
+		_, err = os.Stderr.Write(bytes)
+	default:
+		_, err = os.Stdout.Write(bytes)
+	}
+	if err != nil {
+		println(err.Error())
+		os.Exit(exitIO)
+	}
+}
+
+// exit writes diagnostics and exits with code.
+func exit(code int) {
+	flushDiagnostics()
+	os.Exit(code)
+}
+
+func colorful() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func printLogs(errors, warnings []jnlog.CompilerLog) {
+	if diagnostics != diagnosticsText {
+		diagnosed.errors = append(diagnosed.errors, errors...)
+		diagnosed.warnings = append(diagnosed.warnings, warnings...)
+		diagnosed.pending = true
+		return
+	}
+	color := colorful()
 	var str strings.Builder
-	for _, log := range p.Warnings {
-		str.WriteString(log.String())
+	for _, log := range warnings {
+		str.WriteString(log.Pretty(color))
+		str.WriteByte('\n')
+	}
+	for _, log := range errors {
+		str.WriteString(log.Pretty(color))
 		str.WriteByte('\n')
 	}
-	for _, log := range p.Errors {
-		str.WriteString(log.String())
+	if len(errors)+len(warnings) > 0 {
+		str.WriteString(plural(len(errors), "error"))
+		str.WriteString(", ")
+		str.WriteString(plural(len(warnings), "warning"))
 		str.WriteByte('\n')
 	}
 	print(str.String())
-	return len(p.Errors) > 0
+}
+
+// interpret runs program with interpreter instead of C++ toolchain.
+// Returns exit code of program.
+func interpret(path string) int {
+	p := compile(path, true, false, false)
+	if p == nil {
+		return exitIO
+	}
+	if printlogs(p) {
+		return exitCompile
+	}
+	in := interp.New(os.Stdout)
+	err := in.Load(p.IR())
+	if err == nil {
+		err = in.Run()
+	}
+	switch err.(type) {
+	case nil:
+		return exitSuccess
+	case *interp.Panic:
+		fmt.Println("panic: " + err.Error())
+		return interp.ExitPanic
+	}
+	println(err.Error())
+	return exitCompile
+}
+
+func printlogs(p *Parser) bool {
+	printLogs(p.Errors, p.Warnings)
+	return len(p.Errors) > 0 || (jn.Set.Werror && len(p.Warnings) > 0)
 }
 
 func appendStandard(code *string) {
-	year, month, day := time.Now().Date()
-	hour, min, _ := time.Now().Clock()
-	timeStr := fmt.Sprintf("%d/%d/%d %d.%d (DD/MM/YYYY) (HH.MM)",
-		day, month, year, hour, min)
-	var sb strings.Builder
-	sb.WriteString("// Auto generated by JN compiler.\n")
-	sb.WriteString("// JN version:")
-	sb.WriteString(jn.Version)
-	sb.WriteByte('\n')
-	sb.WriteString("// Date: ")
-	sb.WriteString(timeStr)
-	sb.WriteString("\n\n")
-	sb.WriteString("// this file contains cpp module code which is automatically generated by JN")
-	sb.WriteByte('\n')
-	sb.WriteString("// compiler. generated code in this file provide cpp functions and structures")
-	sb.WriteByte('\n')
-	sb.WriteString("// corresponding to the definition in the JN source files")
-	sb.WriteString("\n\n")
-	sb.WriteString("\n\n#include \"")
-	sb.WriteString(jnapi.JNCHeader)
-	sb.WriteString("\"\n\n")
-	sb.WriteString(*code)
-	*code = sb.String()
+	*code = jnapi.Standard(jnapi.JNCHeader) + *code
 }
 
 func writeOutput(path, content string) {
@@ -330,14 +942,24 @@ func writeOutput(path, content string) {
 	err := os.MkdirAll(dir, 0o777)
 	if err != nil {
 		println(err.Error())
-		os.Exit(0)
+		exit(exitIO)
 	}
 	bytes := []byte(content)
 	err = ioutil.WriteFile(path, bytes, 0o666)
 	if err != nil {
 		println(err.Error())
-		os.Exit(0)
+		exit(exitIO)
+	}
+}
+
+// writeSourceMap writes source map as JSON to path.
+func writeSourceMap(path string, sm *backend.SourceMap) {
+	bytes, err := json.MarshalIndent(sm, "", "\t")
+	if err != nil {
+		println(err.Error())
+		exit(exitIO)
 	}
+	writeOutput(path, string(bytes))
 }
 
 func compile(path string, main, nolocal, justDefs bool) *Parser {
@@ -359,6 +981,10 @@ func compile(path string, main, nolocal, justDefs bool) *Parser {
 	}
 	p.File = f
 	p.NoLocalPkg = nolocal
+	if testFilter != nil {
+		p.IsTest = true
+		p.TestFilter = testFilter.MatchString
+	}
 	p.Parsef(main, justDefs)
 	return p
 }
@@ -374,27 +1000,120 @@ func execPostCommands() {
 	}
 }
 
-func doSpell(path, cpp string) {
-	defer execPostCommands()
+func compilerArgs(path, out string) []string {
+	var args []string
+	if jn.Set.CxxStandard != "" {
+		args = append(args, "-std="+jn.Set.CxxStandard)
+	}
+	if jn.Set.Optimization != "" {
+		args = append(args, "-O"+jn.Set.Optimization)
+	}
+	args = append(args, jn.Set.CxxFlags...)
+	for _, dir := range jn.Set.IncludeDirs {
+		args = append(args, "-I"+dir)
+	}
+	args = append(args, path, "-o", out)
+	for _, lib := range jn.Set.LinkLibs {
+		args = append(args, "-l"+lib)
+	}
+	return args
+}
+
+// compileCpp compiles C++ code of path.
+// Diagnostics of compiler are mapped to Jane sources by sm,
+// output is printed as is if it has no mappable errors.
+func compileCpp(path, out string, sm *backend.SourceMap) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(jn.Set.Compiler, compilerArgs(path, out)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	errs, warns := backend.Diagnostics(stderr.String(), path, sm)
+	if err != nil && len(errs) == 0 {
+		print(backend.Demangle(stderr.String()))
+		return err
+	}
+	if len(errs)+len(warns) > 0 {
+		printLogs(errs, warns)
+	}
+	return err
+}
+
+func doSpell(path, cpp string, sm *backend.SourceMap) {
 	writeOutput(path, cpp)
 	switch jn.Set.Mode {
 	case jnset.ModeCompile:
-		defer os.Remove(path)
-		println("compilation is not supported yet")
+		err := compileCpp(path, jn.Set.OutName, sm)
+		os.Remove(path)
+		if err != nil {
+			println(jn.GetError("cxx_compile_failed", err.Error()))
+			exit(exitCompile)
+		}
 	}
+	execPostCommands()
 }
 
-func main() {
-	fpath := os.Args[0]
-	p := compile(fpath, true, false, false)
+// transpile returns C++ code of path.
+// compiled reports code is compiled even if mode is not compile.
+// Source map is not nil if it is enabled by settings or code is compiled.
+func transpile(path string, compiled bool) (cpp string, sm *backend.SourceMap, code int) {
+	p := compile(path, true, false, false)
 	if p == nil {
-		return
+		return "", nil, exitIO
 	}
 	if printlogs(p) {
-		os.Exit(0)
+		return "", nil, exitCompile
 	}
-	cpp := p.Cpp()
+	compiled = compiled || jn.Set.Mode == jnset.ModeCompile
+	lines := jn.Set.LineDirectives || jn.Set.SourceMap || compiled
+	pkg := p.IR()
+	if jn.Set.Dce {
+		ir.EliminateDeadCode(pkg)
+	}
+	cpp = backend.Generate(pkg, jn.Set.Indent, jn.Set.IndentCount, lines)
 	appendStandard(&cpp)
-	path := filepath.Join(jn.Set.CppOutDir, jn.Set.CppOutName)
-	doSpell(path, cpp)
+	if lines {
+		cpp, sm = backend.MapLines(cpp, jn.Set.CppOutName, !jn.Set.LineDirectives)
+	}
+	return cpp, sm, exitSuccess
+}
+
+func checkDiagnosticsFormat() error {
+	switch diagnostics {
+	case "", diagnosticsText:
+		diagnostics = diagnosticsText
+	case diagnosticsJSON, diagnosticsSARIF:
+	default:
+		return errors.New(jn.GetError("invalid_diagnostics_format", diagnostics))
+	}
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		help(nil, nil)
+		return
+	}
+	cmd := commandByName(os.Args[1])
+	args := os.Args[2:]
+	if cmd == nil {
+		cmd = commandByName(commandBuild)
+		args = os.Args[1:]
+	}
+	positional, rest, err := parseArgs(cmd, args)
+	if err == nil {
+		err = checkDiagnosticsFormat()
+	}
+	switch {
+	case err == flag.ErrHelp:
+		text := commandHelpText(cmd)
+		println(text[:len(text)-1])
+		return
+	case err != nil:
+		println(err.Error())
+		println(`run "jane help ` + cmd.name + `" for usage`)
+		exit(exitUsage)
+	}
+	cmd.fn(positional, rest)
+	flushDiagnostics()
 }
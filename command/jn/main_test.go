@@ -0,0 +1,737 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jnset"
+)
+
+// mainEnv is environment variable that makes test binary run as jane.
+// Value of variable is root directory of repository.
+const mainEnv = "JANE_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if root := os.Getenv(mainEnv); root != "" {
+		jn.ExecPath = root
+		jn.StdlibPath = filepath.Join(root, jn.Stdlib)
+		jnapi.JNCHeader = filepath.Join(root, "api", "jnc.hpp")
+		jn.LangsPath = filepath.Join(root, "localization_lang")
+		main()
+		os.Exit(exitSuccess)
+	}
+	os.Exit(m.Run())
+}
+
+type result struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// project creates project directory with default settings and files.
+// settings are default if files have not settings file.
+func project(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if _, ok := files[jn.SettingsFile]; !ok {
+		files[jn.SettingsFile] = "{}"
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o666)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// jane runs jane with args in dir.
+func jane(t *testing.T, dir string, args ...string) result {
+	t.Helper()
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), mainEnv+"="+root, "NO_COLOR=1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	r := result{stdout: stdout.String(), stderr: stderr.String()}
+	if exit, ok := err.(*exec.ExitError); ok {
+		r.code = exit.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func needCompiler(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("g++"); err != nil {
+		t.Skip("g++ is not installed")
+	}
+}
+
+const testSource = `struct failure {
+	message: str
+}
+
+impl Error for failure {
+	&error() str {
+		ret .message
+	}
+}
+
+main() {}
+
+@test
+test_pass() {}
+
+@test
+test_fail() {
+	panic(failure{"boom"})
+}
+
+helper() {}
+`
+
+func TestTestCommand(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{
+		"main.jn":  testSource,
+		"other.jn": "@test\ntest_other() {}\n",
+	})
+	cases := []struct {
+		name string
+		args []string
+		code int
+		want []string
+		not  []string
+	}{
+		{
+			name: "all",
+			args: []string{"test"},
+			code: exitCompile,
+			want: []string{"--- PASS: test_pass", "--- PASS: test_other", "--- FAIL: test_fail: boom", "2 passed, 1 failed"},
+			not:  []string{"helper"},
+		},
+		{
+			name: "filter",
+			args: []string{"test", "--run", "pass$", "."},
+			code: exitSuccess,
+			want: []string{"--- PASS: test_pass", "1 passed, 0 failed"},
+			not:  []string{"test_fail", "test_other"},
+		},
+		{
+			name: "no match",
+			args: []string{"test", "--run", "nothing", "."},
+			code: exitSuccess,
+			want: []string{"no tests to run"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := jane(t, dir, c.args...)
+			if r.code != c.code {
+				t.Fatalf("exit code %d, want %d\nstdout:\n%s\nstderr:\n%s", r.code, c.code, r.stdout, r.stderr)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(r.stdout, want) {
+					t.Errorf("output does not contain %q:\n%s", want, r.stdout)
+				}
+			}
+			for _, not := range c.not {
+				if strings.Contains(r.stdout, not) {
+					t.Errorf("output contains %q:\n%s", not, r.stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestTestCommandErrors(t *testing.T) {
+	dir := project(t, map[string]string{"main.jn": testSource})
+	empty := t.TempDir()
+	cases := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"invalid pattern", []string{"test", "--run", "(", "."}, exitUsage, "invalid test pattern"},
+		{"no sources", []string{"test", empty}, exitIO, "no source file found"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := jane(t, dir, c.args...)
+			if r.code != c.code {
+				t.Fatalf("exit code %d, want %d\nstderr:\n%s", r.code, c.code, r.stderr)
+			}
+			if !strings.Contains(r.stderr, c.want) {
+				t.Errorf("stderr does not contain %q:\n%s", c.want, r.stderr)
+			}
+		})
+	}
+}
+
+func TestLocalization(t *testing.T) {
+	src := "main() {\n\tcount: = 1\n\tprintln(cuont)\n}\n"
+	cases := []struct {
+		language string
+		want     string
+	}{
+		{"", "identifier is not exist: cuont (did you mean count?)"},
+		{"english", "identifier is not exist: cuont (did you mean count?)"},
+		{"indonesia", "identifier tidak ada: cuont (mungkin maksud anda count?)"},
+	}
+	for _, c := range cases {
+		t.Run(c.language, func(t *testing.T) {
+			dir := project(t, map[string]string{
+				"main.jn":       src,
+				jn.SettingsFile: `{"language": "` + c.language + `"}`,
+			})
+			r := jane(t, dir, "build", "main.jn")
+			if r.code != exitCompile {
+				t.Fatalf("exit code %d, want %d\nstderr:\n%s", r.code, exitCompile, r.stderr)
+			}
+			if !strings.Contains(r.stderr, c.want) {
+				t.Errorf("diagnostics do not contain %q:\n%s", c.want, r.stderr)
+			}
+		})
+	}
+}
+
+// warningSource is program that has warning and prints "out".
+const warningSource = `main() {
+	match true {
+	case true:
+	}
+	println("out")
+}
+`
+
+// decodeDocument decodes s as single JSON document.
+func decodeDocument(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	dec := json.NewDecoder(strings.NewReader(s))
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("output is not JSON document: %v\n%s", err, s)
+	}
+	if dec.More() {
+		t.Fatalf("output has multiple documents:\n%s", s)
+	}
+	return doc
+}
+
+func TestDiagnosticsFormat(t *testing.T) {
+	needCompiler(t)
+	cases := []struct {
+		name   string
+		args   []string
+		stdout bool
+		code   int
+	}{
+		{"build json", []string{"build", "--diagnostics-format", "json", "--mode", "compile", "main.jn"}, true, exitSuccess},
+		{"build sarif", []string{"build", "--diagnostics-format", "sarif", "main.jn"}, true, exitSuccess},
+		{"run json", []string{"run", "--diagnostics-format", "json", "main.jn"}, false, exitSuccess},
+		{"run sarif", []string{"run", "--diagnostics-format", "sarif", "main.jn"}, false, exitSuccess},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := project(t, map[string]string{"main.jn": warningSource})
+			r := jane(t, dir, c.args...)
+			if r.code != c.code {
+				t.Fatalf("exit code %d, want %d\nstderr:\n%s", r.code, c.code, r.stderr)
+			}
+			out := r.stderr
+			if c.stdout {
+				out = r.stdout
+			} else if r.stdout != "out\n" {
+				t.Errorf("program output is mixed with diagnostics:\n%s", r.stdout)
+			}
+			doc := decodeDocument(t, out)
+			if c.args[2] == diagnosticsSARIF {
+				runs, _ := doc["runs"].([]any)
+				if len(runs) != 1 {
+					t.Fatalf("SARIF log has not single run:\n%s", out)
+				}
+				results, _ := runs[0].(map[string]any)["results"].([]any)
+				if len(results) != 1 {
+					t.Errorf("SARIF log has %d results, want 1:\n%s", len(results), out)
+				}
+				return
+			}
+			if doc["warnings"] != 1.0 || doc["errors"] != 0.0 {
+				t.Errorf("invalid counts of report:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsOutput(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{"main.jn": warningSource})
+	path := filepath.Join(dir, "diagnostics.json")
+	r := jane(t, dir, "run", "--diagnostics-format", "json", "--diagnostics-output", path, "main.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("exit code %d\nstderr:\n%s", r.code, r.stderr)
+	}
+	if r.stdout != "out\n" || r.stderr != "" {
+		t.Errorf("diagnostics are not written to file:\nstdout:\n%s\nstderr:\n%s", r.stdout, r.stderr)
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := decodeDocument(t, string(bytes))
+	if doc["warnings"] != 1.0 {
+		t.Errorf("invalid report:\n%s", bytes)
+	}
+}
+
+func TestDiagnosticsErrors(t *testing.T) {
+	dir := project(t, map[string]string{
+		"main.jn": "main() {\n\tprintln(x)\n\tprintln(y)\n}\n",
+	})
+	r := jane(t, dir, "run", "--diagnostics-format", "json", "main.jn")
+	if r.code != exitCompile {
+		t.Fatalf("exit code %d, want %d\nstderr:\n%s", r.code, exitCompile, r.stderr)
+	}
+	doc := decodeDocument(t, r.stderr)
+	if doc["errors"] != 2.0 {
+		t.Errorf("invalid report:\n%s", r.stderr)
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	const src = "main() {println(1)}\n"
+	dir := project(t, map[string]string{
+		"main.jn":       src,
+		jn.SettingsFile: `{"indent": " ", "indent_count": 2}`,
+	})
+	r := jane(t, dir, "fmt", "main.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("exit code %d: %s", r.code, r.stderr)
+	}
+	const want = "main() {\n  println(1)\n}\n"
+	if r.stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", r.stdout, want)
+	}
+	r = jane(t, dir, "fmt", "--check", "main.jn")
+	if r.code != exitCompile || strings.TrimSpace(r.stdout) != "main.jn" {
+		t.Errorf("check: exit code %d, stdout %q", r.code, r.stdout)
+	}
+	r = jane(t, dir, "fmt", "-w", "main.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("write: exit code %d: %s", r.code, r.stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.jn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("written:\n%s\nwant:\n%s", data, want)
+	}
+	r = jane(t, dir, "fmt", "--check", "main.jn")
+	if r.code != exitSuccess || r.stdout != "" {
+		t.Errorf("check after write: exit code %d, stdout %q", r.code, r.stdout)
+	}
+}
+
+func TestCompilerArgs(t *testing.T) {
+	set := jn.Set
+	defer func() { jn.Set = set }()
+	jn.Set = &jnset.JnSet{
+		CxxStandard:  "c++20",
+		Optimization: "2",
+		CxxFlags:     []string{"-Wall", "-g"},
+		IncludeDirs:  []string{"inc", "vendor"},
+		LinkLibs:     []string{"m", "pthread"},
+	}
+	got := strings.Join(compilerArgs("jn.cpp", "main"), " ")
+	const want = "-std=c++20 -O2 -Wall -g -Iinc -Ivendor jn.cpp -o main -lm -lpthread"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	jn.Set = &jnset.JnSet{}
+	if got := strings.Join(compilerArgs("jn.cpp", "main"), " "); got != "jn.cpp -o main" {
+		t.Errorf("empty settings: got %q", got)
+	}
+}
+
+const helloSource = `main() {
+	println("hello")
+}
+`
+
+func TestCompileMode(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{
+		"main.jn":       helloSource,
+		jn.SettingsFile: `{"mode": "compile", "out_name": "hello", "optimization": "1"}`,
+	})
+	r := jane(t, dir, "build", "main.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("exit code %d: %s", r.code, r.stderr)
+	}
+	out, err := exec.Command(filepath.Join(dir, "hello")).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "hello\n" {
+		t.Errorf("output of program: %q", out)
+	}
+	// C++ output is removed after compilation.
+	if _, err := os.Stat(filepath.Join(dir, "dist", "jn.cpp")); !os.IsNotExist(err) {
+		t.Errorf("C++ output is not removed: %v", err)
+	}
+}
+
+func TestCompileModeErrors(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{
+		"main.jn":       helloSource,
+		jn.SettingsFile: `{"mode": "compile"}`,
+	})
+	cases := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"compiler fails", []string{"--set", "cxx_flags=-no-such-flag"}, exitCompile, "C++ compilation failed"},
+		{"compiler not found", []string{"--set", "compiler=no-such-compiler"}, exitCompile, "C++ compilation failed"},
+		{"empty compiler", []string{"--set", "compiler= "}, exitSettings, "compiler"},
+		{"invalid optimization", []string{"--set", "optimization=9"}, exitSettings, "optimization"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := append([]string{"build"}, c.args...)
+			r := jane(t, dir, append(args, "main.jn")...)
+			if r.code != c.code {
+				t.Errorf("exit code %d, want %d: %s", r.code, c.code, r.stderr)
+			}
+			if !strings.Contains(r.stderr, c.want) {
+				t.Errorf("stderr does not contain %q:\n%s", c.want, r.stderr)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "main")); !os.IsNotExist(err) {
+				t.Errorf("binary is written: %v", err)
+			}
+		})
+	}
+}
+
+const echoSource = `use std::io::{readln}
+use std::os::*
+
+main() {
+	line: = readln()
+	println("read: " + line)
+	exit(3)
+}
+`
+
+func TestRunCommand(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{"main.jn": echoSource})
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(os.Args[0], "run", "main.jn", "--", "-x", "arg")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), mainEnv+"="+root, "NO_COLOR=1")
+	cmd.Stdin = strings.NewReader("input line\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	exit, ok := err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected exit error, got %v: %s", err, stderr.String())
+	}
+	if exit.ExitCode() != 3 {
+		t.Errorf("exit code %d, want exit code of program: %s", exit.ExitCode(), stderr.String())
+	}
+	if got := stdout.String(); got != "read: input line\n" {
+		t.Errorf("stdout: %q", got)
+	}
+	// Program is compiled in temporary directory.
+	if _, err := os.Stat(filepath.Join(dir, "dist")); !os.IsNotExist(err) {
+		t.Errorf("output directory is created in project: %v", err)
+	}
+}
+
+func TestRunCommandErrors(t *testing.T) {
+	needCompiler(t)
+	dir := project(t, map[string]string{
+		"main.jn": "main() {\n\tprintln(undefined)\n}\n",
+	})
+	r := jane(t, dir, "run", "main.jn")
+	if r.code != exitCompile {
+		t.Errorf("exit code %d, want %d", r.code, exitCompile)
+	}
+	if r.stdout != "" {
+		t.Errorf("program is run: %q", r.stdout)
+	}
+	r = jane(t, dir, "run")
+	if r.code != exitUsage {
+		t.Errorf("missing path: exit code %d, want %d", r.code, exitUsage)
+	}
+	r = jane(t, dir, "run", "a.jn", "b.jn")
+	if r.code != exitUsage {
+		t.Errorf("argument overflow: exit code %d, want %d", r.code, exitUsage)
+	}
+	dir = project(t, map[string]string{
+		"main.jn":       helloSource,
+		jn.SettingsFile: `{"compiler": "no-such-compiler"}`,
+	})
+	r = jane(t, dir, "run", "main.jn")
+	if r.code != exitCompile || !strings.Contains(r.stderr, "C++ compilation failed") {
+		t.Errorf("compiler of settings: exit code %d: %s", r.code, r.stderr)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cmd := newCommand("x", "", "", nil)
+	var o string
+	var b bool
+	cmd.flags.StringVar(&o, "o", "", "")
+	cmd.flags.BoolVar(&b, "b", false, "")
+	cases := []struct {
+		args       []string
+		positional []string
+		rest       []string
+		o          string
+		b          bool
+		err        bool
+	}{
+		{args: []string{"a.jn"}, positional: []string{"a.jn"}},
+		{args: []string{"-o", "out", "a.jn", "-b"}, positional: []string{"a.jn"}, o: "out", b: true},
+		{args: []string{"a.jn", "--o=out", "b.jn"}, positional: []string{"a.jn", "b.jn"}, o: "out"},
+		{args: []string{"my file.jn", "--", "-b", "x"}, positional: []string{"my file.jn"}, rest: []string{"-b", "x"}},
+		{args: []string{"--unknown", "a.jn"}, err: true},
+		{args: []string{"-o"}, err: true},
+	}
+	for _, c := range cases {
+		o, b = "", false
+		positional, rest, err := parseArgs(cmd, c.args)
+		if (err != nil) != c.err {
+			t.Errorf("%q: unexpected error: %v", c.args, err)
+			continue
+		}
+		if c.err {
+			continue
+		}
+		if strings.Join(positional, "|") != strings.Join(c.positional, "|") ||
+			strings.Join(rest, "|") != strings.Join(c.rest, "|") ||
+			o != c.o || b != c.b {
+			t.Errorf("%q: got positional %q, rest %q, o %q, b %v", c.args, positional, rest, o, b)
+		}
+	}
+}
+
+func TestBuildFlags(t *testing.T) {
+	dir := project(t, map[string]string{"my program.jn": helloSource})
+	r := jane(t, dir, "build", "--out-dir", "gen", "--set", "cxx_out_name=prog.cpp", "my program.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("exit code %d: %s", r.code, r.stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gen", "prog.cpp")); err != nil {
+		t.Errorf("overridden output is not written: %v", err)
+	}
+	// Command name is optional for build.
+	r = jane(t, dir, "my program.jn")
+	if r.code != exitSuccess {
+		t.Fatalf("without command: exit code %d: %s", r.code, r.stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dist", "jn.cpp")); err != nil {
+		t.Errorf("default output is not written: %v", err)
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	dir := project(t, map[string]string{"main.jn": helloSource})
+	cases := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"unknown flag", []string{"build", "--unknown", "main.jn"}, exitUsage, `run "jane help build" for usage`},
+		{"invalid override", []string{"build", "--set", "mode", "main.jn"}, exitUsage, "mode"},
+		{"undefined key", []string{"build", "--set", "no_such_key=1", "main.jn"}, exitSettings, "no_such_key"},
+		{"invalid value", []string{"build", "--set", "indent_count=x", "main.jn"}, exitSettings, "indent_count"},
+		{"invalid mode", []string{"build", "--mode", "interpret", "main.jn"}, exitSettings, "interpret"},
+		{"undefined command", []string{"help", "nothing"}, exitUsage, "nothing"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := jane(t, dir, c.args...)
+			if r.code != c.code {
+				t.Errorf("exit code %d, want %d: %s", r.code, c.code, r.stderr)
+			}
+			if !strings.Contains(r.stderr, c.want) {
+				t.Errorf("stderr does not contain %q:\n%s", c.want, r.stderr)
+			}
+		})
+	}
+}
+
+func TestHelp(t *testing.T) {
+	dir := project(t, map[string]string{})
+	r := jane(t, dir, "help")
+	for _, cmd := range commands {
+		if !strings.Contains(r.stderr, cmd.name) {
+			t.Errorf("help does not list %s:\n%s", cmd.name, r.stderr)
+		}
+	}
+	for _, args := range [][]string{{"help", "build"}, {"build", "--help"}} {
+		r = jane(t, dir, args...)
+		if r.code != exitSuccess {
+			t.Errorf("%q: exit code %d", args, r.code)
+		}
+		for _, want := range []string{"usage: jane build", "-o <value>", "--out-dir <value>", "--werror", "--set <value>"} {
+			if !strings.Contains(r.stderr, want) {
+				t.Errorf("%q: help does not contain %q:\n%s", args, want, r.stderr)
+			}
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	cases := map[int]string{0: "0 errors", 1: "1 error", 2: "2 errors"}
+	for n, want := range cases {
+		if got := plural(n, "error"); got != want {
+			t.Errorf("plural(%d): got %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	const errorSource = "main() {\n\tprintln(undefined)\n}\n"
+	cases := []struct {
+		name    string
+		files   map[string]string
+		args    []string
+		code    int
+		summary string
+	}{
+		{
+			name:  "success",
+			files: map[string]string{"main.jn": helloSource},
+			args:  []string{"build", "main.jn"},
+			code:  exitSuccess,
+		},
+		{
+			name:    "compile error",
+			files:   map[string]string{"main.jn": errorSource},
+			args:    []string{"build", "main.jn"},
+			code:    exitCompile,
+			summary: "1 error, 0 warnings",
+		},
+		{
+			name:    "warnings",
+			files:   map[string]string{"main.jn": warningSource},
+			args:    []string{"build", "main.jn"},
+			code:    exitSuccess,
+			summary: "0 errors, 1 warning",
+		},
+		{
+			name:    "werror flag",
+			files:   map[string]string{"main.jn": warningSource},
+			args:    []string{"build", "--werror", "main.jn"},
+			code:    exitCompile,
+			summary: "0 errors, 1 warning",
+		},
+		{
+			name: "werror setting",
+			files: map[string]string{
+				"main.jn":       warningSource,
+				jn.SettingsFile: `{"werror": true}`,
+			},
+			args:    []string{"build", "main.jn"},
+			code:    exitCompile,
+			summary: "0 errors, 1 warning",
+		},
+		{
+			name: "invalid settings",
+			files: map[string]string{
+				"main.jn":       helloSource,
+				jn.SettingsFile: `{"mode": `,
+			},
+			args: []string{"build", "main.jn"},
+			code: exitSettings,
+		},
+		{
+			name:  "missing source",
+			files: map[string]string{},
+			args:  []string{"build", "missing.jn"},
+			code:  exitIO,
+		},
+		{
+			name: "unwritable output",
+			files: map[string]string{
+				"main.jn": helloSource,
+				"dist":    "not a directory",
+			},
+			args: []string{"build", "main.jn"},
+			code: exitIO,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := jane(t, project(t, c.files), c.args...)
+			if r.code != c.code {
+				t.Errorf("exit code %d, want %d: %s", r.code, c.code, r.stderr)
+			}
+			lines := strings.Split(strings.TrimSpace(r.stderr), "\n")
+			last := lines[len(lines)-1]
+			if c.summary != "" && last != c.summary {
+				t.Errorf("summary: got %q, want %q", last, c.summary)
+			}
+		})
+	}
+
+	// Settings file is required by build.
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.jn"), []byte(helloSource), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := jane(t, dir, "build", "main.jn"); r.code != exitSettings {
+		t.Errorf("missing settings: exit code %d, want %d", r.code, exitSettings)
+	}
+}
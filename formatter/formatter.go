@@ -0,0 +1,386 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package formatter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast"
+	"github.com/DeRuneLabs/jane/lexer"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+type Tok = lexer.Tok
+
+type line struct {
+	toks  []Tok
+	blank bool
+}
+
+func endRow(tok Tok) int {
+	return tok.Row + strings.Count(tok.Kind, "\n")
+}
+
+func splitLines(toks []Tok) []*line {
+	var lines []*line
+	end := 0
+	for _, tok := range toks {
+		if len(lines) == 0 || tok.Row > end {
+			lines = append(lines, &line{blank: len(lines) > 0 && tok.Row > end+1})
+		}
+		ln := lines[len(lines)-1]
+		ln.toks = append(ln.toks, tok)
+		end = endRow(tok)
+	}
+	return lines
+}
+
+func isOpen(tok Tok) bool {
+	if tok.Id != tokens.Brace {
+		return false
+	}
+	switch tok.Kind {
+	case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
+		return true
+	}
+	return false
+}
+
+func isClose(tok Tok) bool {
+	return tok.Id == tokens.Brace && !isOpen(tok)
+}
+
+func isHeader(toks []Tok, depth int) bool {
+	if len(toks) > 0 && toks[0].Id == tokens.Pub {
+		toks = toks[1:]
+	}
+	if len(toks) == 0 || toks[len(toks)-1].Id == tokens.Comment {
+		return false
+	}
+	switch toks[0].Id {
+	case tokens.Struct, tokens.Enum, tokens.Trait, tokens.Impl,
+		tokens.If, tokens.For, tokens.Match:
+		return true
+	case tokens.Id:
+		return depth == 0 && len(toks) > 1 && toks[1].Kind == tokens.LPARENTHESES
+	}
+	return false
+}
+
+func isLBrace(tok Tok) bool {
+	return tok.Id == tokens.Brace && tok.Kind == tokens.LBRACE
+}
+
+func braceDepth(toks []Tok) (depth int) {
+	for _, tok := range toks {
+		if tok.Id != tokens.Brace {
+			continue
+		}
+		switch tok.Kind {
+		case tokens.LBRACE:
+			depth++
+		case tokens.RBRACE:
+			depth--
+		}
+	}
+	return
+}
+
+// joinBraces moves block braces to end of header lines.
+func joinBraces(lines []*line) []*line {
+	var joined []*line
+	depth := 0
+	for _, ln := range lines {
+		n := len(joined)
+		if n > 0 && isLBrace(ln.toks[0]) && isHeader(joined[n-1].toks, depth) {
+			joined[n-1].toks = append(joined[n-1].toks, ln.toks...)
+		} else {
+			joined = append(joined, ln)
+		}
+		depth += braceDepth(ln.toks)
+	}
+	return joined
+}
+
+func isRBrace(tok Tok) bool {
+	return tok.Id == tokens.Brace && tok.Kind == tokens.RBRACE
+}
+
+// isBlockBrace reports whether tok is brace of block.
+func isBlockBrace(tok Tok, r roles) bool {
+	return (isLBrace(tok) || isRBrace(tok)) && r.of(tok) != compositeBrace
+}
+
+// splitBlocks moves contents of one line blocks to their own lines.
+// Lines are split after left braces of non-empty blocks, before right
+// braces of blocks and before cases of matches. Comments stay at end of
+// line of left brace. Statements that separated with semicolons are
+// kept as written.
+func splitBlocks(lines []*line, r roles) []*line {
+	var split []*line
+	for _, ln := range lines {
+		cur := &line{blank: ln.blank}
+		for _, tok := range ln.toks {
+			if len(cur.toks) > 0 {
+				prev := cur.toks[len(cur.toks)-1]
+				brk := false
+				switch {
+				case isLBrace(prev) && isBlockBrace(prev, r):
+					brk = tok.Id != tokens.Comment && !isRBrace(tok)
+				case isRBrace(tok) && isBlockBrace(tok, r):
+					brk = true
+				case isCase(tok):
+					brk = true
+				}
+				if brk {
+					split = append(split, cur)
+					cur = &line{}
+				}
+			}
+			cur.toks = append(cur.toks, tok)
+		}
+		split = append(split, cur)
+	}
+	return split
+}
+
+// sortUses sorts runs of top level use declarations.
+func sortUses(lines []*line) {
+	depth := 0
+	for i := 0; i < len(lines); i++ {
+		if depth != 0 || lines[i].toks[0].Id != tokens.Use {
+			depth += braceDepth(lines[i].toks)
+			continue
+		}
+		j := i + 1
+		for j < len(lines) && !lines[j].blank && lines[j].toks[0].Id == tokens.Use {
+			j++
+		}
+		run := lines[i:j]
+		blank := run[0].blank
+		sort.SliceStable(run, func(a, b int) bool {
+			return lineString(run[a].toks, nil) < lineString(run[b].toks, nil)
+		})
+		for _, ln := range run {
+			ln.blank = false
+		}
+		run[0].blank = blank
+		i = j - 1
+	}
+}
+
+// isOperand reports whether tok can end an operand.
+func isOperand(tok Tok) bool {
+	switch tok.Id {
+	case tokens.Id, tokens.Value, tokens.DataType, tokens.Self, tokens.Cpp:
+		return true
+	}
+	return isClose(tok)
+}
+
+func isKeyword(tok Tok) bool {
+	switch tok.Id {
+	case tokens.Ret, tokens.Const, tokens.Type, tokens.For, tokens.Break,
+		tokens.Continue, tokens.In, tokens.If, tokens.Else, tokens.Use,
+		tokens.Pub, tokens.Defer, tokens.Goto, tokens.Enum, tokens.Struct,
+		tokens.Match, tokens.Case, tokens.Default, tokens.Trait, tokens.Impl,
+		tokens.Cpp, tokens.Fallthrough:
+		return true
+	}
+	return false
+}
+
+func isBinaryOp(tok Tok) bool {
+	if tok.Id != tokens.Operator {
+		return false
+	}
+	switch tok.Kind {
+	case tokens.EQUAL, tokens.EQUALS, tokens.NOT_EQUALS, tokens.LESS_EQUAL,
+		tokens.GREAT_EQUAL, tokens.AND, tokens.OR, tokens.PLUS_EQUAL,
+		tokens.MINUS_EQUAL, tokens.STAR_EQUAL, tokens.SLASH_EQUAL,
+		tokens.PERCENT_EQUAL, tokens.LSHIFT_EQUAL, tokens.RSHIFT_EQUAL,
+		tokens.CARET_EQUAL, tokens.AMPER_EQUAL, tokens.VLINE_EQUAL:
+		return true
+	}
+	return false
+}
+
+// hasSpace reports whether source has whitespace between tokens.
+func hasSpace(prev, tok Tok) bool {
+	return tok.Row > endRow(prev) || tok.Column > prev.Column+len(prev.Kind)
+}
+
+// space reports whether tokens are separated with space.
+// inBracket reports whether tokens are inside of brackets.
+func space(prev, tok Tok, inBracket bool, r roles) bool {
+	switch {
+	case tok.Id == tokens.Comment:
+		return true
+	case isOpen(prev) && !isLBrace(prev):
+		return false
+	case isClose(tok) && tok.Kind != tokens.RBRACE:
+		return false
+	case tok.Id == tokens.Comma, tok.Id == tokens.SemiColon:
+		return false
+	case prev.Id == tokens.Comma, prev.Id == tokens.SemiColon:
+		return true
+	case prev.Id == tokens.Dot, prev.Id == tokens.DoubleColon,
+		tok.Id == tokens.DoubleColon:
+		return false
+	case tok.Id == tokens.Dot:
+		return !isOperand(prev) && hasSpace(prev, tok)
+	case prev.Id == tokens.At, prev.Id == tokens.Preprocessor:
+		return false
+	case tok.Id == tokens.Colon:
+		return false
+	case prev.Id == tokens.Colon:
+		return !inBracket
+	case r.of(prev) == unaryOp:
+		return false
+	case isBinaryOp(prev), isBinaryOp(tok),
+		r.of(prev) == binaryOp, r.of(tok) == binaryOp:
+		return true
+	case prev.Id == tokens.Operator && prev.Kind == tokens.EXCLAMATION:
+		return false
+	case isLBrace(tok):
+		return r.of(tok) != compositeBrace
+	case tok.Id == tokens.Else:
+		return true
+	case tok.Id == tokens.Operator &&
+		(tok.Kind == tokens.DOUBLE_PLUS || tok.Kind == tokens.DOUBLE_MINUS):
+		return false
+	case isKeyword(prev):
+		return true
+	}
+	return hasSpace(prev, tok)
+}
+
+func tokString(tok Tok) string {
+	if tok.Id == tokens.Comment {
+		return strings.TrimRight(tok.Kind, " \t\r")
+	}
+	return tok.Kind
+}
+
+func lineString(toks []Tok, r roles) string {
+	var sb strings.Builder
+	brackets := 0
+	for i, tok := range toks {
+		if i > 0 && space(toks[i-1], tok, brackets > 0, r) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tokString(tok))
+		if tok.Id == tokens.Brace {
+			switch tok.Kind {
+			case tokens.LBRACKET:
+				brackets++
+			case tokens.RBRACKET:
+				brackets--
+			}
+		}
+	}
+	return sb.String()
+}
+
+func levelOf(opens []int) int {
+	level := 0
+	for i, ln := range opens {
+		if i == 0 || opens[i-1] != ln {
+			level++
+		}
+	}
+	return level
+}
+
+func isCase(tok Tok) bool {
+	return tok.Id == tokens.Case || tok.Id == tokens.Default
+}
+
+func render(lines []*line, r roles, indent string) string {
+	var sb strings.Builder
+	var opens []int
+	// depths of opens that has case bodies
+	var cases []int
+	for i, ln := range lines {
+		j := 0
+		for ; j < len(ln.toks) && isClose(ln.toks[j]); j++ {
+			if len(opens) > 0 {
+				opens = opens[:len(opens)-1]
+			}
+		}
+		for len(cases) > 0 && cases[len(cases)-1] > len(opens) {
+			cases = cases[:len(cases)-1]
+		}
+		isCaseLine := j < len(ln.toks) && isCase(ln.toks[j])
+		if isCaseLine && len(cases) > 0 && cases[len(cases)-1] == len(opens) {
+			cases = cases[:len(cases)-1]
+		}
+		if i > 0 && ln.blank && j == 0 {
+			prev := lines[i-1].toks
+			if !isOpen(prev[len(prev)-1]) {
+				sb.WriteByte('\n')
+			}
+		}
+		sb.WriteString(strings.Repeat(indent, levelOf(opens)+len(cases)))
+		sb.WriteString(lineString(ln.toks, r))
+		sb.WriteByte('\n')
+		if isCaseLine {
+			cases = append(cases, len(opens))
+		}
+		for _, tok := range ln.toks[j:] {
+			switch {
+			case isOpen(tok):
+				opens = append(opens, i)
+			case isClose(tok) && len(opens) > 0:
+				opens = opens[:len(opens)-1]
+			}
+		}
+	}
+	return sb.String()
+}
+
+// Format returns source code of f in canonical style.
+// Operators and braces are spaced by their roles in AST.
+// Each block level is indented with indent.
+// Returns logs instead if source code has syntax errors.
+func Format(f *jnio.File, indent string) (string, []jnlog.CompilerLog) {
+	lex := lexer.NewLex(f)
+	toks := lex.Lex()
+	if len(lex.Logs) > 0 {
+		return "", lex.Logs
+	}
+	b := ast.NewBuilder(toks)
+	b.Build()
+	if len(b.Errors) > 0 {
+		return "", b.Errors
+	}
+	lex = lexer.NewLex(f)
+	lex.KeepComments = true
+	lines := splitLines(lex.Lex())
+	lines = joinBraces(lines)
+	r := rolesOf(b.Tree)
+	lines = splitBlocks(lines, r)
+	sortUses(lines)
+	return render(lines, r, indent), nil
+}
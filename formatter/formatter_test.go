@@ -0,0 +1,92 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package formatter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/package/jnio"
+)
+
+func format(t *testing.T, name, src, indent string) string {
+	t.Helper()
+	f := &jnio.File{Name: name, Data: []rune(src)}
+	out, logs := Format(f, indent)
+	if len(logs) > 0 {
+		t.Fatalf("%s: unexpected logs: %v", name, logs)
+	}
+	return out
+}
+
+// TestFormatGolden formats testdata/*.jn and compares results with
+// testdata/*.golden files. Golden files must be stable under formatting.
+func TestFormatGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.jn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no test sources")
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		t.Run(strings.TrimSuffix(name, ".jn"), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden, err := os.ReadFile(strings.TrimSuffix(path, ".jn") + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := format(t, name, string(src), "\t")
+			if out != string(golden) {
+				t.Errorf("output differs from golden file:\n%s", out)
+			}
+			again := format(t, name, out, "\t")
+			if again != out {
+				t.Errorf("formatting is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatIndent(t *testing.T) {
+	const src = "main() {if true {println(1)}}\n"
+	const want = "main() {\n  if true {\n    println(1)\n  }\n}\n"
+	if got := format(t, "main.jn", src, "  "); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	f := &jnio.File{Name: "main.jn", Data: []rune("main() {\n")}
+	out, logs := Format(f, "\t")
+	if len(logs) == 0 {
+		t.Fatal("expected logs for syntax error")
+	}
+	if out != "" {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package formatter
+
+import (
+	"reflect"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+)
+
+// role is syntactic role of token in expression trees.
+type role uint8
+
+const (
+	_ role = iota
+	binaryOp
+	unaryOp
+	// compositeBrace is brace of composite literal body.
+	// Other braces are begins and ends of blocks.
+	compositeBrace
+)
+
+type pos struct {
+	row    int
+	column int
+}
+
+func posOf(tok Tok) pos {
+	return pos{row: tok.Row, column: tok.Column}
+}
+
+// roles are roles of tokens by their positions.
+type roles map[pos]role
+
+func (r roles) of(tok Tok) role {
+	return r[posOf(tok)]
+}
+
+var (
+	tokType  = reflect.TypeOf(Tok{})
+	nodeType = reflect.TypeOf((*models.ExprNode)(nil)).Elem()
+)
+
+// rolesOf returns roles of tokens in expression trees of AST.
+func rolesOf(tree []models.Object) roles {
+	r := roles{}
+	c := collector{r: r, seen: map[uintptr]bool{}}
+	c.collect(reflect.ValueOf(tree))
+	return r
+}
+
+// collector traverses AST to collect roles of tokens.
+// AST is traversed with reflection, so expression trees
+// in any definition or statement are collected.
+type collector struct {
+	r    roles
+	seen map[uintptr]bool
+}
+
+func (c *collector) node(n models.ExprNode) {
+	switch t := n.(type) {
+	case *models.BinaryExpr:
+		c.r[posOf(t.Op)] = binaryOp
+	case *models.UnaryExpr:
+		c.r[posOf(t.Op)] = unaryOp
+	case *models.CompositeExpr:
+		c.r[posOf(t.Body[0])] = compositeBrace
+		c.r[posOf(t.Body[len(t.Body)-1])] = compositeBrace
+	}
+}
+
+func (c *collector) collect(v reflect.Value) {
+	if v.Type() == tokType {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || c.seen[v.Pointer()] {
+			return
+		}
+		c.seen[v.Pointer()] = true
+		if v.Type().Implements(nodeType) {
+			c.node(v.Interface().(models.ExprNode))
+		}
+		c.collect(v.Elem())
+	case reflect.Interface:
+		if !v.IsNil() {
+			c.collect(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				c.collect(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem() == tokType {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			c.collect(v.Index(i))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ir
+
+import "github.com/DeRuneLabs/jane/ast/models"
+
+// dce is state of dead-code elimination.
+type dce struct {
+	funcs   map[*models.Func]*Func
+	globals map[*models.Var]*VarDecl
+	reached map[any]bool
+}
+
+// EliminateDeadCode removes functions, methods and global variables of pkg
+// that are not reachable from entry point, initializers, tests and
+// exported definitions. Methods that implement traits are kept with
+// their structures, because they are reachable by trait calls.
+func EliminateDeadCode(pkg *Package) {
+	d := &dce{
+		funcs:   map[*models.Func]*Func{},
+		globals: map[*models.Var]*VarDecl{},
+		reached: map[any]bool{},
+	}
+	for _, f := range pkg.Funcs {
+		d.funcs[f.Ast] = f
+	}
+	for _, s := range pkg.Structs {
+		for _, f := range s.Funcs {
+			d.funcs[f.Ast] = f
+		}
+	}
+	for _, g := range pkg.Globals {
+		d.globals[g.Var] = g
+	}
+	for _, f := range pkg.Funcs {
+		if f.Entry || f.Export {
+			d.reachFunc(f.Ast)
+		}
+	}
+	for _, f := range pkg.Inits {
+		d.reachFunc(f.Ast)
+	}
+	for _, f := range pkg.Tests {
+		d.reachFunc(f.Ast)
+	}
+	for _, g := range pkg.Globals {
+		if g.Export {
+			d.reachGlobal(g.Var)
+		}
+	}
+	for _, s := range pkg.Structs {
+		for _, f := range s.Funcs {
+			if implementsTrait(s, f) {
+				d.reachFunc(f.Ast)
+			}
+		}
+	}
+	pkg.Funcs = reachedFuncs(d, pkg.Funcs)
+	for _, s := range pkg.Structs {
+		s.Funcs = reachedFuncs(d, s.Funcs)
+	}
+	globals := pkg.Globals[:0]
+	for _, g := range pkg.Globals {
+		if d.reached[g.Var] {
+			globals = append(globals, g)
+		}
+	}
+	pkg.Globals = globals
+}
+
+// implementsTrait reports f is implementation of trait function of s.
+func implementsTrait(s *Struct, f *Func) bool {
+	for _, t := range s.Traits {
+		for _, id := range t.Funcs {
+			if id == f.Ast.Id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func reachedFuncs(d *dce, funcs []*Func) []*Func {
+	reached := funcs[:0]
+	for _, f := range funcs {
+		if d.reached[f.Ast] {
+			reached = append(reached, f)
+		}
+	}
+	return reached
+}
+
+func (d *dce) reachFunc(ast *models.Func) {
+	if d.reached[ast] {
+		return
+	}
+	d.reached[ast] = true
+	if f := d.funcs[ast]; f != nil {
+		d.inspect(f.Body)
+	}
+}
+
+func (d *dce) reachGlobal(v *models.Var) {
+	if d.reached[v] {
+		return
+	}
+	d.reached[v] = true
+	if g := d.globals[v]; g != nil {
+		d.inspect(g.Init)
+	}
+}
+
+func (d *dce) inspect(node any) {
+	Inspect(node, func(node any) bool {
+		switch t := node.(type) {
+		case *FuncRef:
+			d.reachFunc(t.Func)
+		case *Method:
+			d.reachFunc(t.Func)
+		case *VarRef:
+			d.reachGlobal(t.Var)
+		}
+		return true
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ir
+
+import (
+	"testing"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+)
+
+// Builtin traits have functions only in definitions,
+// so their AST is not have any function.
+func TestEliminateDeadCodeKeepsBuiltinTraitImpl(t *testing.T) {
+	errorTrait := &Trait{
+		Ast:   &models.Trait{Id: "Error"},
+		Funcs: []string{"error"},
+	}
+	impl := &Func{Ast: &models.Func{Id: "error"}, Body: &Block{}}
+	unused := &Func{Ast: &models.Func{Id: "unused"}, Body: &Block{}}
+	s := &Struct{
+		Ast:    &models.Struct{Id: "MyErr"},
+		Traits: []*Trait{errorTrait},
+		Funcs:  []*Func{impl, unused},
+	}
+	pkg := &Package{
+		Traits:  []*Trait{errorTrait},
+		Structs: []*Struct{s},
+		Funcs:   []*Func{{Ast: &models.Func{Id: "main"}, Entry: true, Body: &Block{}}},
+	}
+	EliminateDeadCode(pkg)
+	if len(s.Funcs) != 1 || s.Funcs[0] != impl {
+		t.Fatalf("expected only trait implementation to be kept, got %d methods", len(s.Funcs))
+	}
+}
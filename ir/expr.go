@@ -0,0 +1,261 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ir
+
+import "github.com/DeRuneLabs/jane/ast/models"
+
+// Expr is typed expression.
+type Expr interface {
+	// DataType returns resolved data-type of expression.
+	DataType() Type
+}
+
+// Const is constant value.
+// Value is int64, uint64, float64, bool, string or nil.
+// Strings are keeps escape sequences of source.
+// Char reports character literals.
+type Const struct {
+	Type  Type
+	Value any
+	Char  bool
+}
+
+// VarRef is variable.
+type VarRef struct {
+	Type Type
+	Var  *models.Var
+}
+
+// SelfRef is receiver of method.
+type SelfRef struct {
+	Type Type
+}
+
+// FuncRef is function.
+// Entry reports entry point of program.
+type FuncRef struct {
+	Type  Type
+	Func  *models.Func
+	Entry bool
+}
+
+// LinkRef is linked C++ function.
+type LinkRef struct {
+	Type Type
+	Func *models.Func
+}
+
+// EnumRef is enum type.
+type EnumRef struct {
+	Type Type
+	Enum *models.Enum
+}
+
+// StructRef is structure type.
+type StructRef struct {
+	Type Type
+}
+
+// EnumItemRef is item of enum.
+type EnumItemRef struct {
+	Type Type
+	Enum Expr
+	Item *models.EnumItem
+}
+
+// Binary is binary operation.
+type Binary struct {
+	Type Type
+	Op   string
+	X    Expr
+	Y    Expr
+}
+
+// Unary is unary operation, such as "-x", "^x", "!x" or "*x".
+type Unary struct {
+	Type Type
+	Op   string
+	X    Expr
+}
+
+// AddrOf is pointer of expression.
+// Heap reports expression must be allocated on heap.
+type AddrOf struct {
+	Type Type
+	X    Expr
+	Heap bool
+}
+
+// Paren is parenthesized expression.
+type Paren struct {
+	Type Type
+	X    Expr
+}
+
+// Cast is type casting.
+type Cast struct {
+	Type Type
+	X    Expr
+}
+
+// Conv is built-in conversion to string.
+type Conv struct {
+	Type Type
+	X    Expr
+}
+
+// Call is function call.
+// TupleArgs reports multiple return values of Args[0] are arguments.
+type Call struct {
+	Type      Type
+	Fn        Expr
+	Generics  []Type
+	Args      []Expr
+	TupleArgs bool
+}
+
+// Field is field of structure or built-in field of type.
+type Field struct {
+	Type Type
+	X    Expr
+	Var  *models.Var
+}
+
+// Method is method of structure, trait or built-in type.
+type Method struct {
+	Type Type
+	X    Expr
+	Func *models.Func
+}
+
+// TraitData is structure that implements trait.
+type TraitData struct {
+	Type Type
+	X    Expr
+}
+
+// Index is indexing.
+type Index struct {
+	Type  Type
+	X     Expr
+	Index Expr
+}
+
+// Slice is slicing.
+// Low and High are nil if they are not given.
+type Slice struct {
+	Type Type
+	X    Expr
+	Low  Expr
+	High Expr
+}
+
+// Composite is array or slice literal.
+type Composite struct {
+	Type  Type
+	Elems []Expr
+}
+
+// MapLit is map literal.
+type MapLit struct {
+	Type   Type
+	Keys   []Expr
+	Values []Expr
+}
+
+// StructLit is structure construction.
+// Args are in order of fields.
+type StructLit struct {
+	Type Type
+	Args []Expr
+}
+
+// Tuple is multiple values, such as multiple return values.
+type Tuple struct {
+	Type   Type
+	Values []Expr
+}
+
+// Closure is anonymous function.
+// Captures are variables visible to function.
+type Closure struct {
+	Type     Type
+	Func     *models.Func
+	Captures []*models.Var
+	Body     *Block
+}
+
+// Default is default value of type.
+// Type is zero for defaults of parameters.
+type Default struct {
+	Type Type
+}
+
+// MustHeap is expression that escapes to heap.
+type MustHeap struct {
+	Type Type
+	X    Expr
+}
+
+// Offsetof is byte offset of field in struct.
+type Offsetof struct {
+	Type   Type
+	Struct Type
+	Field  *models.Var
+}
+
+// StaticAssert is assertion that checked by C++ compiler.
+// Msg is content of string literal.
+type StaticAssert struct {
+	Type Type
+	Cond Expr
+	Msg  string
+}
+
+func (e *Const) DataType() Type        { return e.Type }
+func (e *VarRef) DataType() Type       { return e.Type }
+func (e *SelfRef) DataType() Type      { return e.Type }
+func (e *FuncRef) DataType() Type      { return e.Type }
+func (e *LinkRef) DataType() Type      { return e.Type }
+func (e *EnumRef) DataType() Type      { return e.Type }
+func (e *StructRef) DataType() Type    { return e.Type }
+func (e *EnumItemRef) DataType() Type  { return e.Type }
+func (e *Binary) DataType() Type       { return e.Type }
+func (e *Unary) DataType() Type        { return e.Type }
+func (e *AddrOf) DataType() Type       { return e.Type }
+func (e *Paren) DataType() Type        { return e.Type }
+func (e *Cast) DataType() Type         { return e.Type }
+func (e *Conv) DataType() Type         { return e.Type }
+func (e *Call) DataType() Type         { return e.Type }
+func (e *Field) DataType() Type        { return e.Type }
+func (e *Method) DataType() Type       { return e.Type }
+func (e *TraitData) DataType() Type    { return e.Type }
+func (e *Index) DataType() Type        { return e.Type }
+func (e *Slice) DataType() Type        { return e.Type }
+func (e *Composite) DataType() Type    { return e.Type }
+func (e *MapLit) DataType() Type       { return e.Type }
+func (e *StructLit) DataType() Type    { return e.Type }
+func (e *Tuple) DataType() Type        { return e.Type }
+func (e *Closure) DataType() Type      { return e.Type }
+func (e *Default) DataType() Type      { return e.Type }
+func (e *MustHeap) DataType() Type     { return e.Type }
+func (e *Offsetof) DataType() Type     { return e.Type }
+func (e *StaticAssert) DataType() Type { return e.Type }
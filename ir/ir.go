@@ -0,0 +1,92 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ir implements typed intermediate representation of Jane programs.
+//
+// IR is produced by parser after checking and consumed by backends.
+// Expressions are carries their resolved data-types and symbols,
+// so consumers are not need to know anything about checking.
+package ir
+
+import "github.com/DeRuneLabs/jane/ast/models"
+
+// Type is resolved data-type.
+type Type = models.DataType
+
+// Package is checked program ready for backends.
+// Declarations of used packages are comes before main package.
+type Package struct {
+	Links   []string
+	Types   []*TypeAlias
+	Enums   []*Enum
+	Traits  []*Trait
+	Structs []*Struct
+	Globals []*VarDecl
+	Funcs   []*Func
+	Inits   []*Func
+	Tests   []*Func
+	IsTest  bool
+}
+
+// Func is function declaration.
+// Body is nil for prototypes.
+// Export reports function is public definition of compiled package.
+type Func struct {
+	Ast    *models.Func
+	Entry  bool
+	Export bool
+	Body   *Block
+}
+
+// TypeAlias is type alias declaration.
+type TypeAlias struct {
+	Pos
+	Ast *models.Type
+}
+
+// Enum is enum declaration.
+// Items are in declaration order with their values.
+type Enum struct {
+	Ast   *models.Enum
+	Items []*EnumItem
+}
+
+// EnumItem is item of enum.
+type EnumItem struct {
+	Ast   *models.EnumItem
+	Value Expr
+}
+
+// Trait is trait declaration.
+// Funcs are identifiers of trait functions.
+type Trait struct {
+	Ast   *models.Trait
+	Funcs []string
+}
+
+// Struct is structure declaration.
+// Fields are checked fields, Funcs are used methods.
+type Struct struct {
+	Ast         *models.Struct
+	Constructor *models.Func
+	Fields      []*models.Var
+	Traits      []*Trait
+	Funcs       []*Func
+}
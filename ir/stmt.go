@@ -0,0 +1,239 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ir
+
+import "github.com/DeRuneLabs/jane/ast/models"
+
+// Stmt is statement.
+type Stmt interface {
+	stmt()
+	Position() *Pos
+}
+
+// Pos is source position of statement.
+// Tok has nil file if statement has no source position.
+type Pos struct {
+	Tok models.Tok
+}
+
+// Position returns source position.
+func (p *Pos) Position() *Pos { return p }
+
+// Block is block of statements.
+type Block struct {
+	Pos
+	Stmts []Stmt
+}
+
+// ExprStmt is expression statement.
+type ExprStmt struct {
+	Pos
+	X Expr
+}
+
+// VarDecl is variable declaration.
+// Init is nil if variable is initialized with default value.
+// Export reports global variable is public definition of compiled package.
+type VarDecl struct {
+	Pos
+	Var    *models.Var
+	Init   Expr
+	Export bool
+}
+
+// Assign is single assignment with operator, such as "=" or "+=".
+// Left is nil if left side is ignored.
+type Assign struct {
+	Pos
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// IncDec is increment or decrement, such as "x++".
+type IncDec struct {
+	Pos
+	Op string
+	X  Expr
+}
+
+// TupleAssign is multiple assignment.
+// Decls are new variables of assignment.
+// Nil elements of Left are ignored values.
+// Right has single element if it is multiple return values.
+type TupleAssign struct {
+	Pos
+	Op    string
+	Decls []*VarDecl
+	Left  []Expr
+	Right []Expr
+}
+
+// If is conditional statement.
+// Else is nil, *If or *Block.
+type If struct {
+	Pos
+	Cond Expr
+	Then *Block
+	Else Stmt
+}
+
+// Loop is infinite iteration.
+type Loop struct {
+	Pos
+	Body *Block
+}
+
+// While is iteration with condition.
+type While struct {
+	Pos
+	Cond Expr
+	Body *Block
+}
+
+// For is iteration with initializer, condition and next statement.
+// Init, Cond and Post are nil if they are not given.
+type For struct {
+	Pos
+	Init Stmt
+	Cond Expr
+	Post Stmt
+	Body *Block
+}
+
+// Foreach is iteration over elements of X.
+// Key and Value are have ignore identifiers if they are not used.
+type Foreach struct {
+	Pos
+	Key   *models.Var
+	Value *models.Var
+	X     Expr
+	Type  Type
+	Body  *Block
+}
+
+// Match is match-case statement.
+// X is nil for boolean matches.
+type Match struct {
+	Pos
+	X       Expr
+	Type    Type
+	Cases   []*Case
+	Default *Case
+}
+
+// Case is case of match.
+// Exprs is empty for default case.
+type Case struct {
+	Pos
+	Exprs []Expr
+	Body  *Block
+	Match *Match
+	Next  *Case
+}
+
+// Fallthrough is jump to next case.
+type Fallthrough struct {
+	Pos
+	Case *Case
+}
+
+// Break is break of iteration or match.
+// Match is nil for iterations.
+type Break struct {
+	Pos
+	Match *Match
+}
+
+// Continue is continue of iteration.
+type Continue struct {
+	Pos
+}
+
+// Goto is jump to label.
+type Goto struct {
+	Pos
+	Label string
+}
+
+// Label is label of goto.
+type Label struct {
+	Pos
+	Label string
+}
+
+// Ret is return statement.
+// X is nil for void returns.
+type Ret struct {
+	Pos
+	X Expr
+}
+
+// Defer is deferred call.
+type Defer struct {
+	Pos
+	Call Expr
+}
+
+// Co is concurrent call.
+type Co struct {
+	Pos
+	Call Expr
+}
+
+// Try is block protected by recover.
+// Handler is called with Param if error is raised.
+type Try struct {
+	Pos
+	Body    *Block
+	Handler Expr
+	Param   *models.Param
+}
+
+// Comment is comment line.
+type Comment struct {
+	Pos
+	Text string
+}
+
+func (*Block) stmt()       {}
+func (*ExprStmt) stmt()    {}
+func (*VarDecl) stmt()     {}
+func (*Assign) stmt()      {}
+func (*IncDec) stmt()      {}
+func (*TupleAssign) stmt() {}
+func (*If) stmt()          {}
+func (*Loop) stmt()        {}
+func (*While) stmt()       {}
+func (*For) stmt()         {}
+func (*Foreach) stmt()     {}
+func (*Match) stmt()       {}
+func (*Fallthrough) stmt() {}
+func (*Break) stmt()       {}
+func (*Continue) stmt()    {}
+func (*Goto) stmt()        {}
+func (*Label) stmt()       {}
+func (*Ret) stmt()         {}
+func (*Defer) stmt()       {}
+func (*Co) stmt()          {}
+func (*Try) stmt()         {}
+func (*Comment) stmt()     {}
+func (*TypeAlias) stmt()   {}
@@ -0,0 +1,153 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ir
+
+// Inspect traverses node and its children in depth-first order.
+// Node is statement, expression, *Block or *Case.
+// Children of node are not traversed if f returns false.
+func Inspect(node any, f func(node any) bool) {
+	if b, ok := node.(*Block); node == nil || (ok && b == nil) || !f(node) {
+		return
+	}
+	switch t := node.(type) {
+	case *Block:
+		for _, s := range t.Stmts {
+			Inspect(s, f)
+		}
+	case *ExprStmt:
+		inspectExpr(t.X, f)
+	case *VarDecl:
+		inspectExpr(t.Init, f)
+	case *Assign:
+		inspectExpr(t.Left, f)
+		inspectExpr(t.Right, f)
+	case *IncDec:
+		inspectExpr(t.X, f)
+	case *TupleAssign:
+		for _, d := range t.Decls {
+			Inspect(d, f)
+		}
+		inspectExprs(t.Left, f)
+		inspectExprs(t.Right, f)
+	case *If:
+		inspectExpr(t.Cond, f)
+		Inspect(t.Then, f)
+		if t.Else != nil {
+			Inspect(t.Else, f)
+		}
+	case *Loop:
+		Inspect(t.Body, f)
+	case *While:
+		inspectExpr(t.Cond, f)
+		Inspect(t.Body, f)
+	case *For:
+		if t.Init != nil {
+			Inspect(t.Init, f)
+		}
+		inspectExpr(t.Cond, f)
+		if t.Post != nil {
+			Inspect(t.Post, f)
+		}
+		Inspect(t.Body, f)
+	case *Foreach:
+		inspectExpr(t.X, f)
+		Inspect(t.Body, f)
+	case *Match:
+		inspectExpr(t.X, f)
+		for _, c := range t.Cases {
+			Inspect(c, f)
+		}
+		if t.Default != nil {
+			Inspect(t.Default, f)
+		}
+	case *Case:
+		inspectExprs(t.Exprs, f)
+		Inspect(t.Body, f)
+	case *Ret:
+		inspectExpr(t.X, f)
+	case *Defer:
+		inspectExpr(t.Call, f)
+	case *Co:
+		inspectExpr(t.Call, f)
+	case *Try:
+		Inspect(t.Body, f)
+		inspectExpr(t.Handler, f)
+	case *EnumItemRef:
+		inspectExpr(t.Enum, f)
+	case *Binary:
+		inspectExpr(t.X, f)
+		inspectExpr(t.Y, f)
+	case *Unary:
+		inspectExpr(t.X, f)
+	case *AddrOf:
+		inspectExpr(t.X, f)
+	case *Paren:
+		inspectExpr(t.X, f)
+	case *Cast:
+		inspectExpr(t.X, f)
+	case *Conv:
+		inspectExpr(t.X, f)
+	case *Call:
+		inspectExpr(t.Fn, f)
+		inspectExprs(t.Args, f)
+	case *Field:
+		inspectExpr(t.X, f)
+	case *Method:
+		inspectExpr(t.X, f)
+	case *TraitData:
+		inspectExpr(t.X, f)
+	case *Index:
+		inspectExpr(t.X, f)
+		inspectExpr(t.Index, f)
+	case *Slice:
+		inspectExpr(t.X, f)
+		inspectExpr(t.Low, f)
+		inspectExpr(t.High, f)
+	case *Composite:
+		inspectExprs(t.Elems, f)
+	case *MapLit:
+		inspectExprs(t.Keys, f)
+		inspectExprs(t.Values, f)
+	case *StructLit:
+		inspectExprs(t.Args, f)
+	case *Tuple:
+		inspectExprs(t.Values, f)
+	case *Closure:
+		Inspect(t.Body, f)
+	case *MustHeap:
+		inspectExpr(t.X, f)
+	case *StaticAssert:
+		inspectExpr(t.Cond, f)
+	}
+}
+
+// inspectExpr inspects x if it is not nil.
+func inspectExpr(x Expr, f func(node any) bool) {
+	if x != nil {
+		Inspect(x, f)
+	}
+}
+
+func inspectExprs(xs []Expr, f func(node any) bool) {
+	for _, x := range xs {
+		inspectExpr(x, f)
+	}
+}
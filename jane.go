@@ -0,0 +1,132 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package jane is embeddable compiler API of Jane.
+//
+// Compilations are isolated from each other,
+// so several compilations can run in one process and in parallel.
+package jane
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/DeRuneLabs/jane/backend/cpp"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+	"github.com/DeRuneLabs/jane/package/jnset"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+// Options is options of compilation.
+type Options struct {
+	// FS is file system of sources and standard library.
+	// jnio.OS can be used for file system of operating system.
+	FS fs.FS
+	// Path is path of main source file in FS.
+	Path string
+	// StdlibPath is path of standard library directory in FS.
+	StdlibPath string
+	// Header is path of API header (jnc.hpp) that included by C++ output.
+	Header string
+	// Set is settings of compilation, default settings are used if nil.
+	Set *jnset.JnSet
+	// Diagnostic is called for each error and warning if not nil.
+	Diagnostic func(log jnlog.CompilerLog)
+}
+
+// Result is result of compilation.
+type Result struct {
+	// Cpp is C++ output, empty if compilation is failed.
+	Cpp string
+	// SourceMap is source map of Cpp, nil if it is not enabled by settings.
+	SourceMap *cpp.SourceMap
+	Errors    []jnlog.CompilerLog
+	Warnings  []jnlog.CompilerLog
+}
+
+// Failed reports compilation is failed.
+func (r *Result) Failed() bool {
+	return r.Cpp == ""
+}
+
+// Compile compiles main source file of options.
+// Returned error is not nil only if main source file is not readable,
+// compile errors are reported with result.
+func Compile(opts Options) (*Result, error) {
+	set := opts.Set
+	if set == nil {
+		set = jnset.Default
+	}
+	p := parser.New(nil)
+	p.Env = parser.NewEnv(opts.FS, opts.StdlibPath, set)
+	r := new(Result)
+	defer r.report(opts.Diagnostic)
+	if !jnio.IsUseable(opts.Path) {
+		p.PushErr("file_not_useable")
+		r.Errors = p.Errors
+		return r, nil
+	}
+	info, err := fs.Stat(opts.FS, filepath.Clean(opts.StdlibPath))
+	if err != nil || !info.IsDir() {
+		p.PushErr("no_stdlib")
+		r.Errors = p.Errors
+		return r, nil
+	}
+	f, err := jnio.ReadJn(opts.FS, filepath.Clean(opts.Path))
+	if err != nil {
+		return nil, err
+	}
+	p.File = f
+	p.Parsef(true, false)
+	r.Errors = p.Errors
+	r.Warnings = p.Warnings
+	if len(r.Errors) > 0 || (set.Werror && len(r.Warnings) > 0) {
+		return r, nil
+	}
+	pkg := p.IR()
+	if set.Dce {
+		ir.EliminateDeadCode(pkg)
+	}
+	lines := set.LineDirectives || set.SourceMap
+	r.Cpp = jnapi.Standard(opts.Header) + cpp.Generate(pkg, set.Indent, set.IndentCount, lines)
+	if lines {
+		var sm *cpp.SourceMap
+		r.Cpp, sm = cpp.MapLines(r.Cpp, set.CppOutName, !set.LineDirectives)
+		if set.SourceMap {
+			r.SourceMap = sm
+		}
+	}
+	return r, nil
+}
+
+func (r *Result) report(diagnostic func(jnlog.CompilerLog)) {
+	if diagnostic == nil {
+		return
+	}
+	for _, log := range r.Errors {
+		diagnostic(log)
+	}
+	for _, log := range r.Warnings {
+		diagnostic(log)
+	}
+}
@@ -45,6 +45,10 @@ type Lex struct {
 	Row    int
 	Logs   []jnlog.CompilerLog
 
+	// KeepComments reports all comments as tokens,
+	// including range and end of line comments.
+	KeepComments bool
+
 	braces []Tok
 }
 
@@ -62,7 +66,10 @@ func (l *Lex) pusherr(key string, args ...any) {
 		Type:    jnlog.Error,
 		Row:     l.Row,
 		Column:  l.Column,
+		Length:  1,
 		Path:    l.File.Path(),
+		Line:    l.File.Line(l.Row),
+		Key:     key,
 		Message: jn.GetError(key, args...),
 	})
 }
@@ -72,7 +79,10 @@ func (l *Lex) pusherrtok(tok Tok, err string) {
 		Type:    jnlog.Error,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  utf8.RuneCountInString(tok.Kind),
 		Path:    l.File.Path(),
+		Line:    l.File.Line(tok.Row),
+		Key:     err,
 		Message: jn.GetError(err),
 	})
 }
@@ -171,20 +181,21 @@ func (l *Lex) lncomment(tok *Tok) {
 	l.Pos += 2
 	for ; l.Pos < len(l.File.Data); l.Pos++ {
 		if l.File.Data[l.Pos] == '\n' {
-			if l.firstTokOfLine {
+			if l.firstTokOfLine || l.KeepComments {
 				tok.Id = tokens.Comment
 				tok.Kind = string(l.File.Data[start:l.Pos])
 			}
 			return
 		}
 	}
-	if l.firstTokOfLine {
+	if l.firstTokOfLine || l.KeepComments {
 		tok.Id = tokens.Comment
 		tok.Kind = string(l.File.Data[start:])
 	}
 }
 
-func (l *Lex) rangecomment() {
+func (l *Lex) rangecomment(tok *Tok) {
+	start := l.Pos
 	l.Pos += 2
 	for ; l.Pos < len(l.File.Data); l.Pos++ {
 		run := l.File.Data[l.Pos]
@@ -196,6 +207,10 @@ func (l *Lex) rangecomment() {
 		if strings.HasPrefix(string(l.File.Data[l.Pos:]), tokens.RANGE_COMMENT_CLOSE) {
 			l.Column += 2
 			l.Pos += 2
+			if l.KeepComments {
+				tok.Id = tokens.Comment
+				tok.Kind = string(l.File.Data[start:l.Pos])
+			}
 			return
 		}
 	}
@@ -480,7 +495,7 @@ func (l *Lex) Tok() Tok {
 		l.lncomment(&tok)
 		return tok
 	case strings.HasPrefix(txt, tokens.RANGE_COMMENT_OPEN):
-		l.rangecomment()
+		l.rangecomment(&tok)
 		return tok
 	case l.isop(txt, tokens.LPARENTHESES, tokens.Brace, &tok):
 		l.braces = append(l.braces, tok)
@@ -0,0 +1,450 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package lsp
+
+import (
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DeRuneLabs/jane/ast"
+	"github.com/DeRuneLabs/jane/lexer"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+type Tok = lexer.Tok
+
+// document is an in-memory source buffer of client.
+type document struct {
+	uri  string
+	file *jnio.File
+	toks []Tok
+	// parser of latest buffer that has no syntax errors.
+	// queries are runs on it while buffer is not parseable.
+	parser *parser.Parser
+	// related are paths of files that are not open
+	// and have diagnostics of latest analysis.
+	related []string
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToUri(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+func newDocument(uri, text string) *document {
+	f := &jnio.File{Data: []rune(text)}
+	f.Dir, f.Name = filepath.Split(uriToPath(uri))
+	return &document{uri: uri, file: f}
+}
+
+// analyze parses document and returns logs.
+// Sources of package and uses are read from fsys.
+func (d *document) analyze(fsys fs.FS) []jnlog.CompilerLog {
+	lex := lexer.NewLex(d.file)
+	d.toks = lex.Lex()
+	if len(lex.Logs) > 0 {
+		return lex.Logs
+	}
+	env := parser.NewEnv(fsys, jn.StdlibPath, jn.Set)
+	b := ast.NewBuilder(d.toks)
+	b.StdlibPath = env.StdlibPath
+	b.Build()
+	if len(b.Errors) > 0 {
+		return b.Errors
+	}
+	p := parser.New(d.file)
+	p.Env = env
+	p.Parset(b.Tree, true, false)
+	d.parser = p
+	return append(p.Errors, p.Warnings...)
+}
+
+// width returns lexer column width of rune.
+func width(r rune) int {
+	if r == '\t' {
+		return 4
+	}
+	return utf8.RuneLen(r)
+}
+
+// units16 returns UTF-16 code unit count of rune.
+func units16(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// lexColumn returns lexer column of UTF-16 character offset of line.
+func lexColumn(line string, char int) int {
+	col, units := 1, 0
+	for _, r := range line {
+		if units >= char {
+			break
+		}
+		units += units16(r)
+		col += width(r)
+	}
+	return col
+}
+
+// utf16Char returns UTF-16 character offset of lexer column of line.
+// length is rune count after column.
+func utf16Char(line string, col, length int) int {
+	c, units := 1, 0
+	for _, r := range line {
+		if c >= col {
+			if length == 0 {
+				break
+			}
+			length--
+		}
+		c += width(r)
+		units += units16(r)
+	}
+	return units
+}
+
+func rangeOf(line string, row, col, length int) textRange {
+	return textRange{
+		Start: position{Line: row - 1, Character: utf16Char(line, col, 0)},
+		End:   position{Line: row - 1, Character: utf16Char(line, col, length)},
+	}
+}
+
+func tokRange(tok Tok) textRange {
+	line := ""
+	if tok.File != nil {
+		line = tok.File.Line(tok.Row)
+	}
+	return rangeOf(line, tok.Row, tok.Column, utf8.RuneCountInString(tok.Kind))
+}
+
+// diagnostics returns diagnostics of logs of same file.
+// Logs without position are reported at first line.
+func diagnostics(logs []jnlog.CompilerLog) []diagnostic {
+	diags := []diagnostic{}
+	for _, log := range logs {
+		if log.Row == 0 {
+			log.Row, log.Column = 1, 1
+		}
+		diag := diagnostic{
+			Range:    rangeOf(log.Line, log.Row, log.Column, log.Length),
+			Severity: 1,
+			Code:     log.Key,
+			Source:   "jane",
+			Message:  log.Message,
+		}
+		if log.Type == jnlog.Warning {
+			diag.Severity = 2
+		}
+		for _, note := range log.Notes {
+			diag.Message += "\n" + note
+		}
+		for _, r := range log.Related {
+			diag.RelatedInformation = append(diag.RelatedInformation, diagnosticRelated{
+				Location: location{
+					Uri:   pathToUri(r.Path),
+					Range: rangeOf(r.Line, r.Row, r.Column, r.Length),
+				},
+				Message: r.Label,
+			})
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// at returns token of position for queries of parser.
+func (d *document) at(pos position) Tok {
+	tok := Tok{Row: pos.Line + 1}
+	tok.Column = lexColumn(d.file.Line(tok.Row), pos.Character)
+	if d.parser != nil {
+		tok.File = d.parser.File
+	}
+	return tok
+}
+
+// tokenAt returns index of identifier token at position, -1 if not exist.
+func (d *document) tokenAt(pos position) int {
+	at := d.at(pos)
+	for i, tok := range d.toks {
+		if tok.Row != at.Row || tok.Column > at.Column {
+			continue
+		}
+		if at.Column > tok.Column+len(tok.Kind) {
+			continue
+		}
+		switch tok.Id {
+		case tokens.Id, tokens.Self:
+			return i
+		}
+	}
+	return -1
+}
+
+// tokenBefore returns index of latest token that starts before position.
+func (d *document) tokenBefore(pos position) int {
+	at := d.at(pos)
+	i := -1
+	for j, tok := range d.toks {
+		if tok.Row > at.Row || (tok.Row == at.Row && tok.Column >= at.Column) {
+			break
+		}
+		i = j
+	}
+	return i
+}
+
+// namespacePath returns identifiers of namespace chain that ends with
+// double colon token at i.
+func (d *document) namespacePath(i int) []string {
+	var path []string
+	for i > 0 && d.toks[i].Id == tokens.DoubleColon && d.toks[i-1].Id == tokens.Id {
+		path = append([]string{d.toks[i-1].Kind}, path...)
+		i -= 2
+	}
+	return path
+}
+
+// isUse reports token at i is in use declaration.
+func (d *document) isUse(i int) bool {
+	row := d.toks[i].Row
+	for ; i >= 0 && d.toks[i].Row == row; i-- {
+		if d.toks[i].Id == tokens.Use {
+			return true
+		}
+	}
+	return false
+}
+
+func find(syms []*parser.Symbol, id string) *parser.Symbol {
+	for _, sym := range syms {
+		if sym.Id == id {
+			return sym
+		}
+	}
+	return nil
+}
+
+// symbolAt returns symbol of identifier at position.
+func (d *document) symbolAt(pos position) (*parser.Symbol, Tok) {
+	i := d.tokenAt(pos)
+	if i == -1 || d.parser == nil {
+		return nil, Tok{}
+	}
+	tok := d.toks[i]
+	// cursor token is after identifier for find itself as declaration.
+	at := d.at(pos)
+	at.Column = tok.Column + 1
+	if i > 1 {
+		switch d.toks[i-1].Id {
+		case tokens.Dot:
+			return find(d.parser.Members(d.toks[i-2].Kind, at), tok.Kind), tok
+		case tokens.DoubleColon:
+			return find(d.parser.NamespaceSymbols(d.namespacePath(i-1)), tok.Kind), tok
+		}
+	}
+	return d.parser.Lookup(tok.Kind, at), tok
+}
+
+func hoverText(sym *parser.Symbol) string {
+	var detail string
+	switch sym.Kind {
+	case parser.SymbolVar, parser.SymbolField:
+		detail = sym.Id + ": " + sym.Detail
+	case parser.SymbolConst:
+		detail = "const " + sym.Id + ": " + sym.Detail
+	default:
+		detail = sym.Detail
+	}
+	var text strings.Builder
+	text.WriteString("```jane\n")
+	text.WriteString(detail)
+	text.WriteString("\n```")
+	if sym.Doc != "" {
+		text.WriteString("\n\n")
+		text.WriteString(strings.TrimSpace(sym.Doc))
+	}
+	return text.String()
+}
+
+func (d *document) hover(pos position) *hover {
+	sym, tok := d.symbolAt(pos)
+	if sym == nil {
+		return nil
+	}
+	r := tokRange(tok)
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: hoverText(sym)},
+		Range:    &r,
+	}
+}
+
+func (d *document) definition(pos position) *location {
+	sym, _ := d.symbolAt(pos)
+	if sym == nil || sym.Tok.File == nil {
+		return nil
+	}
+	uri := pathToUri(sym.Tok.File.Path())
+	if sym.Tok.File == d.parser.File {
+		uri = d.uri
+	}
+	return &location{Uri: uri, Range: tokRange(sym.Tok)}
+}
+
+var symbolKinds = [...]int{
+	parser.SymbolFunc:      symbolKindFunction,
+	parser.SymbolMethod:    symbolKindMethod,
+	parser.SymbolVar:       symbolKindVariable,
+	parser.SymbolConst:     symbolKindConstant,
+	parser.SymbolField:     symbolKindField,
+	parser.SymbolStruct:    symbolKindStruct,
+	parser.SymbolTrait:     symbolKindInterface,
+	parser.SymbolEnum:      symbolKindEnum,
+	parser.SymbolEnumItem:  symbolKindEnumMember,
+	parser.SymbolType:      symbolKindTypeParam,
+	parser.SymbolNamespace: symbolKindNamespace,
+}
+
+var completionKinds = [...]int{
+	parser.SymbolFunc:      completionKindFunction,
+	parser.SymbolMethod:    completionKindMethod,
+	parser.SymbolVar:       completionKindVariable,
+	parser.SymbolConst:     completionKindConstant,
+	parser.SymbolField:     completionKindField,
+	parser.SymbolStruct:    completionKindStruct,
+	parser.SymbolTrait:     completionKindInterface,
+	parser.SymbolEnum:      completionKindEnum,
+	parser.SymbolEnumItem:  completionKindEnumMember,
+	parser.SymbolType:      completionKindTypeParam,
+	parser.SymbolNamespace: completionKindModule,
+}
+
+func documentSymbols(syms []*parser.Symbol) []documentSymbol {
+	docsyms := []documentSymbol{}
+	for _, sym := range syms {
+		r := tokRange(sym.Tok)
+		docsyms = append(docsyms, documentSymbol{
+			Name:           sym.Id,
+			Detail:         sym.Detail,
+			Kind:           symbolKinds[sym.Kind],
+			Range:          r,
+			SelectionRange: r,
+			Children:       documentSymbols(sym.Children),
+		})
+	}
+	return docsyms
+}
+
+func (d *document) symbols() []documentSymbol {
+	if d.parser == nil {
+		return []documentSymbol{}
+	}
+	return documentSymbols(d.parser.Symbols())
+}
+
+func completionItems(syms []*parser.Symbol) []completionItem {
+	items := []completionItem{}
+	added := map[string]bool{}
+	for _, sym := range syms {
+		if added[sym.Id] {
+			continue
+		}
+		added[sym.Id] = true
+		item := completionItem{
+			Label:  sym.Id,
+			Kind:   completionKinds[sym.Kind],
+			Detail: sym.Detail,
+		}
+		if sym.Doc != "" {
+			item.Documentation = &markupContent{Kind: "markdown", Value: strings.TrimSpace(sym.Doc)}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// packageItems returns next segments of std packages for path.
+func packageItems(env *parser.Env, path []string) []completionItem {
+	items := []completionItem{}
+	added := map[string]bool{}
+	prefix := strings.Join(path, "::")
+	for _, pkg := range env.StdPackages() {
+		var seg string
+		switch {
+		case prefix == "":
+			seg, _, _ = strings.Cut(pkg, "::")
+		case strings.HasPrefix(pkg, prefix+"::"):
+			seg, _, _ = strings.Cut(pkg[len(prefix)+2:], "::")
+		default:
+			continue
+		}
+		if !added[seg] {
+			added[seg] = true
+			items = append(items, completionItem{Label: seg, Kind: completionKindModule})
+		}
+	}
+	return items
+}
+
+func (d *document) completion(pos position) []completionItem {
+	i := d.tokenBefore(pos)
+	at := d.at(pos)
+	if i != -1 && d.toks[i].Id == tokens.Id && d.toks[i].Column+len(d.toks[i].Kind) >= at.Column {
+		// identifier that completing
+		i--
+	}
+	if i != -1 && d.isUse(i) {
+		env := parser.DefaultEnv()
+		if d.toks[i].Id == tokens.DoubleColon {
+			return packageItems(env, d.namespacePath(i))
+		}
+		return packageItems(env, nil)
+	}
+	if d.parser == nil {
+		return []completionItem{}
+	}
+	if i > 0 {
+		switch d.toks[i].Id {
+		case tokens.Dot:
+			return completionItems(d.parser.Members(d.toks[i-1].Kind, at))
+		case tokens.DoubleColon:
+			return completionItems(d.parser.NamespaceSymbols(d.namespacePath(i)))
+		}
+	}
+	return completionItems(d.parser.Scope(at))
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package lsp
+
+import (
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// overlay is file system that serves open documents over base,
+// so analysis uses unsaved buffers of client instead of disk.
+type overlay struct {
+	base fs.FS
+	// docs are texts of documents by their clean paths.
+	docs map[string][]byte
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	name = filepath.Clean(name)
+	if data, ok := o.docs[name]; ok {
+		info := bufferInfo{name: filepath.Base(name), size: len(data)}
+		return &buffer{info: info, Reader: bytes.NewReader(data)}, nil
+	}
+	return o.base.Open(name)
+}
+
+// ReadDir returns entries of base directory with open documents of it.
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	name = filepath.Clean(name)
+	entries, err := fs.ReadDir(o.base, name)
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		seen[entry.Name()] = true
+	}
+	for path, data := range o.docs {
+		base := filepath.Base(path)
+		if filepath.Dir(path) != name || seen[base] {
+			continue
+		}
+		err = nil
+		info := bufferInfo{name: base, size: len(data)}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// buffer is file of open document.
+type buffer struct {
+	info bufferInfo
+	*bytes.Reader
+}
+
+func (b *buffer) Stat() (fs.FileInfo, error) { return b.info, nil }
+
+func (b *buffer) Close() error { return nil }
+
+type bufferInfo struct {
+	name string
+	size int
+}
+
+func (i bufferInfo) Name() string       { return i.name }
+func (i bufferInfo) Size() int64        { return int64(i.size) }
+func (i bufferInfo) Mode() fs.FileMode  { return 0o444 }
+func (i bufferInfo) ModTime() time.Time { return time.Time{} }
+func (i bufferInfo) IsDir() bool        { return false }
+func (i bufferInfo) Sys() any           { return nil }
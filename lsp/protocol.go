@@ -0,0 +1,163 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package lsp
+
+import "encoding/json"
+
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type message struct {
+	Jsonrpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	Uri   string    `json:"uri"`
+	Range textRange `json:"range"`
+}
+
+type textDocumentIdentifier struct {
+	Uri string `json:"uri"`
+}
+
+type textDocumentItem struct {
+	Uri     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type positionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type diagnosticRelated struct {
+	Location location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+type diagnostic struct {
+	Range              textRange           `json:"range"`
+	Severity           int                 `json:"severity"`
+	Code               string              `json:"code,omitempty"`
+	Source             string              `json:"source"`
+	Message            string              `json:"message"`
+	RelatedInformation []diagnosticRelated `json:"relatedInformation,omitempty"`
+}
+
+type publishDiagnosticsParams struct {
+	Uri         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    *textRange    `json:"range,omitempty"`
+}
+
+type documentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          textRange        `json:"range"`
+	SelectionRange textRange        `json:"selectionRange"`
+	Children       []documentSymbol `json:"children,omitempty"`
+}
+
+type completionItem struct {
+	Label         string         `json:"label"`
+	Kind          int            `json:"kind,omitempty"`
+	Detail        string         `json:"detail,omitempty"`
+	Documentation *markupContent `json:"documentation,omitempty"`
+}
+
+// LSP symbol kinds.
+const (
+	symbolKindNamespace  = 3
+	symbolKindMethod     = 6
+	symbolKindField      = 8
+	symbolKindEnum       = 10
+	symbolKindInterface  = 11
+	symbolKindFunction   = 12
+	symbolKindVariable   = 13
+	symbolKindConstant   = 14
+	symbolKindEnumMember = 22
+	symbolKindStruct     = 23
+	symbolKindTypeParam  = 26
+)
+
+// LSP completion item kinds.
+const (
+	completionKindMethod     = 2
+	completionKindFunction   = 3
+	completionKindField      = 5
+	completionKindVariable   = 6
+	completionKindInterface  = 8
+	completionKindModule     = 9
+	completionKindEnum       = 13
+	completionKindEnumMember = 20
+	completionKindConstant   = 21
+	completionKindStruct     = 22
+	completionKindTypeParam  = 25
+)
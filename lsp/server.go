@@ -0,0 +1,364 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package lsp implements language server protocol for Jane.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+// Server is a language server that communicates over JSON-RPC.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	outMutex sync.Mutex
+	// Log is logger of server, logs are discarded if nil.
+	Log      *log.Logger
+	docs     map[string]*document
+	shutdown bool
+}
+
+// NewServer returns new server that reads requests from in
+// and writes responses to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: map[string]*document{},
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Log != nil {
+		s.Log.Printf(format, args...)
+	}
+}
+
+func (s *Server) read() (*message, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(s.in, data); err != nil {
+		return nil, err
+	}
+	msg := new(message)
+	if err := json.Unmarshal(data, msg); err != nil {
+		s.logf("%v", err)
+		s.write(&message{Error: &responseError{Code: codeParseError, Message: err.Error()}})
+		return nil, nil
+	}
+	return msg, nil
+}
+
+func (s *Server) write(msg *message) {
+	msg.Jsonrpc = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.logf("%v", err)
+		return
+	}
+	s.outMutex.Lock()
+	defer s.outMutex.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(data))
+	s.out.Write(data)
+}
+
+func (s *Server) notify(method string, params any) {
+	data, _ := json.Marshal(params)
+	s.write(&message{Method: method, Params: data})
+}
+
+// Run serves requests until exit notification or end of input.
+// Returns error if input is ended without shutdown request.
+func (s *Server) Run() error {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) && s.shutdown {
+				return nil
+			}
+			return err
+		}
+		if msg == nil {
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		s.handle(msg)
+	}
+}
+
+func (s *Server) handle(msg *message) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logf("%s: panic: %v", msg.Method, r)
+			if msg.Id != nil {
+				s.write(&message{Id: msg.Id, Error: &responseError{
+					Code:    codeInvalidParams,
+					Message: fmt.Sprint(r),
+				}})
+			}
+		}
+	}()
+	result, err := s.dispatch(msg)
+	if msg.Id == nil {
+		return
+	}
+	if err != nil {
+		s.write(&message{Id: msg.Id, Error: err})
+		return
+	}
+	if result == nil {
+		// null result must be written
+		result = json.RawMessage("null")
+	}
+	s.write(&message{Id: msg.Id, Result: result})
+}
+
+func decode(msg *message, v any) *responseError {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) dispatch(msg *message) (any, *responseError) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(msg, &params); err != nil {
+			return nil, err
+		}
+		s.open(params.TextDocument.Uri, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(msg, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.open(params.TextDocument.Uri, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decode(msg, &params); err != nil {
+			return nil, err
+		}
+		s.close(params.TextDocument.Uri)
+		return nil, nil
+	case "textDocument/hover",
+		"textDocument/definition",
+		"textDocument/documentSymbol",
+		"textDocument/completion":
+		var params positionParams
+		if err := decode(msg, &params); err != nil {
+			return nil, err
+		}
+		d := s.docs[params.TextDocument.Uri]
+		if d == nil {
+			return nil, nil
+		}
+		return s.query(msg.Method, d, params.Position), nil
+	}
+	if strings.HasPrefix(msg.Method, "$/") {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+func (s *Server) query(method string, d *document, pos position) any {
+	switch method {
+	case "textDocument/hover":
+		if h := d.hover(pos); h != nil {
+			return h
+		}
+	case "textDocument/definition":
+		if loc := d.definition(pos); loc != nil {
+			return loc
+		}
+	case "textDocument/documentSymbol":
+		return d.symbols()
+	case "textDocument/completion":
+		return d.completion(pos)
+	}
+	return nil
+}
+
+func (s *Server) initialize() any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":       1, // full
+			"hoverProvider":          true,
+			"definitionProvider":     true,
+			"documentSymbolProvider": true,
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{".", ":"},
+			},
+		},
+		"serverInfo": map[string]any{"name": "jane"},
+	}
+}
+
+// open analyzes text of document and publishes diagnostics.
+// Other open documents of package are analyzed again,
+// because they are depends on text of document.
+func (s *Server) open(uri, text string) {
+	d := newDocument(uri, text)
+	if old := s.docs[uri]; old != nil {
+		d.parser = old.parser
+		d.related = old.related
+	}
+	s.docs[uri] = d
+	s.analyze(d)
+	s.analyzePackage(d.file.Dir, uri)
+}
+
+// close forgets document and clears diagnostics of it.
+// Other open documents of package are analyzed with file on disk.
+func (s *Server) close(uri string) {
+	d := s.docs[uri]
+	if d == nil {
+		return
+	}
+	delete(s.docs, uri)
+	s.publish(uri, nil)
+	for _, path := range d.related {
+		if !s.isOpen(path) {
+			s.publish(pathToUri(path), nil)
+		}
+	}
+	s.analyzePackage(d.file.Dir, "")
+}
+
+// analyzePackage analyzes open documents of directory except uri.
+func (s *Server) analyzePackage(dir, uri string) {
+	var uris []string
+	for other, d := range s.docs {
+		if other != uri && d.file.Dir == dir {
+			uris = append(uris, other)
+		}
+	}
+	sort.Strings(uris)
+	for _, other := range uris {
+		s.analyze(s.docs[other])
+	}
+}
+
+// fs returns file system of analysis that serves open documents.
+func (s *Server) fs() fs.FS {
+	docs := make(map[string][]byte, len(s.docs))
+	for _, d := range s.docs {
+		docs[filepath.Clean(d.file.Path())] = []byte(string(d.file.Data))
+	}
+	return overlay{base: jnio.OS, docs: docs}
+}
+
+// isOpen reports whether file of path is open document.
+func (s *Server) isOpen(path string) bool {
+	for _, d := range s.docs {
+		if d.file.Path() == path {
+			return true
+		}
+	}
+	return false
+}
+
+// analyze analyzes document and publishes diagnostics of every file
+// that has logs. Open documents publish their diagnostics by their
+// analysis, so only logs of files that are not open are published
+// for other files.
+func (s *Server) analyze(d *document) {
+	var logs []jnlog.CompilerLog
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logf("%s: analysis panic: %v", d.uri, r)
+			}
+		}()
+		logs = d.analyze(s.fs())
+	}()
+	path := d.file.Path()
+	files := map[string][]jnlog.CompilerLog{}
+	for _, log := range logs {
+		// Logs without path are about document, such as reading package.
+		if log.Path == "" {
+			log.Path = path
+		}
+		files[log.Path] = append(files[log.Path], log)
+	}
+	s.publish(d.uri, files[path])
+	var paths []string
+	for other := range files {
+		if other != path && !s.isOpen(other) {
+			paths = append(paths, other)
+		}
+	}
+	sort.Strings(paths)
+	for _, other := range paths {
+		s.publish(pathToUri(other), files[other])
+	}
+	for _, other := range d.related {
+		if files[other] == nil && !s.isOpen(other) {
+			s.publish(pathToUri(other), nil)
+		}
+	}
+	d.related = paths
+}
+
+func (s *Server) publish(uri string, logs []jnlog.CompilerLog) {
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		Uri:         uri,
+		Diagnostics: diagnostics(logs),
+	})
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnset"
+)
+
+// client is test client of server that runs in background.
+type client struct {
+	t    *testing.T
+	in   *io.PipeWriter
+	out  chan *message
+	done chan error
+	id   int
+	// notifications are received notifications by method.
+	notifications map[string][]json.RawMessage
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	stdlib, err := filepath.Abs(filepath.Join("..", jn.Stdlib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jn.StdlibPath = stdlib
+	jn.Set = jnset.Default
+	inr, inw := io.Pipe()
+	outr, outw := io.Pipe()
+	c := &client{
+		t:             t,
+		in:            inw,
+		out:           make(chan *message, 64),
+		done:          make(chan error, 1),
+		notifications: map[string][]json.RawMessage{},
+	}
+	s := NewServer(inr, outw)
+	go func() {
+		c.done <- s.Run()
+		outw.Close()
+	}()
+	go readMessages(bufio.NewReader(outr), c.out)
+	t.Cleanup(func() {
+		inw.Close()
+		outr.Close()
+	})
+	return c
+}
+
+func (c *client) write(msg map[string]any) {
+	c.t.Helper()
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+// readMessages reads messages of server until output is closed.
+func readMessages(r *bufio.Reader, out chan<- *message) {
+	defer close(out)
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+		msg := new(message)
+		if err := json.Unmarshal(data, msg); err != nil {
+			return
+		}
+		out <- msg
+	}
+}
+
+func (c *client) read() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.out:
+		if !ok {
+			c.t.Fatal("server output is closed")
+		}
+		return msg
+	case <-time.After(10 * time.Second):
+		c.t.Fatal("server is not responded")
+	}
+	return nil
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.write(map[string]any{"method": method, "params": params})
+}
+
+// request sends request and returns response. Notifications that
+// received before response are recorded.
+func (c *client) request(method string, params any) *message {
+	c.t.Helper()
+	c.id++
+	c.write(map[string]any{"id": c.id, "method": method, "params": params})
+	for {
+		msg := c.read()
+		if msg.Id == nil {
+			c.notifications[msg.Method] = append(c.notifications[msg.Method], msg.Params)
+			continue
+		}
+		if string(*msg.Id) != strconv.Itoa(c.id) {
+			c.t.Fatalf("response of request %s, want %d", *msg.Id, c.id)
+		}
+		return msg
+	}
+}
+
+// result decodes result of request into v.
+func (c *client) result(method string, params, v any) {
+	c.t.Helper()
+	msg := c.request(method, params)
+	if msg.Error != nil {
+		c.t.Fatalf("%s: %s", method, msg.Error.Message)
+	}
+	data, err := json.Marshal(msg.Result)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// diagnostics returns diagnostics of latest publish of uri.
+// Requests are sent to make sure that pending notifications are read.
+func (c *client) diagnostics(uri string) ([]diagnostic, bool) {
+	c.t.Helper()
+	c.request("shutdown", nil)
+	var diags []diagnostic
+	found := false
+	for _, params := range c.notifications["textDocument/publishDiagnostics"] {
+		var p publishDiagnosticsParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			c.t.Fatal(err)
+		}
+		if p.Uri == uri {
+			diags, found = p.Diagnostics, true
+		}
+	}
+	c.notifications = map[string][]json.RawMessage{}
+	return diags, found
+}
+
+func (c *client) open(uri, text string) {
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "version": 1, "text": text},
+	})
+}
+
+func (c *client) exit() error {
+	c.t.Helper()
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		return err
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server is not exited")
+	}
+	return nil
+}
+
+func docPosition(uri string, line, char int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": char},
+	}
+}
+
+func TestInitialize(t *testing.T) {
+	c := newClient(t)
+	var result struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	c.result("initialize", map[string]any{}, &result)
+	for _, key := range []string{"hoverProvider", "definitionProvider", "documentSymbolProvider", "completionProvider"} {
+		if result.Capabilities[key] == nil {
+			t.Errorf("capability %s is not provided", key)
+		}
+	}
+	c.notify("initialized", map[string]any{})
+	if msg := c.request("textDocument/formatting", map[string]any{}); msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method: %+v", msg.Error)
+	}
+	// Unknown notifications are ignored.
+	c.notify("$/unknown", nil)
+	c.request("shutdown", nil)
+	if err := c.exit(); err != nil {
+		t.Errorf("exit after shutdown: %v", err)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	if err := c.exit(); err == nil {
+		t.Error("exit without shutdown is not an error")
+	}
+}
+
+const testSource = `//doc:
+// adds numbers
+add(a int, b int) int {
+	ret a + b
+}
+
+struct point {
+	x: int
+	y: int
+}
+
+main() {
+	p: = point{1, 2}
+	println(add(p.x, p.y))
+}
+`
+
+func TestDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	uri := pathToUri(filepath.Join(dir, "main.jn"))
+	c := newClient(t)
+	c.open(uri, "main() {\n\tprintln(undefined)\n}\n")
+	diags, ok := c.diagnostics(uri)
+	if !ok || len(diags) != 1 {
+		t.Fatalf("diagnostics: %+v", diags)
+	}
+	d := diags[0]
+	want := textRange{Start: position{Line: 1, Character: 9}, End: position{Line: 1, Character: 18}}
+	if d.Range != want || d.Severity != 1 || d.Code != "id_noexist" {
+		t.Errorf("diagnostic: %+v", d)
+	}
+
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri},
+		"contentChanges": []map[string]any{{"text": testSource}},
+	})
+	if diags, ok := c.diagnostics(uri); !ok || len(diags) != 0 {
+		t.Errorf("diagnostics after change: %+v", diags)
+	}
+
+	c.open(uri, "main() {\n\tprintln(undefined)\n}\n")
+	c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	if diags, ok := c.diagnostics(uri); !ok || len(diags) != 0 {
+		t.Errorf("diagnostics after close: %+v", diags)
+	}
+}
+
+func TestPackageDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "other.jn")
+	err := os.WriteFile(other, []byte("helper() int {\n\tret missing\n}\n"), 0o666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := pathToUri(filepath.Join(dir, "main.jn"))
+	c := newClient(t)
+	c.open(uri, "main() {\n\thelper()\n}\n")
+	diags, ok := c.diagnostics(pathToUri(other))
+	if !ok || len(diags) != 1 || diags[0].Code != "id_noexist" {
+		t.Errorf("diagnostics of other file: %+v", diags)
+	}
+	if diags, _ := c.diagnostics(uri); len(diags) != 0 {
+		t.Errorf("diagnostics of document: %+v", diags)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	dir := t.TempDir()
+	uri := pathToUri(filepath.Join(dir, "main.jn"))
+	c := newClient(t)
+	c.open(uri, testSource)
+
+	var h hover
+	// "add" in call
+	c.result("textDocument/hover", docPosition(uri, 13, 10), &h)
+	if !strings.Contains(h.Contents.Value, "add") || !strings.Contains(h.Contents.Value, "adds numbers") {
+		t.Errorf("hover: %q", h.Contents.Value)
+	}
+
+	var loc location
+	c.result("textDocument/definition", docPosition(uri, 13, 10), &loc)
+	want := textRange{Start: position{Line: 2, Character: 0}, End: position{Line: 2, Character: 3}}
+	if loc.Uri != uri || loc.Range != want {
+		t.Errorf("definition: %+v", loc)
+	}
+
+	// "x" field of "p.x"
+	var field *location
+	c.result("textDocument/definition", docPosition(uri, 13, 15), &field)
+	if field == nil || field.Range.Start.Line != 7 {
+		t.Errorf("definition of field: %+v", field)
+	}
+
+	var syms []documentSymbol
+	c.result("textDocument/documentSymbol", docPosition(uri, 0, 0), &syms)
+	names := map[string]bool{}
+	for _, sym := range syms {
+		names[sym.Name] = true
+	}
+	for _, name := range []string{"add", "point", "main"} {
+		if !names[name] {
+			t.Errorf("symbol %s is not listed: %+v", name, syms)
+		}
+	}
+
+	var items []completionItem
+	// after "p."
+	c.result("textDocument/completion", docPosition(uri, 13, 15), &items)
+	labels := map[string]bool{}
+	for _, item := range items {
+		labels[item.Label] = true
+	}
+	if !labels["x"] || !labels["y"] {
+		t.Errorf("member completion: %+v", items)
+	}
+
+	// Queries of unknown documents have null results.
+	if msg := c.request("textDocument/hover", docPosition(uri+"x", 0, 0)); msg.Error != nil || msg.Result != nil {
+		t.Errorf("unknown document: %+v", msg)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	cases := []struct {
+		line string
+		char int
+		col  int
+	}{
+		{"abc", 0, 1},
+		{"abc", 2, 3},
+		{"\tx", 1, 5},
+		// "ğ" is two bytes and one UTF-16 unit.
+		{"ğx", 1, 3},
+		// "😀" is four bytes and two UTF-16 units.
+		{"😀x", 2, 5},
+	}
+	for _, c := range cases {
+		col := lexColumn(c.line, c.char)
+		if col != c.col {
+			t.Errorf("lexColumn(%q, %d): got %d, want %d", c.line, c.char, col, c.col)
+		}
+		if char := utf16Char(c.line, col, 0); char != c.char {
+			t.Errorf("utf16Char(%q, %d): got %d, want %d", c.line, col, char, c.char)
+		}
+	}
+}
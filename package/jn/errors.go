@@ -143,12 +143,34 @@ var Errors = map[string]string{
 	`variadic_reference_param`:                 `referencing cannot combined with variadic parameters`,
 	`func_must_have_generics_if_has_attribute`: `function is must be have minimum one generic type if has @%s attribute`,
 	`func_cant_have_params_if_has_attribute`:   `function is cannot have parameter(s) if has @%s attribute`,
+	`func_cant_have_generics_if_has_attribute`: `function is cannot have generic type(s) if has @%s attribute`,
+	`func_cant_have_ret_if_has_attribute`:      `function is cannot have return type if has @%s attribute`,
 	`divide_by_zero`:                           `divide by zero`,
 	`trait_hasnt_id`:                           `%s trait is not have this identifier: %s`,
 	`notimpl_trait_def`:                        `not implemented %s trait's %s define`,
 	`dynamic_generic_annotation_failed`:        `dynamic generic type annotation failed`,
 	`fallthrough_wrong_use`:                    `fallthrough keyword can only useable at end of the case scopes`,
 	`fallthrough_into_final_case`:              `fallthrough cannot useable at final case`,
+	`cxx_compile_failed`:                       `C++ compilation failed: %s`,
+	`missing_source_path`:                      `missing source file path`,
+	`undefined_command`:                        `undefined command: %s`,
+	`undefined_set_key`:                        `undefined settings key: %s`,
+	`invalid_set_override`:                     `invalid settings override, expected key=value: %s`,
+	`no_source_files`:                          `no source file found in directory: %s`,
+	`invalid_test_pattern`:                     `invalid test pattern: %s`,
+	`invalid_diagnostics_format`:               `invalid diagnostics format: %s`,
+	`use_cycle`:                                `illegal use cycle: %s`,
+	`interp_cpp_link`:                          `cpp links are not supported by interpreter: %s`,
+	`repl_unknown_command`:                     `unknown command: %s`,
+	`cxx_error`:                                `C++: %s`,
+	`func_must_have_ret_if_has_attribute`:      `function is must be have return type if has @%s attribute`,
+	`comptime_not_evaluable`:                   `function call is cannot be evaluated at compile-time`,
+	`comptime_step_limit`:                      `compile-time evaluation exceeded the limit of %d steps`,
+	`comptime_call_depth`:                      `compile-time evaluation exceeded the call depth limit of %d`,
+	`static_assert_failed`:                     `static assertion failed: %s`,
+	`offsetof_not_struct`:                      `offsetof is requires struct type, found: %s`,
+	`match_not_exhaustive`:                     `match is not exhaustive, missing cases: %s`,
+	`interp_layout`:                            `type layouts are not supported by interpreter: %s`,
 }
 
 func GetError(key string, args ...any) string {
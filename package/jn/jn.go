@@ -48,8 +48,10 @@ const (
 	ArchAmd64 = "amd64"
 	ArchI386  = "i386"
 
-	Attribute_Inline  = "inline"
-	Attribute_TypeArg = "typearg"
+	Attribute_Inline   = "inline"
+	Attribute_TypeArg  = "typearg"
+	Attribute_Test     = "test"
+	Attribute_Comptime = "comptime"
 
 	PreprocessorDirective      = "pragma"
 	PreprocessorDirectiveEnofi = "enofi"
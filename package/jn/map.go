@@ -23,4 +23,6 @@ package jn
 var Attributes = [...]string{
 	0: Attribute_Inline,
 	1: Attribute_TypeArg,
+	2: Attribute_Test,
+	3: Attribute_Comptime,
 }
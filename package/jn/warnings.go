@@ -23,8 +23,13 @@ package jn
 import "fmt"
 
 var Warnings = map[string]string{
-	`doc_ignored`:         `documentation is ignored because object isn't supports documentations`,
-	`exist_undefined_doc`: `source code has undefined documentations (some documentations isn't document anything)`,
+	`doc_ignored`:          `documentation is ignored because object isn't supports documentations`,
+	`exist_undefined_doc`:  `source code has undefined documentations (some documentations isn't document anything)`,
+	`cxx_warning`:          `C++: %s`,
+	`match_not_exhaustive`: `match is not exhaustive, missing cases: %s`,
+	`unreachable_case`:     `unreachable case, value is already handled by previous case`,
+	`unreachable_code`:     `unreachable code`,
+	`use_before_assign`:    `variable "%s" may be used before assignment`,
 }
 
 func GetWarning(key string, args ...any) string {
@@ -70,33 +70,92 @@ func bytesToStr(bytes []byte) string {
 		return ""
 	}
 	var str strings.Builder
-	for i := 0; i < len(bytes); i++ {
-		b := bytes[i]
-		if b == '\\' {
-			i++
-			switch bytes[i] {
-			case 'u':
-				rc, _ := strconv.ParseUint(string(bytes[i+1:i+5]), 16, 32)
-				r := rune(rc)
-				str.WriteString(bytesToStr([]byte(string(r))))
-				i += 4
-			case 'U':
-				rc, _ := strconv.ParseUint(string(bytes[i+1:i+9]), 16, 32)
-				r := rune(rc)
-				str.WriteString(bytesToStr([]byte(string(r))))
-				i += 8
-			case 'x':
-				str.WriteByte('0')
-				str.Write(bytes[i : i+3])
-				i += 2
-			default:
-				str.Write(bytes[i : i+3])
-				i += 2
+	for i, b := range []byte(Unescape(string(bytes))) {
+		if i > 0 {
+			str.WriteByte(',')
+		}
+		str.WriteString(btoa(b))
+	}
+	return str.String()
+}
+
+// ToCppStr returns C++ string literal of string literal content.
+// Bytes that are not printable ASCII, quotes and backslashes
+// are written as octal escape sequences.
+func ToCppStr(content string) string {
+	var cpp strings.Builder
+	cpp.WriteByte('"')
+	for _, b := range []byte(Unescape(content)) {
+		if b < ' ' || b > '~' || b == '"' || b == '\\' || b == '?' {
+			cpp.WriteByte('\\')
+			cpp.WriteString(strconv.FormatUint(uint64(b)|0o1000, 8)[1:])
+			continue
+		}
+		cpp.WriteByte(b)
+	}
+	cpp.WriteByte('"')
+	return cpp.String()
+}
+
+// Unescape returns string literal content with decoded escape sequences.
+func Unescape(s string) string {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				n = len(s) - i - 1
+			}
+			r, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			sb.WriteRune(rune(r))
+			i += n
+		case 'x':
+			n := 2
+			if i+n >= len(s) {
+				n = len(s) - i - 1
+			}
+			b, _ := strconv.ParseUint(s[i+1:i+1+n], 16, 8)
+			sb.WriteByte(byte(b))
+			i += n
+		default:
+			if c >= '0' && c <= '7' {
+				j := i
+				for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+					j++
+				}
+				b, _ := strconv.ParseUint(s[i:j], 8, 8)
+				sb.WriteByte(byte(b))
+				i = j - 1
+			} else {
+				sb.WriteByte(c)
 			}
-		} else {
-			str.WriteString(btoa(b))
 		}
-		str.WriteByte(',')
 	}
-	return str.String()[:str.Len()-1]
+	return sb.String()
 }
@@ -20,6 +20,14 @@
 
 package jnapi
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+)
+
 var CppHeaderExtensions = []string{
 	".h",
 	".hpp",
@@ -35,3 +43,29 @@ func IsValidHeader(ext string) bool {
 	}
 	return false
 }
+
+// Standard returns prelude of generated code that includes header.
+func Standard(header string) string {
+	year, month, day := time.Now().Date()
+	hour, min, _ := time.Now().Clock()
+	timeStr := fmt.Sprintf("%d/%d/%d %d.%d (DD/MM/YYYY) (HH.MM)",
+		day, month, year, hour, min)
+	var sb strings.Builder
+	sb.WriteString("// Auto generated by JN compiler.\n")
+	sb.WriteString("// JN version:")
+	sb.WriteString(jn.Version)
+	sb.WriteByte('\n')
+	sb.WriteString("// Date: ")
+	sb.WriteString(timeStr)
+	sb.WriteString("\n\n")
+	sb.WriteString("// this file contains cpp module code which is automatically generated by JN")
+	sb.WriteByte('\n')
+	sb.WriteString("// compiler. generated code in this file provide cpp functions and structures")
+	sb.WriteByte('\n')
+	sb.WriteString("// corresponding to the definition in the JN source files")
+	sb.WriteString("\n\n")
+	sb.WriteString("\n\n#include \"")
+	sb.WriteString(header)
+	sb.WriteString("\"\n\n")
+	return sb.String()
+}
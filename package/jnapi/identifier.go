@@ -21,9 +21,9 @@
 package jnapi
 
 import (
-	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
-	"unsafe"
 
 	"github.com/DeRuneLabs/jane/package/jnio"
 )
@@ -40,23 +40,20 @@ func AsId(id string) string {
 	return "JNID(" + id + ")"
 }
 
-func getPtrAsId(ptr unsafe.Pointer) string {
-	address := fmt.Sprintf("%p", ptr)
-	address = address[3:]
-	for i, r := range address {
-		if r != '0' {
-			address = address[i:]
-			break
-		}
-	}
-	return address
+// fileId returns identifier of file.
+// identifier is derived from path, so it is not depends on
+// parsing order of files.
+func fileId(f *jnio.File) string {
+	h := fnv.New32a()
+	h.Write([]byte(f.Path()))
+	return strconv.FormatUint(uint64(h.Sum32()), 16)
 }
 
 func OutId(id string, f *jnio.File) string {
 	if f != nil {
 		var out strings.Builder
 		out.WriteByte('f')
-		out.WriteString(getPtrAsId(unsafe.Pointer(f)))
+		out.WriteString(fileId(f))
 		out.WriteByte('_')
 		out.WriteString(id)
 		return out.String()
@@ -20,7 +20,10 @@
 
 package jnio
 
-import "path/filepath"
+import (
+	"path/filepath"
+	"strings"
+)
 
 type File struct {
 	Dir  string
@@ -31,3 +34,26 @@ type File struct {
 func (f *File) Path() string {
 	return filepath.Join(f.Dir, f.Name)
 }
+
+// Line returns source line of row without line terminator.
+// rows are starts at 1.
+func (f *File) Line(row int) string {
+	start := 0
+	for i, r := range f.Data {
+		if row <= 1 {
+			break
+		}
+		if r == '\n' {
+			row--
+			start = i + 1
+		}
+	}
+	if row > 1 {
+		return ""
+	}
+	end := start
+	for end < len(f.Data) && f.Data[end] != '\n' {
+		end++
+	}
+	return strings.TrimRight(string(f.Data[start:end]), "\r")
+}
@@ -22,18 +22,34 @@ package jnio
 
 import (
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 
 	"github.com/DeRuneLabs/jane/package/jn"
 )
 
+// OS is file system of operating system.
+// unlike os.DirFS, paths are used as is.
+var OS fs.FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
 func OpenJn(path string) (*File, error) {
 	path, _ = filepath.Abs(path)
+	return ReadJn(OS, path)
+}
+
+// ReadJn reads Jn source file of path from fsys.
+func ReadJn(fsys fs.FS, path string) (*File, error) {
 	if filepath.Ext(path) != jn.SrcExt {
 		return nil, errors.New(jn.GetError("file_not_jn", path))
 	}
-	bytes, err := os.ReadFile(path)
+	bytes, err := fs.ReadFile(fsys, path)
 	if err != nil {
 		return nil, err
 	}
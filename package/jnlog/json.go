@@ -0,0 +1,67 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jnlog
+
+import "encoding/json"
+
+type jsonLog struct {
+	Severity string `json:"severity"`
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+	Path     string `json:"path,omitempty"`
+	Row      int    `json:"row,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+type jsonReport struct {
+	Errors      int       `json:"errors"`
+	Warnings    int       `json:"warnings"`
+	Diagnostics []jsonLog `json:"diagnostics"`
+}
+
+func severity(clog *CompilerLog) string {
+	if clog.IsError() {
+		return "error"
+	}
+	return "warning"
+}
+
+// JSON returns logs as JSON report.
+func JSON(logs []CompilerLog) ([]byte, error) {
+	report := jsonReport{Diagnostics: make([]jsonLog, len(logs))}
+	for i := range logs {
+		clog := &logs[i]
+		if clog.IsError() {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+		report.Diagnostics[i] = jsonLog{
+			Severity: severity(clog),
+			Key:      clog.Key,
+			Message:  clog.Message,
+			Path:     clog.Path,
+			Row:      clog.Row,
+			Column:   clog.Column,
+		}
+	}
+	return json.MarshalIndent(report, "", "  ")
+}
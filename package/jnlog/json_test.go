@@ -0,0 +1,95 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jnlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testLogs are logs of each type.
+var testLogs = []CompilerLog{
+	{Type: Warning, Row: 3, Column: 5, Path: "/src/main.jn", Key: "unreachable_code", Message: "unreachable code"},
+	{Type: Error, Row: 7, Column: 2, Path: "/src/main.jn", Key: "id_noexist", Message: "identifier is not exist: x"},
+	{Type: FlatError, Key: "no_stdlib", Message: "standard library directory not found"},
+	{Type: FlatWarning, Message: "C++: note"},
+}
+
+func TestJSON(t *testing.T) {
+	bytes, err := JSON(testLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]any
+	if err := json.Unmarshal(bytes, &report); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, bytes)
+	}
+	want := map[string]any{
+		"errors":   2.0,
+		"warnings": 2.0,
+		"diagnostics": []any{
+			map[string]any{
+				"severity": "warning",
+				"key":      "unreachable_code",
+				"message":  "unreachable code",
+				"path":     "/src/main.jn",
+				"row":      3.0,
+				"column":   5.0,
+			},
+			map[string]any{
+				"severity": "error",
+				"key":      "id_noexist",
+				"message":  "identifier is not exist: x",
+				"path":     "/src/main.jn",
+				"row":      7.0,
+				"column":   2.0,
+			},
+			map[string]any{
+				"severity": "error",
+				"key":      "no_stdlib",
+				"message":  "standard library directory not found",
+			},
+			map[string]any{
+				"severity": "warning",
+				"key":      "",
+				"message":  "C++: note",
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report:\n%s", bytes)
+	}
+}
+
+func TestJSONEmpty(t *testing.T) {
+	bytes, err := JSON(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]any
+	if err := json.Unmarshal(bytes, &report); err != nil {
+		t.Fatal(err)
+	}
+	if diagnostics, ok := report["diagnostics"].([]any); !ok || len(diagnostics) != 0 {
+		t.Errorf("diagnostics is not empty array:\n%s", bytes)
+	}
+}
@@ -34,12 +34,27 @@ const (
 
 const warningMark = "<!>"
 
+// Related is a secondary location of log, such as previous declaration.
+type Related struct {
+	Row    int
+	Column int
+	Length int
+	Path   string
+	Line   string
+	Label  string
+}
+
 type CompilerLog struct {
 	Type    uint8
 	Row     int
 	Column  int
+	Length  int
 	Path    string
+	Line    string
+	Key     string
 	Message string
+	Notes   []string
+	Related []Related
 }
 
 func (clog *CompilerLog) flatError() string {
@@ -76,6 +91,14 @@ func (clog *CompilerLog) warning() string {
 	return log.String()
 }
 
+func (clog *CompilerLog) IsError() bool {
+	return clog.Type == FlatError || clog.Type == Error
+}
+
+func (clog *CompilerLog) IsFlat() bool {
+	return clog.Type == FlatError || clog.Type == FlatWarning
+}
+
 func (clog CompilerLog) String() string {
 	switch clog.Type {
 	case FlatError:
@@ -0,0 +1,188 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jnlog
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[1;31m"
+	ansiYellow = "\033[1;33m"
+	ansiBlue   = "\033[1;34m"
+	ansiCyan   = "\033[1;36m"
+)
+
+// tabWidth is column width of tab character in lexer.
+const tabWidth = 4
+
+type painter bool
+
+func (p painter) paint(color, text string) string {
+	if !p {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// displayLine expands tabs of line and returns display offset of column.
+// column counted like lexer; tab is four columns, other runes are
+// byte length of rune.
+func displayLine(line string, column int) (string, int) {
+	var sb strings.Builder
+	col := 1
+	offset := -1
+	for _, r := range line {
+		if offset == -1 && col >= column {
+			offset = sb.Len()
+		}
+		if r == '\t' {
+			sb.WriteString(strings.Repeat(" ", tabWidth))
+			col += tabWidth
+			continue
+		}
+		sb.WriteRune(r)
+		col += utf8.RuneLen(r)
+	}
+	if offset == -1 {
+		offset = sb.Len()
+	}
+	return sb.String(), utf8.RuneCountInString(sb.String()[:offset])
+}
+
+type snippet struct {
+	row    int
+	column int
+	length int
+	path   string
+	line   string
+	label  string
+	arrow  string
+	mark   byte
+	color  string
+}
+
+func gutterWidth(rows ...int) int {
+	max := 0
+	for _, row := range rows {
+		n := len(strconv.Itoa(row))
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+func (p painter) writeSnippet(sb *strings.Builder, s snippet, width int) {
+	pad := strings.Repeat(" ", width)
+	sb.WriteString(pad)
+	sb.WriteString(p.paint(ansiBlue, s.arrow+" "))
+	sb.WriteString(s.path)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(s.row))
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(s.column))
+	sb.WriteByte('\n')
+	if s.line == "" {
+		return
+	}
+	gutter := p.paint(ansiBlue, pad+" |")
+	sb.WriteString(gutter)
+	sb.WriteByte('\n')
+	line, offset := displayLine(s.line, s.column)
+	row := strconv.Itoa(s.row)
+	sb.WriteString(p.paint(ansiBlue, strings.Repeat(" ", width-len(row))+row+" |"))
+	sb.WriteByte(' ')
+	sb.WriteString(line)
+	sb.WriteByte('\n')
+	length := s.length
+	if length < 1 {
+		length = 1
+	}
+	sb.WriteString(gutter)
+	sb.WriteByte(' ')
+	sb.WriteString(strings.Repeat(" ", offset))
+	marks := strings.Repeat(string(s.mark), length)
+	if s.label != "" {
+		marks += " " + s.label
+	}
+	sb.WriteString(p.paint(s.color, marks))
+	sb.WriteByte('\n')
+}
+
+// Pretty returns log with source snippet, underline of span,
+// related locations and notes. Uses ANSI colors if color is true.
+func (clog *CompilerLog) Pretty(color bool) string {
+	p := painter(color)
+	var sb strings.Builder
+	level, levelColor := "error", ansiRed
+	if !clog.IsError() {
+		level, levelColor = "warning", ansiYellow
+	}
+	if clog.Key != "" {
+		level += "[" + clog.Key + "]"
+	}
+	sb.WriteString(p.paint(levelColor, level))
+	sb.WriteString(p.paint(ansiBold, ": "+clog.Message))
+	sb.WriteByte('\n')
+	rows := []int{clog.Row}
+	for _, r := range clog.Related {
+		rows = append(rows, r.Row)
+	}
+	width := gutterWidth(rows...)
+	if !clog.IsFlat() {
+		p.writeSnippet(&sb, snippet{
+			row:    clog.Row,
+			column: clog.Column,
+			length: clog.Length,
+			path:   clog.Path,
+			line:   clog.Line,
+			arrow:  "-->",
+			mark:   '^',
+			color:  levelColor,
+		}, width)
+	}
+	for _, r := range clog.Related {
+		p.writeSnippet(&sb, snippet{
+			row:    r.Row,
+			column: r.Column,
+			length: r.Length,
+			path:   r.Path,
+			line:   r.Line,
+			label:  r.Label,
+			arrow:  ":::",
+			mark:   '-',
+			color:  ansiCyan,
+		}, width)
+	}
+	for _, note := range clog.Notes {
+		sb.WriteString(strings.Repeat(" ", width))
+		sb.WriteString(p.paint(ansiBlue, " = "))
+		sb.WriteString(p.paint(ansiBold, "note: "))
+		sb.WriteString(note)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jnlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPretty(t *testing.T) {
+	cases := []struct {
+		name string
+		log  CompilerLog
+		want string
+	}{
+		{
+			name: "error",
+			log: CompilerLog{
+				Type: Error, Row: 2, Column: 13, Length: 3, Path: "main.jn",
+				Line: "    println(abc)", Key: "id_noexist", Message: "identifier is not exist: abc",
+			},
+			want: `error[id_noexist]: identifier is not exist: abc
+ --> main.jn:2:13
+  |
+2 |     println(abc)
+  |             ^^^
+`,
+		},
+		{
+			name: "warning with tab and zero length",
+			log: CompilerLog{
+				Type: Warning, Row: 10, Column: 5, Path: "main.jn",
+				Line: "\tx: = 1", Message: "unused variable",
+			},
+			want: `warning: unused variable
+  --> main.jn:10:5
+   |
+10 |     x: = 1
+   |     ^
+`,
+		},
+		{
+			name: "related and notes",
+			log: CompilerLog{
+				Type: Error, Row: 9, Column: 1, Length: 1, Path: "main.jn",
+				Line: "f() {}", Key: "exist_id", Message: "identifier is already exist: f",
+				Related: []Related{{
+					Row: 120, Column: 1, Length: 1, Path: "main.jn",
+					Line: "f() {}", Label: "previous declaration",
+				}},
+				Notes: []string{"rename one of declarations"},
+			},
+			want: `error[exist_id]: identifier is already exist: f
+   --> main.jn:9:1
+    |
+  9 | f() {}
+    | ^
+   ::: main.jn:120:1
+    |
+120 | f() {}
+    | - previous declaration
+    = note: rename one of declarations
+`,
+		},
+		{
+			name: "flat",
+			log:  CompilerLog{Type: FlatError, Key: "no_stdlib", Message: "standard library directory not found"},
+			want: "error[no_stdlib]: standard library directory not found\n",
+		},
+		{
+			name: "without line",
+			log:  CompilerLog{Type: Error, Row: 1, Column: 1, Path: "main.jn", Message: "file is not useable"},
+			want: "error: file is not useable\n --> main.jn:1:1\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.log.Pretty(false); got != c.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestPrettyColor(t *testing.T) {
+	log := CompilerLog{
+		Type: Warning, Row: 1, Column: 1, Length: 1, Path: "main.jn",
+		Line: "x", Message: "warn",
+	}
+	got := log.Pretty(true)
+	for _, want := range []string{ansiYellow + "warning" + ansiReset, ansiYellow + "^" + ansiReset, ansiBlue} {
+		if !strings.Contains(got, want) {
+			t.Errorf("colored output does not contain %q:\n%q", want, got)
+		}
+	}
+	if plain := log.Pretty(false); strings.Contains(plain, "\033[") {
+		t.Errorf("plain output has escapes: %q", plain)
+	}
+}
+
+func TestDisplayLine(t *testing.T) {
+	cases := []struct {
+		line   string
+		column int
+		want   string
+		offset int
+	}{
+		{"abc", 2, "abc", 1},
+		{"\tx", 5, "    x", 4},
+		{"\t\tx", 9, "        x", 8},
+		// Columns count bytes of runes, offsets count runes.
+		{"ğ = x", 4, "ğ = x", 2},
+		{"ab", 10, "ab", 2},
+	}
+	for _, c := range cases {
+		line, offset := displayLine(c.line, c.column)
+		if line != c.want || offset != c.offset {
+			t.Errorf("displayLine(%q, %d): got %q, %d, want %q, %d", c.line, c.column, line, offset, c.want, c.offset)
+		}
+	}
+}
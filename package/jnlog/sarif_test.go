@@ -0,0 +1,80 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jnlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSARIF(t *testing.T) {
+	bytes, err := SARIF(testLogs, "jane", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(bytes, &log); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, bytes)
+	}
+	if log.Version != "2.1.0" || log.Schema == "" || len(log.Runs) != 1 {
+		t.Fatalf("invalid SARIF log:\n%s", bytes)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "jane" || run.Tool.Driver.Version != "1.0" {
+		t.Errorf("invalid driver: %+v", run.Tool.Driver)
+	}
+	rules := []sarifRule{{Id: "unreachable_code"}, {Id: "id_noexist"}, {Id: "no_stdlib"}}
+	if !reflect.DeepEqual(run.Tool.Driver.Rules, rules) {
+		t.Errorf("rules %+v, want %+v", run.Tool.Driver.Rules, rules)
+	}
+	location := func(row, column int) []sarifLocation {
+		return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifact{URI: "file:///src/main.jn"},
+			Region:           sarifRegion{StartLine: row, StartColumn: column},
+		}}}
+	}
+	results := []sarifResult{
+		{RuleId: "unreachable_code", Level: "warning", Message: sarifMessage{"unreachable code"}, Locations: location(3, 5)},
+		{RuleId: "id_noexist", Level: "error", Message: sarifMessage{"identifier is not exist: x"}, Locations: location(7, 2)},
+		{RuleId: "no_stdlib", Level: "error", Message: sarifMessage{"standard library directory not found"}},
+		{Level: "warning", Message: sarifMessage{"C++: note"}},
+	}
+	if !reflect.DeepEqual(run.Results, results) {
+		t.Errorf("results:\n%s", bytes)
+	}
+}
+
+func TestSARIFRelativePath(t *testing.T) {
+	logs := []CompilerLog{{Type: Error, Row: 1, Column: 1, Path: "src/main.jn", Message: "error"}}
+	bytes, err := SARIF(logs, "jane", "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(bytes, &log); err != nil {
+		t.Fatal(err)
+	}
+	uri := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI
+	if uri != "src/main.jn" {
+		t.Errorf("uri %q, want %q", uri, "src/main.jn")
+	}
+}
@@ -27,26 +27,58 @@ const (
 	ModeCompile   = "compile"
 )
 
+var Optimizations = [...]string{
+	0: "",
+	1: "0",
+	2: "1",
+	3: "2",
+	4: "3",
+	5: "s",
+	6: "fast",
+}
+
 type JnSet struct {
-	CppOutDir    string   `json:"cxx_out_dir"`
-	CppOutName   string   `json:"cxx_out_name"`
-	OutName      string   `json:"out_name"`
-	Language     string   `json:"language"`
-	Mode         string   `json:"mode"`
-	PostCommands []string `json:"post_commands"`
-	Indent       string   `json:"indent"`
-	IndentCount  int      `json:"indent_count"`
+	CppOutDir      string   `json:"cxx_out_dir"`
+	CppOutName     string   `json:"cxx_out_name"`
+	OutName        string   `json:"out_name"`
+	Language       string   `json:"language"`
+	Mode           string   `json:"mode"`
+	PostCommands   []string `json:"post_commands"`
+	Indent         string   `json:"indent"`
+	IndentCount    int      `json:"indent_count"`
+	Compiler       string   `json:"compiler"`
+	CxxStandard    string   `json:"cxx_standard"`
+	CxxFlags       []string `json:"cxx_flags"`
+	IncludeDirs    []string `json:"include_dirs"`
+	LinkLibs       []string `json:"link_libs"`
+	Optimization   string   `json:"optimization"`
+	Werror         bool     `json:"werror"`
+	LineDirectives bool     `json:"line_directives"`
+	SourceMap      bool     `json:"source_map"`
+	Dce            bool     `json:"dce"`
+	StrictMatch    bool     `json:"strict_match"`
 }
 
 var Default = &JnSet{
-	CppOutDir:    "./dist",
-	CppOutName:   "jn.cpp",
-	OutName:      "main",
-	Language:     "",
-	Mode:         "transpile",
-	Indent:       "\t",
-	IndentCount:  1,
-	PostCommands: []string{},
+	CppOutDir:      "./dist",
+	CppOutName:     "jn.cpp",
+	OutName:        "main",
+	Language:       "",
+	Mode:           "transpile",
+	Indent:         "\t",
+	IndentCount:    1,
+	PostCommands:   []string{},
+	Compiler:       "g++",
+	CxxStandard:    "c++17",
+	CxxFlags:       []string{},
+	IncludeDirs:    []string{},
+	LinkLibs:       []string{},
+	Optimization:   "",
+	Werror:         false,
+	LineDirectives: false,
+	SourceMap:      false,
+	Dce:            true,
+	StrictMatch:    false,
 }
 
 func Load(bytes []byte) (*JnSet, error) {
@@ -22,7 +22,7 @@ package parser
 
 import (
 	"github.com/DeRuneLabs/jane/ast/models"
-	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/package/jn"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
@@ -38,15 +38,27 @@ func getParamMap(params []Param) *paramMap {
 }
 
 type pureArgParser struct {
-	p       *Parser
-	pmap    *paramMap
-	f       *Func
-	args    *models.Args
-	i       int
-	arg     Arg
-	errTok  Tok
-	m       *exprModel
-	paramId string
+	p         *Parser
+	pmap      *paramMap
+	f         *Func
+	args      *models.Args
+	i         int
+	arg       Arg
+	errTok    Tok
+	paramId   string
+	tupleArgs bool
+}
+
+func variadicParamType(param *Param) DataType {
+	t := DataType{
+		Id:            jntype.Slice,
+		Tok:           param.Type.Tok,
+		Kind:          jn.Prefix_Slice + param.Type.Kind,
+		Pure:          true,
+		ComponentType: new(DataType),
+	}
+	*t.ComponentType = param.Type
+	return t
 }
 
 func (pap *pureArgParser) buildArgs() {
@@ -57,15 +69,7 @@ func (pap *pureArgParser) buildArgs() {
 		case pair.arg != nil:
 			pap.args.Src[i] = *pair.arg
 		case pair.param.Variadic:
-			t := DataType{
-				Id:            jntype.Slice,
-				Tok:           pair.param.Type.Tok,
-				Kind:          jn.Prefix_Slice + pair.param.Type.Kind,
-				Pure:          true,
-				ComponentType: new(DataType),
-			}
-			*t.ComponentType = pair.param.Type
-			model := sliceExpr{t, nil}
+			model := &ir.Composite{Type: variadicParamType(pair.param)}
 			arg := Arg{Expr: Expr{Model: model}}
 			pap.args.Src[i] = arg
 		}
@@ -73,23 +77,24 @@ func (pap *pureArgParser) buildArgs() {
 }
 
 func (pap *pureArgParser) pushVariadicArgs(pair *paramMapPair) {
-	var model serieExpr
+	model := &ir.Composite{Type: variadicParamType(pair.param)}
 	variadiced := false
 	pap.p.parseArg(pap.f, pair, pap.args, &variadiced)
-	model.exprs = append(model.exprs, pair.arg.Expr.Model.(iExpr))
+	model.Elems = append(model.Elems, pair.arg.Expr.Model)
 	once := false
 	for pap.i++; pap.i < len(pap.args.Src); pap.i++ {
 		pair.arg = &pap.args.Src[pap.i]
 		once = true
 		pap.p.parseArg(pap.f, pair, pap.args, &variadiced)
-		model.exprs = append(model.exprs, exprNode{tokens.COMMA})
-		model.exprs = append(model.exprs, pair.arg.Expr.Model.(iExpr))
+		model.Elems = append(model.Elems, pair.arg.Expr.Model)
 	}
-	model.exprs = append(model.exprs, exprNode{tokens.RBRACE})
-	pair.arg.Expr.Model = model
 	if !once {
+		if !variadiced {
+			pair.arg.Expr.Model = model
+		}
 		return
 	}
+	pair.arg.Expr.Model = model
 	if variadiced {
 		pap.p.pusherrtok(pap.errTok, "more_args_with_variadiced")
 	}
@@ -152,16 +157,10 @@ func (pap *pureArgParser) tryFuncMultiRetAsArgs() bool {
 	} else if len(types) > len(pap.f.Params) {
 		return false
 	}
-	if pap.m != nil {
-		fname := pap.m.nodes[pap.m.index].nodes[0]
-		pap.m.nodes[pap.m.index].nodes[0] = exprNode{"tuple_as_args"}
-		pap.args.Src = make([]Arg, 2)
-		pap.args.Src[0] = Arg{Expr: Expr{Model: fname}}
-		pap.args.Src[1] = arg
-	}
+	pap.args.Src[0] = arg
+	pap.tupleArgs = true
 	for i, param := range pap.f.Params {
 		rt := types[i]
-		pap.p.wg.Add(1)
 		val := value{data: models.Data{Type: rt}}
 		pap.p.checkArgType(&param, val, arg.Tok)
 	}
@@ -22,9 +22,9 @@ package parser
 
 import (
 	"math"
-	"strconv"
 
 	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jn"
 	"github.com/DeRuneLabs/jane/package/jntype"
@@ -39,8 +39,9 @@ var i8statics = &Defmap{
 			Type:    DataType{Id: jntype.I8, Kind: tokens.I8},
 			ExprTag: int64(math.MaxInt8),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I8) + "{" + strconv.FormatInt(math.MaxInt8, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I8, Kind: tokens.I8},
+					Value: int64(math.MaxInt8),
 				},
 			},
 		},
@@ -51,8 +52,9 @@ var i8statics = &Defmap{
 			Type:    DataType{Id: jntype.I8, Kind: tokens.I8},
 			ExprTag: int64(math.MinInt8),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I8) + "{" + strconv.FormatInt(math.MinInt8, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I8, Kind: tokens.I8},
+					Value: int64(math.MinInt8),
 				},
 			},
 		},
@@ -68,8 +70,9 @@ var i16statics = &Defmap{
 			Type:    DataType{Id: jntype.I16, Kind: tokens.I16},
 			ExprTag: int64(math.MaxInt16),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I16) + "{" + strconv.FormatInt(math.MaxInt16, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I16, Kind: tokens.I16},
+					Value: int64(math.MaxInt16),
 				},
 			},
 		},
@@ -80,8 +83,9 @@ var i16statics = &Defmap{
 			Type:    DataType{Id: jntype.I16, Kind: tokens.I16},
 			ExprTag: int64(math.MinInt16),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I16) + "{" + strconv.FormatInt(math.MinInt16, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I16, Kind: tokens.I16},
+					Value: int64(math.MinInt16),
 				},
 			},
 		},
@@ -97,8 +101,9 @@ var i32statics = &Defmap{
 			Type:    DataType{Id: jntype.I32, Kind: tokens.I32},
 			ExprTag: int64(math.MaxInt32),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I32) + "{" + strconv.FormatInt(math.MaxInt32, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I32, Kind: tokens.I32},
+					Value: int64(math.MaxInt32),
 				},
 			},
 		},
@@ -109,8 +114,9 @@ var i32statics = &Defmap{
 			Type:    DataType{Id: jntype.I32, Kind: tokens.I32},
 			ExprTag: int64(math.MinInt32),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I32) + "{" + strconv.FormatInt(math.MinInt32, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I32, Kind: tokens.I32},
+					Value: int64(math.MinInt32),
 				},
 			},
 		},
@@ -126,8 +132,9 @@ var i64statics = &Defmap{
 			Type:    DataType{Id: jntype.I64, Kind: tokens.I64},
 			ExprTag: int64(math.MaxInt64),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I64) + "{" + strconv.FormatInt(math.MaxInt64, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I64, Kind: tokens.I64},
+					Value: int64(math.MaxInt64),
 				},
 			},
 		},
@@ -138,8 +145,9 @@ var i64statics = &Defmap{
 			Type:    DataType{Id: jntype.I64, Kind: tokens.I64},
 			ExprTag: int64(math.MinInt64),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.I64) + "{" + strconv.FormatInt(math.MinInt64, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.I64, Kind: tokens.I64},
+					Value: int64(math.MinInt64),
 				},
 			},
 		},
@@ -155,8 +163,9 @@ var u8statics = &Defmap{
 			Type:    DataType{Id: jntype.U8, Kind: tokens.U8},
 			ExprTag: uint64(math.MaxUint8),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.U8) + "{" + strconv.FormatUint(math.MaxUint8, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.U8, Kind: tokens.U8},
+					Value: uint64(math.MaxUint8),
 				},
 			},
 		},
@@ -172,8 +181,9 @@ var u16statics = &Defmap{
 			Type:    DataType{Id: jntype.U16, Kind: tokens.U16},
 			ExprTag: uint64(math.MaxUint16),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.U16) + "{" + strconv.FormatUint(math.MaxUint16, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.U16, Kind: tokens.U16},
+					Value: uint64(math.MaxUint16),
 				},
 			},
 		},
@@ -189,8 +199,9 @@ var u32statics = &Defmap{
 			Type:    DataType{Id: jntype.U32, Kind: tokens.U32},
 			ExprTag: uint64(math.MaxUint32),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.U32) + "{" + strconv.FormatUint(math.MaxUint32, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.U32, Kind: tokens.U32},
+					Value: uint64(math.MaxUint32),
 				},
 			},
 		},
@@ -206,8 +217,9 @@ var u64statics = &Defmap{
 			Type:    DataType{Id: jntype.U64, Kind: tokens.U64},
 			ExprTag: uint64(math.MaxUint64),
 			Expr: models.Expr{
-				Model: exprNode{
-					jntype.CppId(jntype.U64) + "{" + strconv.FormatUint(math.MaxUint64, 10) + "}",
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.U64, Kind: tokens.U64},
+					Value: uint64(math.MaxUint64),
 				},
 			},
 		},
@@ -242,8 +254,9 @@ var intStatics = &Defmap{
 
 const f32min = float64(1.17549435082228750796873653722224568e-38)
 
-var f32min_model = exprNode{
-	jntype.CppId(jntype.F32) + "{1.17549435082228750796873653722224568e-38F}",
+var f32min_model = &ir.Const{
+	Type:  DataType{Id: jntype.F32, Kind: tokens.F32},
+	Value: f32min,
 }
 
 var f32statics = &Defmap{
@@ -255,7 +268,10 @@ var f32statics = &Defmap{
 			Type:    DataType{Id: jntype.F32, Kind: tokens.F32},
 			ExprTag: float64(math.MaxFloat32),
 			Expr: models.Expr{
-				Model: exprNode{strconv.FormatFloat(math.MaxFloat32, 'e', -1, 32) + "F"},
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.F32, Kind: tokens.F32},
+					Value: float64(math.MaxFloat32),
+				},
 			},
 		},
 		{
@@ -271,8 +287,9 @@ var f32statics = &Defmap{
 
 const f64min = float64(2.22507385850720138309023271733240406e-308)
 
-var f64min_model = exprNode{
-	jntype.CppId(jntype.F64) + "{2.22507385850720138309023271733240406e-308}",
+var f64min_model = &ir.Const{
+	Type:  DataType{Id: jntype.F64, Kind: tokens.F64},
+	Value: f64min,
 }
 
 var f64statics = &Defmap{
@@ -284,7 +301,10 @@ var f64statics = &Defmap{
 			Type:    DataType{Id: jntype.F64, Kind: tokens.F64},
 			ExprTag: float64(math.MaxFloat64),
 			Expr: models.Expr{
-				Model: exprNode{strconv.FormatFloat(math.MaxFloat64, 'e', -1, 64)},
+				Model: &ir.Const{
+					Type:  DataType{Id: jntype.F64, Kind: tokens.F64},
+					Value: float64(math.MaxFloat64),
+				},
 			},
 		},
 		{
@@ -305,350 +325,403 @@ var strDefaultFunc = Func{
 	RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
 }
 
-var errorTrait = &trait{
-	Ast: &models.Trait{
-		Id: "Error",
-	},
-	Defs: &Defmap{
-		Funcs: []*function{
-			{Ast: &models.Func{
-				Pub:     true,
-				Id:      "error",
-				RetType: models.RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-			}},
+// newBuiltin returns new builtin definitions.
+// every environment has own builtin definitions,
+// because generic functions are mutated while checking calls.
+func newBuiltin() *Defmap {
+	errorTrait := &trait{
+		Ast: &models.Trait{
+			Id: "Error",
 		},
-	},
-}
+		Defs: &Defmap{
+			Funcs: []*function{
+				{Ast: &models.Func{
+					Pub:     true,
+					Id:      "error",
+					RetType: models.RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+				}},
+			},
+		},
+	}
 
-var errorType = DataType{
-	Id:   jntype.Trait,
-	Kind: errorTrait.Ast.Id,
-	Tag:  errorTrait,
-	Pure: true,
-}
+	errorType := DataType{
+		Id:   jntype.Trait,
+		Kind: errorTrait.Ast.Id,
+		Tag:  errorTrait,
+		Pure: true,
+	}
+
+	panicFunc := &function{
+		Ast: &models.Func{
+			Pub: true,
+			Id:  "panic",
+			Params: []models.Param{
+				{
+					Id:   "error",
+					Type: errorType,
+				},
+			},
+		},
+	}
 
-var panicFunc = &function{
-	Ast: &models.Func{
-		Pub: true,
-		Id:  "panic",
+	errorHandlerFunc := &models.Func{
+		Id: "handler",
 		Params: []models.Param{
 			{
 				Id:   "error",
 				Type: errorType,
 			},
 		},
-	},
-}
-
-var errorHandlerFunc = &models.Func{
-	Id: "handler",
-	Params: []models.Param{
-		{
-			Id:   "error",
-			Type: errorType,
-		},
-	},
-	RetType: models.RetType{
-		Type: models.DataType{
-			Id:   jntype.Void,
-			Kind: jntype.TypeMap[jntype.Void],
+		RetType: models.RetType{
+			Type: models.DataType{
+				Id:   jntype.Void,
+				Kind: jntype.TypeMap[jntype.Void],
+			},
 		},
-	},
-}
+	}
 
-var recoverFunc = &function{
-	Ast: &models.Func{
-		Pub: true,
-		Id:  "recover",
-		Params: []models.Param{
-			{
-				Id: "handler",
-				Type: models.DataType{
-					Id:   jntype.Func,
-					Kind: errorHandlerFunc.DataTypeString(),
-					Tag:  errorHandlerFunc,
+	recoverFunc := &function{
+		Ast: &models.Func{
+			Pub: true,
+			Id:  "recover",
+			Params: []models.Param{
+				{
+					Id: "handler",
+					Type: models.DataType{
+						Id:   jntype.Func,
+						Kind: errorHandlerFunc.DataTypeString(),
+						Tag:  errorHandlerFunc,
+					},
 				},
 			},
 		},
-	},
-}
+	}
 
-var genericFile = &Parser{}
+	genericFile := &Parser{}
 
-var Builtin = &Defmap{
-	Types: []*models.Type{
-		{
-			Pub:  true,
-			Id:   "byte",
-			Type: DataType{Id: jntype.U8, Kind: jntype.TypeMap[jntype.U8]},
-		},
-		{
-			Pub:  true,
-			Id:   "rune",
-			Type: DataType{Id: jntype.I32, Kind: jntype.TypeMap[jntype.I32]},
-		},
-	},
-	Funcs: []*function{
-		panicFunc,
-		recoverFunc,
-		{Ast: &Func{
-			Pub: true,
-			Id:  "print",
-			RetType: RetType{
-				Type: DataType{Id: jntype.Void, Kind: jntype.TypeMap[jntype.Void]},
+	defs := &Defmap{
+		Types: []*models.Type{
+			{
+				Pub:  true,
+				Id:   "byte",
+				Type: DataType{Id: jntype.U8, Kind: jntype.TypeMap[jntype.U8]},
 			},
-			Params: []Param{{
-				Id:   "expr",
-				Type: DataType{Id: jntype.Any, Kind: tokens.ANY},
-			}},
-		}},
-		{Ast: &Func{
-			Pub:      true,
-			Id:       "new",
-			Owner:    genericFile,
-			Generics: []*GenericType{{Id: "T"}},
-			Attributes: []Attribute{
-				models.Attribute{Tag: jn.Attribute_TypeArg},
+			{
+				Pub:  true,
+				Id:   "rune",
+				Type: DataType{Id: jntype.I32, Kind: jntype.TypeMap[jntype.I32]},
 			},
-			RetType: RetType{Type: DataType{Id: jntype.Id, Kind: tokens.STAR + "T"}},
-		}},
-		{Ast: &Func{
-			Pub:      true,
-			Id:       "make",
-			Owner:    genericFile,
-			Generics: []*GenericType{{Id: "Item"}},
-			RetType: models.RetType{
-				Type: DataType{
-					Id:            jntype.Slice,
-					Kind:          jn.Prefix_Slice + "Item",
-					ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+		},
+		Funcs: []*function{
+			panicFunc,
+			recoverFunc,
+			{Ast: &Func{
+				Pub: true,
+				Id:  "print",
+				RetType: RetType{
+					Type: DataType{Id: jntype.Void, Kind: jntype.TypeMap[jntype.Void]},
 				},
-			},
-			Params: []models.Param{
-				{
-					Id:   "n",
-					Type: DataType{Id: jntype.Int, Kind: jntype.TypeMap[jntype.Int]},
+				Params: []Param{{
+					Id:   "expr",
+					Type: DataType{Id: jntype.Any, Kind: tokens.ANY},
+				}},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "new",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "T"}},
+				Attributes: []Attribute{
+					models.Attribute{Tag: jn.Attribute_TypeArg},
 				},
-			},
-		}},
-		{Ast: &Func{
-			Pub:      true,
-			Id:       "copy",
-			Owner:    genericFile,
-			Generics: []*GenericType{{Id: "Item"}},
-			RetType: models.RetType{
-				Type: DataType{Id: jntype.Int, Kind: jntype.TypeMap[jntype.Int]},
-			},
-			Params: []models.Param{
-				{
-					Id: "dest",
+				RetType: RetType{Type: DataType{Id: jntype.Id, Kind: tokens.STAR + "T"}},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "sizeof",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "T"}},
+				RetType:  RetType{Type: DataType{Id: jntype.UInt, Kind: jntype.TypeMap[jntype.UInt]}},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "alignof",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "T"}},
+				RetType:  RetType{Type: DataType{Id: jntype.UInt, Kind: jntype.TypeMap[jntype.UInt]}},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "offsetof",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "T"}},
+				RetType:  RetType{Type: DataType{Id: jntype.UInt, Kind: jntype.TypeMap[jntype.UInt]}},
+			}},
+			{Ast: &Func{
+				Pub: true,
+				Id:  "static_assert",
+				RetType: RetType{
+					Type: DataType{Id: jntype.Void, Kind: jntype.TypeMap[jntype.Void]},
+				},
+				Params: []Param{
+					{Id: "cond", Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+					{Id: "msg", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+				},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "make",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "Item"}},
+				RetType: models.RetType{
 					Type: DataType{
 						Id:            jntype.Slice,
 						Kind:          jn.Prefix_Slice + "Item",
 						ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
 					},
 				},
-				{
-					Id: "src",
-					Type: DataType{
-						Id:            jntype.Slice,
-						Kind:          jn.Prefix_Slice + "Item",
-						ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+				Params: []models.Param{
+					{
+						Id:   "n",
+						Type: DataType{Id: jntype.Int, Kind: jntype.TypeMap[jntype.Int]},
 					},
 				},
-			},
-		}},
-		{Ast: &Func{
-			Pub:      true,
-			Id:       "append",
-			Owner:    genericFile,
-			Generics: []*GenericType{{Id: "Item"}},
-			RetType: models.RetType{
-				Type: DataType{
-					Id:            jntype.Slice,
-					Kind:          jn.Prefix_Slice + "Item",
-					ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "copy",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "Item"}},
+				RetType: models.RetType{
+					Type: DataType{Id: jntype.Int, Kind: jntype.TypeMap[jntype.Int]},
 				},
-			},
-			Params: []models.Param{
-				{
-					Id: "src",
+				Params: []models.Param{
+					{
+						Id: "dest",
+						Type: DataType{
+							Id:            jntype.Slice,
+							Kind:          jn.Prefix_Slice + "Item",
+							ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+						},
+					},
+					{
+						Id: "src",
+						Type: DataType{
+							Id:            jntype.Slice,
+							Kind:          jn.Prefix_Slice + "Item",
+							ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+						},
+					},
+				},
+			}},
+			{Ast: &Func{
+				Pub:      true,
+				Id:       "append",
+				Owner:    genericFile,
+				Generics: []*GenericType{{Id: "Item"}},
+				RetType: models.RetType{
 					Type: DataType{
 						Id:            jntype.Slice,
 						Kind:          jn.Prefix_Slice + "Item",
 						ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
 					},
 				},
-				{
-					Id:       "components",
-					Type:     DataType{Id: jntype.Id, Kind: "Item"},
-					Variadic: true,
+				Params: []models.Param{
+					{
+						Id: "src",
+						Type: DataType{
+							Id:            jntype.Slice,
+							Kind:          jn.Prefix_Slice + "Item",
+							ComponentType: &DataType{Id: jntype.Id, Kind: "Item"},
+						},
+					},
+					{
+						Id:       "components",
+						Type:     DataType{Id: jntype.Id, Kind: "Item"},
+						Variadic: true,
+					},
 				},
-			},
-		}},
-	},
-	Traits: []*trait{
-		errorTrait,
-	},
+			}},
+		},
+		Traits: []*trait{
+			errorTrait,
+		},
+	}
+	printFunc, _, _ := defs.funcById("print", nil)
+	printlnFunc := new(function)
+	*printlnFunc = *printFunc
+	printlnFunc.Ast = new(models.Func)
+	*printlnFunc.Ast = *printFunc.Ast
+	printlnFunc.Ast.Id = "println"
+	defs.Funcs = append(defs.Funcs, printlnFunc)
+	return defs
 }
 
-var strDefs = &Defmap{
-	Globals: []*Var{
-		{
-			Pub:  true,
-			Id:   "len",
-			Type: DataType{Id: jntype.Int, Kind: tokens.INT},
-			Tag:  "len()",
+func newStrDefs() *Defmap {
+	return &Defmap{
+		Globals: []*Var{
+			{
+				Pub:  true,
+				Id:   "len",
+				Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+				Tag:  "len()",
+			},
 		},
-	},
-	Funcs: []*function{
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "empty",
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "has_prefix",
-			Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "has_suffix",
-			Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "find",
-			Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Int, Kind: tokens.INT}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "rfind",
-			Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Int, Kind: tokens.INT}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "trim",
-			Params:  []Param{{Id: "bytes", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "rtrim",
-			Params:  []Param{{Id: "bytes", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
-			RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-		}},
-		{Ast: &Func{
-			Pub: true,
-			Id:  "split",
-			Params: []Param{
-				{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-				{
-					Id:   "n",
-					Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+		Funcs: []*function{
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "empty",
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "has_prefix",
+				Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "has_suffix",
+				Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "find",
+				Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Int, Kind: tokens.INT}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "rfind",
+				Params:  []Param{{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Int, Kind: tokens.INT}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "trim",
+				Params:  []Param{{Id: "bytes", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "rtrim",
+				Params:  []Param{{Id: "bytes", Type: DataType{Id: jntype.Str, Kind: tokens.STR}}},
+				RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+			}},
+			{Ast: &Func{
+				Pub: true,
+				Id:  "split",
+				Params: []Param{
+					{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+					{
+						Id:   "n",
+						Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+					},
 				},
-			},
-			RetType: RetType{Type: DataType{Id: jntype.Str, Kind: jn.Prefix_Slice + tokens.STR}},
-		}},
-		{Ast: &Func{
-			Pub: true,
-			Id:  "replace",
-			Params: []Param{
-				{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-				{Id: "new", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-				{
-					Id:   "n",
-					Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+				RetType: RetType{Type: DataType{Id: jntype.Str, Kind: jn.Prefix_Slice + tokens.STR}},
+			}},
+			{Ast: &Func{
+				Pub: true,
+				Id:  "replace",
+				Params: []Param{
+					{Id: "sub", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+					{Id: "new", Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+					{
+						Id:   "n",
+						Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+					},
 				},
-			},
-			RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
-		}},
-	},
+				RetType: RetType{Type: DataType{Id: jntype.Str, Kind: tokens.STR}},
+			}},
+		},
+	}
 }
 
-var sliceDefs = &Defmap{
-	Globals: []*Var{
-		{
-			Pub:  true,
-			Id:   "len",
-			Type: DataType{Id: jntype.Int, Kind: tokens.INT},
-			Tag:  "len()",
+func newSliceDefs() *Defmap {
+	return &Defmap{
+		Globals: []*Var{
+			{
+				Pub:  true,
+				Id:   "len",
+				Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+				Tag:  "len()",
+			},
 		},
-	},
-	Funcs: []*function{
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "empty",
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-	},
+		Funcs: []*function{
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "empty",
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+		},
+	}
 }
 
-var arrayDefs = &Defmap{
-	Globals: []*Var{
-		{
-			Pub:  true,
-			Id:   "len",
-			Type: DataType{Id: jntype.Int, Kind: tokens.INT},
-			Tag:  "len()",
+func newArrayDefs() *Defmap {
+	return &Defmap{
+		Globals: []*Var{
+			{
+				Pub:  true,
+				Id:   "len",
+				Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+				Tag:  "len()",
+			},
 		},
-	},
-	Funcs: []*function{
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "empty",
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-	},
+		Funcs: []*function{
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "empty",
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+		},
+	}
 }
 
-var mapDefs = &Defmap{
-	Globals: []*Var{
-		{
-			Pub:  true,
-			Id:   "len",
-			Type: DataType{Id: jntype.Int, Kind: tokens.INT},
-			Tag:  "len()",
+func newMapDefs() *Defmap {
+	return &Defmap{
+		Globals: []*Var{
+			{
+				Pub:  true,
+				Id:   "len",
+				Type: DataType{Id: jntype.Int, Kind: tokens.INT},
+				Tag:  "len()",
+			},
 		},
-	},
-	Funcs: []*function{
-		{Ast: &Func{
-			Pub: true,
-			Id:  "clear",
-		}},
-		{Ast: &Func{
-			Pub: true,
-			Id:  "keys",
-		}},
-		{Ast: &Func{
-			Pub: true,
-			Id:  "values",
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "empty",
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-		{Ast: &Func{
-			Pub:     true,
-			Id:      "has",
-			Params:  []Param{{Id: "key"}},
-			RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
-		}},
-		{Ast: &Func{
-			Pub:    true,
-			Id:     "del",
-			Params: []Param{{Id: "key"}},
-		}},
-	},
+		Funcs: []*function{
+			{Ast: &Func{
+				Pub: true,
+				Id:  "clear",
+			}},
+			{Ast: &Func{
+				Pub: true,
+				Id:  "keys",
+			}},
+			{Ast: &Func{
+				Pub: true,
+				Id:  "values",
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "empty",
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+			{Ast: &Func{
+				Pub:     true,
+				Id:      "has",
+				Params:  []Param{{Id: "key"}},
+				RetType: RetType{Type: DataType{Id: jntype.Bool, Kind: tokens.BOOL}},
+			}},
+			{Ast: &Func{
+				Pub:    true,
+				Id:     "del",
+				Params: []Param{{Id: "key"}},
+			}},
+		},
+	}
 }
 
-func readyMapDefs(mapt DataType) {
+func readyMapDefs(mapDefs *Defmap, mapt DataType) {
 	types := mapt.Tag.([]DataType)
 	keyt := types[0]
 	valt := types[1]
@@ -669,14 +742,6 @@ func readyMapDefs(mapt DataType) {
 }
 
 func init() {
-	printFunc, _, _ := Builtin.funcById("print", nil)
-	printlnFunc := new(function)
-	*printlnFunc = *printFunc
-	printlnFunc.Ast = new(models.Func)
-	*printlnFunc.Ast = *printFunc.Ast
-	printlnFunc.Ast.Id = "println"
-	Builtin.Funcs = append(Builtin.Funcs, printlnFunc)
-
 	intMax := intStatics.Globals[0]
 	intMin := intStatics.Globals[1]
 	uintMax := uintStatics.Globals[0]
@@ -0,0 +1,669 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnbits"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// Limits of compile-time evaluation for each call from checked source.
+const (
+	comptimeStepLimit  = 1000000
+	comptimeDepthLimit = 1000
+)
+
+// comptimeError is error of compile-time evaluation.
+// Errors are reported at call site of evaluation.
+type comptimeError struct {
+	key  string
+	args []any
+}
+
+func comptimeFail(key string, args ...any) {
+	panic(&comptimeError{key: key, args: args})
+}
+
+func comptimeNotEvaluable() {
+	comptimeFail("comptime_not_evaluable")
+}
+
+// varKey is key of local variable.
+// Variables are copied while lowering, so keyed by declaration.
+type varKey struct {
+	file   *File
+	row    int
+	column int
+	id     string
+}
+
+func keyOfVar(v *Var) varKey {
+	return varKey{v.Token.File, v.Token.Row, v.Token.Column, v.Id}
+}
+
+func keyOfParam(p *Param) varKey {
+	return varKey{p.Tok.File, p.Tok.Row, p.Tok.Column, p.Id}
+}
+
+// comptimeCtrl is control flow result of statement.
+type comptimeCtrl int
+
+const (
+	comptimeNone comptimeCtrl = iota
+	comptimeBreak
+	comptimeContinue
+	comptimeRet
+	comptimeFallthrough
+	comptimeBreakMatch
+)
+
+// comptime is compile-time evaluator of @comptime functions.
+// Values are int64, uint64, float64, bool and string.
+type comptime struct {
+	steps int
+	depth int
+}
+
+// comptimeFrame is state of evaluating function call.
+type comptimeFrame struct {
+	ct    *comptime
+	vars  map[varKey]any
+	ret   any
+	fall  *ir.Case
+	match *ir.Match
+}
+
+// comptimeCall replaces value of call with result of compile-time evaluation.
+// Call is evaluated if all arguments are constant.
+func (p *Parser) comptimeCall(f *Func, call *ir.Call, v *value, errTok Tok) {
+	if _, ok := call.Fn.(*ir.FuncRef); !ok || call.TupleArgs {
+		return
+	}
+	for _, arg := range call.Args {
+		if _, ok := arg.(*ir.Const); !ok {
+			return
+		}
+	}
+	result, err := evalComptime(f, call.Args)
+	if err != nil {
+		p.eval.pusherrtok(errTok, err.key, err.args...)
+		return
+	}
+	v.expr = result
+	v.constExpr = true
+	v.model = getModel(*v)
+}
+
+func evalComptime(f *Func, args []ir.Expr) (v any, err *comptimeError) {
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if err, ok = r.(*comptimeError); !ok {
+				panic(r)
+			}
+		}
+	}()
+	ct := new(comptime)
+	fr := ct.frame()
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = fr.eval(arg)
+	}
+	return ct.call(f, values), nil
+}
+
+// comptimeBody returns lowered body of function for compile-time evaluation.
+// Function is checked if not checked yet.
+// Returns nil if function is in checking.
+func (p *Parser) comptimeBody(f *Func) *ir.Block {
+	if b := p.comptimes[f]; b != nil {
+		return b
+	}
+	fn := p.Defs.funcByAst(f)
+	if fn == nil || fn.checking || f.Block == nil {
+		return nil
+	}
+	if !fn.checked {
+		scope, iter, c := p.scope, p.isNowIntoIter, p.currentCase
+		p.scope, p.isNowIntoIter, p.currentCase = nil, false, nil
+		p.parseFunc(fn)
+		fn.checked = true
+		p.scope, p.isNowIntoIter, p.currentCase = scope, iter, c
+	}
+	if p.comptimes == nil {
+		p.comptimes = map[*Func]*ir.Block{}
+	}
+	b := p.lowerBlock(f.Block)
+	p.comptimes[f] = b
+	return b
+}
+
+func (ct *comptime) step() {
+	ct.steps++
+	if ct.steps > comptimeStepLimit {
+		comptimeFail("comptime_step_limit", comptimeStepLimit)
+	}
+}
+
+func (ct *comptime) frame() *comptimeFrame {
+	return &comptimeFrame{ct: ct, vars: map[varKey]any{}}
+}
+
+func (ct *comptime) call(f *Func, args []any) any {
+	ct.step()
+	if f.FindAttribute(jn.Attribute_Comptime) == nil || len(args) != len(f.Params) {
+		comptimeNotEvaluable()
+	}
+	owner, _ := f.Owner.(*Parser)
+	if owner == nil {
+		comptimeNotEvaluable()
+	}
+	body := owner.comptimeBody(f)
+	if body == nil {
+		comptimeNotEvaluable()
+	}
+	ct.depth++
+	if ct.depth > comptimeDepthLimit {
+		comptimeFail("comptime_call_depth", comptimeDepthLimit)
+	}
+	fr := ct.frame()
+	for i := range f.Params {
+		param := &f.Params[i]
+		if param.Variadic {
+			comptimeNotEvaluable()
+		}
+		fr.vars[keyOfParam(param)] = comptimeConvert(args[i], param.Type)
+	}
+	if fr.block(body) != comptimeRet {
+		comptimeNotEvaluable()
+	}
+	ct.depth--
+	return comptimeConvert(fr.ret, f.RetType.Type)
+}
+
+func (fr *comptimeFrame) block(b *ir.Block) comptimeCtrl {
+	if b == nil {
+		return comptimeNone
+	}
+	for _, s := range b.Stmts {
+		if c := fr.stmt(s); c != comptimeNone {
+			return c
+		}
+	}
+	return comptimeNone
+}
+
+func (fr *comptimeFrame) stmt(s ir.Stmt) comptimeCtrl {
+	fr.ct.step()
+	switch t := s.(type) {
+	case *ir.Block:
+		return fr.block(t)
+	case *ir.Comment, *ir.Label:
+	case *ir.ExprStmt:
+		fr.eval(t.X)
+	case *ir.VarDecl:
+		if t.Init != nil {
+			fr.vars[keyOfVar(t.Var)] = comptimeConvert(fr.eval(t.Init), t.Var.Type)
+		} else {
+			fr.vars[keyOfVar(t.Var)] = comptimeZero(t.Var.Type)
+		}
+	case *ir.Assign:
+		v := fr.eval(t.Right)
+		if t.Left != nil {
+			fr.store(t.Left, t.Op, v)
+		}
+	case *ir.IncDec:
+		op := tokens.PLUS
+		if t.Op == tokens.DOUBLE_MINUS {
+			op = tokens.MINUS
+		}
+		fr.store(t.X, op+tokens.EQUAL, int64(1))
+	case *ir.If:
+		return fr.ifStmt(t)
+	case *ir.Loop:
+		return fr.loop(nil, t.Body, nil)
+	case *ir.While:
+		return fr.loop(t.Cond, t.Body, nil)
+	case *ir.For:
+		if t.Init != nil {
+			fr.stmt(t.Init)
+		}
+		return fr.loop(t.Cond, t.Body, t.Post)
+	case *ir.Match:
+		return fr.matchStmt(t)
+	case *ir.Fallthrough:
+		fr.fall = t.Case
+		return comptimeFallthrough
+	case *ir.Break:
+		if t.Match != nil {
+			fr.match = t.Match
+			return comptimeBreakMatch
+		}
+		return comptimeBreak
+	case *ir.Continue:
+		return comptimeContinue
+	case *ir.Ret:
+		if t.X == nil {
+			comptimeNotEvaluable()
+		}
+		fr.ret = fr.eval(t.X)
+		return comptimeRet
+	default:
+		comptimeNotEvaluable()
+	}
+	return comptimeNone
+}
+
+// store assigns value to local variable with assignment operator.
+func (fr *comptimeFrame) store(left ir.Expr, op string, v any) {
+	ref, ok := left.(*ir.VarRef)
+	if !ok {
+		comptimeNotEvaluable()
+	}
+	key := keyOfVar(ref.Var)
+	old, ok := fr.vars[key]
+	if !ok {
+		comptimeNotEvaluable()
+	}
+	if op != tokens.EQUAL && op != tokens.COLON+tokens.EQUAL {
+		v = comptimeArith(op[:len(op)-1], old, v, ref.Type)
+	}
+	fr.vars[key] = comptimeConvert(v, ref.Type)
+}
+
+func (fr *comptimeFrame) ifStmt(i *ir.If) comptimeCtrl {
+	for {
+		if comptimeTruth(fr.eval(i.Cond)) {
+			return fr.block(i.Then)
+		}
+		switch t := i.Else.(type) {
+		case *ir.If:
+			i = t
+		case *ir.Block:
+			return fr.block(t)
+		default:
+			return comptimeNone
+		}
+	}
+}
+
+func (fr *comptimeFrame) loop(cond ir.Expr, body *ir.Block, post ir.Stmt) comptimeCtrl {
+	for cond == nil || comptimeTruth(fr.eval(cond)) {
+		fr.ct.step()
+		switch c := fr.block(body); c {
+		case comptimeNone, comptimeContinue:
+		case comptimeBreak:
+			return comptimeNone
+		default:
+			return c
+		}
+		if post != nil {
+			fr.stmt(post)
+		}
+	}
+	return comptimeNone
+}
+
+func (fr *comptimeFrame) matchCase(m *ir.Match, x any) *ir.Case {
+	for _, c := range m.Cases {
+		for _, e := range c.Exprs {
+			v := fr.eval(e)
+			if (m.X == nil && comptimeTruth(v)) || (m.X != nil && comptimeEqual(v, x)) {
+				return c
+			}
+		}
+	}
+	return m.Default
+}
+
+func (fr *comptimeFrame) matchStmt(m *ir.Match) comptimeCtrl {
+	var x any
+	if m.X != nil {
+		x = comptimeConvert(fr.eval(m.X), m.Type)
+	}
+	c := fr.matchCase(m, x)
+	for c != nil {
+		switch ctl := fr.block(c.Body); ctl {
+		case comptimeFallthrough:
+			c = fr.fall.Next
+		case comptimeBreakMatch:
+			if fr.match != m {
+				return ctl
+			}
+			return comptimeNone
+		default:
+			return ctl
+		}
+	}
+	return comptimeNone
+}
+
+func (fr *comptimeFrame) eval(e ir.Expr) any {
+	switch t := e.(type) {
+	case *ir.Const:
+		switch v := t.Value.(type) {
+		case bool, string:
+			return v
+		case int64, uint64, float64:
+			return comptimeCast(v, t.Type.Id)
+		}
+	case *ir.VarRef:
+		if v, ok := fr.vars[keyOfVar(t.Var)]; ok {
+			return v
+		}
+	case *ir.Paren:
+		return fr.eval(t.X)
+	case *ir.Binary:
+		return fr.binary(t)
+	case *ir.Unary:
+		return fr.unary(t)
+	case *ir.Cast:
+		if comptimeIsNumeric(t.Type) {
+			return comptimeCast(fr.eval(t.X), t.Type.Id)
+		}
+	case *ir.Call:
+		return fr.call(t)
+	}
+	comptimeNotEvaluable()
+	return nil
+}
+
+func (fr *comptimeFrame) call(c *ir.Call) any {
+	ref, ok := c.Fn.(*ir.FuncRef)
+	if !ok || c.TupleArgs {
+		comptimeNotEvaluable()
+	}
+	args := make([]any, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fr.eval(arg)
+	}
+	return fr.ct.call(ref.Func, args)
+}
+
+func (fr *comptimeFrame) binary(b *ir.Binary) any {
+	switch b.Op {
+	case tokens.AND:
+		return comptimeTruth(fr.eval(b.X)) && comptimeTruth(fr.eval(b.Y))
+	case tokens.OR:
+		return comptimeTruth(fr.eval(b.X)) || comptimeTruth(fr.eval(b.Y))
+	}
+	x, y := fr.eval(b.X), fr.eval(b.Y)
+	switch b.Op {
+	case tokens.EQUALS:
+		return comptimeEqual(x, y)
+	case tokens.NOT_EQUALS:
+		return !comptimeEqual(x, y)
+	case tokens.LESS:
+		return comptimeCompare(x, y) < 0
+	case tokens.GREAT:
+		return comptimeCompare(x, y) > 0
+	case tokens.LESS_EQUAL:
+		return comptimeCompare(x, y) <= 0
+	case tokens.GREAT_EQUAL:
+		return comptimeCompare(x, y) >= 0
+	}
+	return comptimeArith(b.Op, x, y, b.Type)
+}
+
+func (fr *comptimeFrame) unary(u *ir.Unary) any {
+	x := fr.eval(u.X)
+	switch u.Op {
+	case tokens.PLUS:
+		return x
+	case tokens.EXCLAMATION:
+		return !comptimeTruth(x)
+	case tokens.MINUS:
+		if f, ok := x.(float64); ok {
+			return comptimeCast(-f, u.Type.Id)
+		}
+		return comptimeFromBig(new(big.Int).Neg(comptimeBig(x)), u.Type.Id)
+	case tokens.CARET:
+		if jntype.IsSignedInteger(u.Type.Id) {
+			return comptimeCast(^tonums(x), u.Type.Id)
+		} else if jntype.IsUnsignedInteger(u.Type.Id) {
+			return comptimeCast(^tonumu(x), u.Type.Id)
+		}
+	}
+	comptimeNotEvaluable()
+	return nil
+}
+
+func comptimeTruth(v any) bool {
+	b, ok := v.(bool)
+	if !ok {
+		comptimeNotEvaluable()
+	}
+	return b
+}
+
+func comptimeIsNumeric(t DataType) bool {
+	return typeIsPure(t) && !t.MultiTyped && jntype.IsNumeric(t.Id)
+}
+
+// comptimeBig returns integer value as big integer.
+func comptimeBig(v any) *big.Int {
+	switch t := v.(type) {
+	case int64:
+		return big.NewInt(t)
+	case uint64:
+		return new(big.Int).SetUint64(t)
+	case float64:
+		return big.NewInt(int64(t))
+	}
+	comptimeNotEvaluable()
+	return nil
+}
+
+// comptimeFromBig returns big integer as value of integer type.
+// Values that not fits into type are overflows.
+func comptimeFromBig(r *big.Int, id uint8) any {
+	id = jntype.GetRealCode(id)
+	switch {
+	case jntype.IsSignedInteger(id):
+		if r.IsInt64() {
+			x := r.Int64()
+			if x >= jntype.MinOfType(id) && x <= int64(jntype.MaxOfType(id)) {
+				return x
+			}
+		}
+	case jntype.IsUnsignedInteger(id):
+		if r.IsUint64() && r.Uint64() <= jntype.MaxOfType(id) {
+			return r.Uint64()
+		}
+	case jntype.IsFloat(id):
+		f, _ := new(big.Float).SetInt(r).Float64()
+		return comptimeCast(f, id)
+	default:
+		comptimeNotEvaluable()
+	}
+	comptimeFail("overflow_limits")
+	return nil
+}
+
+// comptimeCast returns numeric value as numeric type like casting.
+func comptimeCast(v any, id uint8) any {
+	id = jntype.GetRealCode(id)
+	switch {
+	case jntype.IsFloat(id):
+		if id == jntype.F32 {
+			return float64(float32(tonumf(v)))
+		}
+		return tonumf(v)
+	case jntype.IsSignedInteger(id):
+		shift := 64 - jnbits.BitsizeType(id)
+		return tonums(v) << shift >> shift
+	case jntype.IsUnsignedInteger(id):
+		shift := 64 - jnbits.BitsizeType(id)
+		return tonumu(v) << shift >> shift
+	}
+	comptimeNotEvaluable()
+	return nil
+}
+
+// comptimeConvert returns value for storing into location of type.
+func comptimeConvert(v any, t DataType) any {
+	if !typeIsPure(t) || t.MultiTyped {
+		comptimeNotEvaluable()
+	}
+	switch id := jntype.GetRealCode(t.Id); {
+	case id == jntype.Bool:
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	case id == jntype.Str:
+		if s, ok := v.(string); ok {
+			return s
+		}
+	case jntype.IsFloat(id):
+		f := tonumf(v)
+		if id == jntype.F32 && math.Abs(f) > math.MaxFloat32 {
+			comptimeFail("overflow_limits")
+		}
+		return comptimeCast(v, id)
+	case jntype.IsInteger(id):
+		if _, ok := v.(float64); ok {
+			comptimeNotEvaluable()
+		}
+		return comptimeFromBig(comptimeBig(v), id)
+	}
+	comptimeNotEvaluable()
+	return nil
+}
+
+func comptimeZero(t DataType) any {
+	switch id := jntype.GetRealCode(t.Id); {
+	case id == jntype.Bool:
+		return false
+	case id == jntype.Str:
+		return ""
+	}
+	return comptimeConvert(int64(0), t)
+}
+
+// comptimeArith returns result of arithmetic operation as type.
+func comptimeArith(op string, x, y any, t DataType) any {
+	if s, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok || op != tokens.PLUS {
+			comptimeNotEvaluable()
+		}
+		return s + ys
+	}
+	id := jntype.GetRealCode(t.Id)
+	if jntype.IsFloat(id) {
+		a, b := tonumf(x), tonumf(y)
+		var r float64
+		switch op {
+		case tokens.PLUS:
+			r = a + b
+		case tokens.MINUS:
+			r = a - b
+		case tokens.STAR:
+			r = a * b
+		case tokens.SOLIDUS:
+			if b == 0 {
+				comptimeFail("divide_by_zero")
+			}
+			r = a / b
+		default:
+			comptimeNotEvaluable()
+		}
+		return comptimeConvert(r, t)
+	}
+	a, b := comptimeBig(x), comptimeBig(y)
+	r := new(big.Int)
+	switch op {
+	case tokens.PLUS:
+		r.Add(a, b)
+	case tokens.MINUS:
+		r.Sub(a, b)
+	case tokens.STAR:
+		r.Mul(a, b)
+	case tokens.SOLIDUS, tokens.PERCENT:
+		if b.Sign() == 0 {
+			comptimeFail("divide_by_zero")
+		}
+		if op == tokens.SOLIDUS {
+			r.Quo(a, b)
+		} else {
+			r.Rem(a, b)
+		}
+	case tokens.AMPER:
+		r.And(a, b)
+	case tokens.VLINE:
+		r.Or(a, b)
+	case tokens.CARET:
+		r.Xor(a, b)
+	case tokens.LSHIFT, tokens.RSHIFT:
+		if b.Sign() < 0 {
+			comptimeFail("overflow_limits")
+		}
+		n := uint(64)
+		if b.IsUint64() && b.Uint64() < 64 {
+			n = uint(b.Uint64())
+		}
+		if op == tokens.LSHIFT {
+			r.Lsh(a, n)
+		} else {
+			r.Rsh(a, n)
+		}
+	default:
+		comptimeNotEvaluable()
+	}
+	return comptimeFromBig(r, id)
+}
+
+func comptimeCompare(x, y any) int {
+	if s, ok := x.(string); ok {
+		ys, ok := y.(string)
+		if !ok {
+			comptimeNotEvaluable()
+		}
+		return strings.Compare(s, ys)
+	}
+	_, xf := x.(float64)
+	_, yf := y.(float64)
+	if xf || yf {
+		a, b := tonumf(x), tonumf(y)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	return comptimeBig(x).Cmp(comptimeBig(y))
+}
+
+func comptimeEqual(x, y any) bool {
+	if b, ok := x.(bool); ok {
+		return b == comptimeTruth(y)
+	}
+	return comptimeCompare(x, y) == 0
+}
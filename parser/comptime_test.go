@@ -0,0 +1,160 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/ir"
+)
+
+// mainDecls returns variable declarations of entry point.
+func mainDecls(t *testing.T, p *Parser) map[string]*ir.VarDecl {
+	t.Helper()
+	decls := map[string]*ir.VarDecl{}
+	for _, f := range p.IR().Funcs {
+		if !f.Entry {
+			continue
+		}
+		for _, s := range f.Body.Stmts {
+			if d, ok := s.(*ir.VarDecl); ok {
+				decls[d.Var.Id] = d
+			}
+		}
+		return decls
+	}
+	t.Fatal("entry point is not found")
+	return nil
+}
+
+func TestComptimeFolding(t *testing.T) {
+	p := parseSource(t, `@comptime
+fact(n int) int {
+	if n <= 1 {
+		ret 1
+	}
+	ret n * fact(n - 1)
+}
+
+@comptime
+sum(n int) int {
+	total: = 0
+	for i: = 1; i <= n; i++ {
+		total += i
+	}
+	ret total
+}
+
+@comptime
+even(n int) bool {
+	ret n%2 == 0
+}
+
+main() {
+	a: = fact(5)
+	b: = sum(100)
+	c: = even(7)
+	println(a)
+	println(b)
+	println(c)
+}
+`)
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", logKeys(p.Errors))
+	}
+	decls := mainDecls(t, p)
+	for id, want := range map[string]any{"a": int64(120), "b": int64(5050), "c": false} {
+		c, ok := decls[id].Init.(*ir.Const)
+		if !ok {
+			t.Errorf("%s is not folded: %T", id, decls[id].Init)
+			continue
+		}
+		if c.Value != want {
+			t.Errorf("%s = %#v, want %#v", id, c.Value, want)
+		}
+	}
+}
+
+func TestComptimeLimits(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "step limit",
+			src: `@comptime
+spin(n int) int {
+	for {
+		n++
+	}
+	ret n
+}
+
+main() {
+	x: = spin(1)
+	println(x)
+}
+`,
+			want: []string{"comptime_step_limit"},
+		},
+		{
+			name: "depth limit",
+			src: `@comptime
+deep(n int) int {
+	ret deep(n + 1)
+}
+
+main() {
+	x: = deep(0)
+	println(x)
+}
+`,
+			want: []string{"comptime_call_depth"},
+		},
+		{
+			name: "depth below limit",
+			src: `@comptime
+down(n int) int {
+	if n == 0 {
+		ret 0
+	}
+	ret down(n - 1)
+}
+
+main() {
+	x: = down(999)
+	println(x)
+}
+`,
+			want: []string{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := parseSource(t, c.src)
+			if got := logKeys(p.Errors); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("errors %v, want %v", got, c.want)
+			}
+		})
+	}
+}
@@ -21,7 +21,6 @@
 package parser
 
 import (
-	"github.com/DeRuneLabs/jane/package/jnio"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
 
@@ -38,15 +37,118 @@ type Defmap struct {
 	Funcs      []*function
 	Globals    []*Var
 	side       *Defmap
+	index      defIndex
 }
 
-func (dm *Defmap) findNsById(id string) int {
-	for i, t := range dm.Namespaces {
-		if t != nil && t.Id == id {
-			return i
+// defIndex is hashed index of defines by identifier.
+// it is built on demand and follows appends to define lists.
+//
+// index is updated by lookups, so it is not safe for concurrent use.
+// defines that shared between goroutines are indexed by buildIndex
+// before shared, and lookups of them only read index.
+type defIndex struct {
+	namespaces idIndex
+	enums      idIndex
+	structs    idIndex
+	traits     idIndex
+	types      idIndex
+	funcs      idIndex
+	globals    idIndex
+}
+
+// idIndex maps identifiers to positions in a define list.
+type idIndex struct {
+	ids  map[string][]int
+	base any // pointer to first item of indexed list
+	n    int // count of indexed items
+}
+
+// updateIndex indexes items of list that not indexed yet.
+// idOf returns identifier of item.
+// index is rebuilt if list is shrinked or reallocated.
+// index is not changed if list is already indexed.
+func updateIndex[T any](x *idIndex, list []*T, idOf func(*T) string) {
+	var base any
+	if len(list) > 0 {
+		base = &list[0]
+	}
+	if len(list) < x.n || base != x.base {
+		x.ids = nil
+		x.base = base
+		x.n = 0
+	}
+	// items are indexed until first nil item,
+	// nil items are filled after allocation of list
+	for ; x.n < len(list); x.n++ {
+		item := list[x.n]
+		if item == nil {
+			break
+		}
+		if x.ids == nil {
+			x.ids = map[string][]int{}
 		}
+		itemId := idOf(item)
+		x.ids[itemId] = append(x.ids[itemId], x.n)
 	}
-	return -1
+}
+
+// indexPositions returns positions of items of list that have identifier id.
+func indexPositions[T any](x *idIndex, list []*T, id string, idOf func(*T) string) []int {
+	updateIndex(x, list, idOf)
+	positions := x.ids[id]
+	for i := x.n; i < len(list); i++ {
+		if item := list[i]; item != nil && idOf(item) == id {
+			positions = append(positions[:len(positions):len(positions)], i)
+		}
+	}
+	return positions
+}
+
+// buildIndex indexes all defines and defines of namespaces,
+// structures and traits. defines must not be changed after
+// index is built if defmap is shared between goroutines.
+func (dm *Defmap) buildIndex() {
+	updateIndex(&dm.index.namespaces, dm.Namespaces, nsId)
+	updateIndex(&dm.index.enums, dm.Enums, enumId)
+	updateIndex(&dm.index.structs, dm.Structs, structId)
+	updateIndex(&dm.index.traits, dm.Traits, traitId)
+	updateIndex(&dm.index.types, dm.Types, typeId)
+	updateIndex(&dm.index.funcs, dm.Funcs, funcId)
+	updateIndex(&dm.index.globals, dm.Globals, globalId)
+	for _, ns := range dm.Namespaces {
+		if ns.defs != nil {
+			ns.defs.buildIndex()
+		}
+	}
+	for _, s := range dm.Structs {
+		if s.Defs != nil {
+			s.Defs.buildIndex()
+		}
+	}
+	for _, t := range dm.Traits {
+		if t.Defs != nil {
+			t.Defs.buildIndex()
+		}
+	}
+	if dm.side != nil {
+		dm.side.buildIndex()
+	}
+}
+
+func nsId(ns *namespace) string   { return ns.Id }
+func enumId(e *Enum) string       { return e.Id }
+func structId(s *jnstruct) string { return s.Ast.Id }
+func traitId(t *trait) string     { return t.Ast.Id }
+func typeId(t *Type) string       { return t.Id }
+func funcId(f *function) string   { return f.Ast.Id }
+func globalId(v *Var) string      { return v.Id }
+
+func (dm *Defmap) findNsById(id string) int {
+	positions := indexPositions(&dm.index.namespaces, dm.Namespaces, id, nsId)
+	if len(positions) == 0 {
+		return -1
+	}
+	return positions[0]
 }
 
 func (dm *Defmap) nsById(id string) *namespace {
@@ -58,11 +160,10 @@ func (dm *Defmap) nsById(id string) *namespace {
 }
 
 func (dm *Defmap) findStructById(id string, f *File) (int, *Defmap, bool) {
-	for i, s := range dm.Structs {
-		if s != nil && s.Ast.Id == id {
-			if isAccessable(f, s.Ast.Tok.File, s.Ast.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.structs, dm.Structs, id, structId) {
+		s := dm.Structs[i]
+		if isAccessable(f, s.Ast.Tok.File, s.Ast.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -81,11 +182,10 @@ func (dm *Defmap) structById(id string, f *File) (*jnstruct, *Defmap, bool) {
 }
 
 func (dm *Defmap) findTraitById(id string, f *File) (int, *Defmap, bool) {
-	for i, t := range dm.Traits {
-		if t != nil && t.Ast.Id == id {
-			if isAccessable(f, t.Ast.Tok.File, t.Ast.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.traits, dm.Traits, id, traitId) {
+		t := dm.Traits[i]
+		if isAccessable(f, t.Ast.Tok.File, t.Ast.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -104,11 +204,10 @@ func (dm *Defmap) traitById(id string, f *File) (*trait, *Defmap, bool) {
 }
 
 func (dm *Defmap) findEnumById(id string, f *File) (int, *Defmap, bool) {
-	for i, e := range dm.Enums {
-		if e != nil && e.Id == id {
-			if isAccessable(f, e.Tok.File, e.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.enums, dm.Enums, id, enumId) {
+		e := dm.Enums[i]
+		if isAccessable(f, e.Tok.File, e.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -127,11 +226,10 @@ func (dm *Defmap) enumById(id string, f *File) (*Enum, *Defmap, bool) {
 }
 
 func (dm *Defmap) findTypeById(id string, f *File) (int, *Defmap, bool) {
-	for i, t := range dm.Types {
-		if t != nil && t.Id == id {
-			if isAccessable(f, t.Tok.File, t.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.types, dm.Types, id, typeId) {
+		t := dm.Types[i]
+		if isAccessable(f, t.Tok.File, t.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -150,11 +248,10 @@ func (dm *Defmap) typeById(id string, f *File) (*Type, *Defmap, bool) {
 }
 
 func (dm *Defmap) findFuncById(id string, f *File) (int, *Defmap, bool) {
-	for i, fn := range dm.Funcs {
-		if fn != nil && fn.Ast.Id == id {
-			if isAccessable(f, fn.Ast.Tok.File, fn.Ast.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.funcs, dm.Funcs, id, funcId) {
+		fn := dm.Funcs[i]
+		if isAccessable(f, fn.Ast.Tok.File, fn.Ast.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -172,12 +269,23 @@ func (dm *Defmap) funcById(id string, f *File) (*function, *Defmap, bool) {
 	return m.Funcs[i], m, canshadow
 }
 
+func (dm *Defmap) funcByAst(f *Func) *function {
+	for _, i := range indexPositions(&dm.index.funcs, dm.Funcs, f.Id, funcId) {
+		if fn := dm.Funcs[i]; fn.Ast == f {
+			return fn
+		}
+	}
+	if dm.side != nil {
+		return dm.side.funcByAst(f)
+	}
+	return nil
+}
+
 func (dm *Defmap) findGlobalById(id string, f *File) (int, *Defmap, bool) {
-	for i, g := range dm.Globals {
-		if g != nil && g.Type.Id != jntype.Void && g.Id == id {
-			if isAccessable(f, g.Token.File, g.Pub) {
-				return i, dm, false
-			}
+	for _, i := range indexPositions(&dm.index.globals, dm.Globals, id, globalId) {
+		g := dm.Globals[i]
+		if g.Type.Id != jntype.Void && isAccessable(f, g.Token.File, g.Pub) {
+			return i, dm, false
 		}
 	}
 	if dm.side != nil {
@@ -195,19 +303,25 @@ func (dm *Defmap) globalById(id string, f *File) (*Var, *Defmap, bool) {
 	return m.Globals[i], m, canshadow
 }
 
+// findById returns define by identifier.
+// defines are searched by order of globals, functions, enums,
+// structures, types and traits.
 func (dm *Defmap) findById(id string, f *File) (int, *Defmap, byte) {
-	finders := map[byte]func(string, *jnio.File) (int, *Defmap, bool){
-		'g': dm.findGlobalById,
-		'f': dm.findFuncById,
-		'e': dm.findEnumById,
-		's': dm.findStructById,
-		't': dm.findTypeById,
-		'i': dm.findTraitById,
-	}
-	for code, finder := range finders {
-		i, m, _ := finder(id, f)
+	finders := [...]struct {
+		code   byte
+		finder func(string, *File) (int, *Defmap, bool)
+	}{
+		{'g', dm.findGlobalById},
+		{'f', dm.findFuncById},
+		{'e', dm.findEnumById},
+		{'s', dm.findStructById},
+		{'t', dm.findTypeById},
+		{'i', dm.findTraitById},
+	}
+	for _, finder := range finders {
+		i, m, _ := finder.finder(id, f)
 		if i != -1 {
-			return i, m, code
+			return i, m, finder.code
 		}
 	}
 	return -1, nil, ' '
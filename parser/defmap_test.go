@@ -0,0 +1,153 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+const benchDefs = 4096
+
+func benchDefmap() (*Defmap, []string) {
+	f := &File{Dir: "bench", Name: "bench.jn"}
+	dm := new(Defmap)
+	ids := make([]string, benchDefs)
+	for i := range ids {
+		id := "def" + strconv.Itoa(i)
+		ids[i] = id
+		tok := Tok{File: f}
+		dm.Funcs = append(dm.Funcs, &function{Ast: &models.Func{Id: id, Tok: tok}})
+		dm.Structs = append(dm.Structs, &jnstruct{Ast: models.Struct{Id: "S" + id, Tok: tok}})
+		dm.Globals = append(dm.Globals, &Var{Id: "g" + id, Token: tok, Type: DataType{Id: jntype.Int}})
+	}
+	return dm, ids
+}
+
+// linearFuncById is linear scan lookup that index is replaced.
+func linearFuncById(dm *Defmap, id string, f *File) *function {
+	for _, fn := range dm.Funcs {
+		if fn.Ast.Id == id && isAccessable(f, fn.Ast.Tok.File, fn.Ast.Pub) {
+			return fn
+		}
+	}
+	return nil
+}
+
+// TestDefmapSharedLookup looks up built index from goroutines,
+// lookups must not write index that checked by race detector.
+func TestDefmapSharedLookup(t *testing.T) {
+	dm, ids := benchDefmap()
+	dm.buildIndex()
+	f := dm.Funcs[0].Ast.Tok.File
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(ids); i += 4 {
+				if fn, _, _ := dm.funcById(ids[i], f); fn == nil || fn.Ast.Id != ids[i] {
+					t.Errorf("function is not found: %s", ids[i])
+				}
+				if s, _, _ := dm.structById("S"+ids[i], f); s == nil {
+					t.Errorf("struct is not found: S%s", ids[i])
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func BenchmarkDefmapFuncById(b *testing.B) {
+	dm, ids := benchDefmap()
+	f := dm.Funcs[0].Ast.Tok.File
+	// index is built by first lookup
+	dm.funcById(ids[0], f)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if fn, _, _ := dm.funcById(ids[i%len(ids)], f); fn == nil {
+			b.Fatal("function is not found")
+		}
+	}
+}
+
+func BenchmarkDefmapFuncByIdLinear(b *testing.B) {
+	dm, ids := benchDefmap()
+	f := dm.Funcs[0].Ast.Tok.File
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if linearFuncById(dm, ids[i%len(ids)], f) == nil {
+			b.Fatal("function is not found")
+		}
+	}
+}
+
+// BenchmarkDefById looks up globals, that is the last
+// step of identifier lookup after types, structs and functions.
+func BenchmarkDefById(b *testing.B) {
+	dm, ids := benchDefmap()
+	p := New(dm.Funcs[0].Ast.Tok.File)
+	p.Defs = dm
+	p.scope = newScope(nil, scopeBlock)
+	p.defById(ids[0])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if def, _, _ := p.defById("g" + ids[i%len(ids)]); def == nil {
+			b.Fatal("global is not found")
+		}
+	}
+}
+
+// BenchmarkStdPackages parses and checks each package of standard library.
+func BenchmarkStdPackages(b *testing.B) {
+	std, err := filepath.Abs(filepath.Join("..", "std"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var pkgs []string
+	err = fs.WalkDir(jnio.OS, std, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != std {
+			pkgs = append(pkgs, path)
+		}
+		return err
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, path := range pkgs {
+		name, _ := filepath.Rel(std, path)
+		b.Run(filepath.ToSlash(name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				env := NewEnv(jnio.OS, std, nil)
+				if pkg, _ := env.load(nil, path); pkg.defs == nil && len(pkg.errors) == 0 {
+					b.Fatal("package is not parsed")
+				}
+			}
+		})
+	}
+}
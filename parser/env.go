@@ -0,0 +1,150 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnset"
+)
+
+// Env is environment of compilation.
+// parser and its subparsers shares same environment.
+type Env struct {
+	// FS is file system of sources and standard library.
+	FS fs.FS
+	// StdlibPath is path of standard library in FS.
+	StdlibPath string
+	// Set is settings of compilation.
+	Set *jnset.JnSet
+
+	mutex    sync.Mutex
+	packages map[string]*usePackage
+	// checking serializes checks of packages, because checks
+	// update definitions of used packages such as generic combinations.
+	checking  sync.Mutex
+	workers   chan struct{}
+	builtin   *Defmap
+	strDefs   *Defmap
+	sliceDefs *Defmap
+	arrayDefs *Defmap
+	mapDefs   *Defmap
+}
+
+// NewEnv returns new environment.
+// set is default settings if nil.
+func NewEnv(fsys fs.FS, stdlib string, set *jnset.JnSet) *Env {
+	if set == nil {
+		set = jnset.Default
+	}
+	env := &Env{
+		FS:         fsys,
+		StdlibPath: stdlib,
+		Set:        set,
+		workers:    make(chan struct{}, runtime.GOMAXPROCS(0)),
+		builtin:    newBuiltin(),
+		strDefs:    newStrDefs(),
+		sliceDefs:  newSliceDefs(),
+		arrayDefs:  newArrayDefs(),
+		mapDefs:    newMapDefs(),
+	}
+	// built-in defines are shared by parsers of goroutines
+	env.builtin.buildIndex()
+	env.strDefs.buildIndex()
+	env.sliceDefs.buildIndex()
+	env.arrayDefs.buildIndex()
+	env.mapDefs.buildIndex()
+	return env
+}
+
+// DefaultEnv returns new environment of operating system
+// with global paths and settings.
+func DefaultEnv() *Env {
+	return NewEnv(jnio.OS, jn.StdlibPath, jn.Set)
+}
+
+func (env *Env) recoverFunc() *function {
+	f, _, _ := env.builtin.funcById("recover", nil)
+	return f
+}
+
+// isBuiltinFunc reports whether f is built-in function of identifier.
+func (env *Env) isBuiltinFunc(f *Func, id string) bool {
+	bf, _, _ := env.builtin.funcById(id, nil)
+	return bf != nil && bf.Ast == f
+}
+
+func fsPath(path string) string {
+	return filepath.Clean(path)
+}
+
+func (env *Env) stat(path string) (fs.FileInfo, error) {
+	return fs.Stat(env.FS, fsPath(path))
+}
+
+// sourceFiles returns useable source files of directory.
+func (env *Env) sourceFiles(dir string) ([]string, error) {
+	entries, err := fs.ReadDir(env.FS, fsPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() ||
+			!strings.HasSuffix(name, jn.SrcExt) ||
+			!jnio.IsUseable(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (env *Env) open(path string) (*File, error) {
+	return jnio.ReadJn(env.FS, fsPath(path))
+}
+
+// StdPackages returns link strings of all packages of standard library.
+func (env *Env) StdPackages() []string {
+	var pkgs []string
+	root := fsPath(env.StdlibPath)
+	_ = fs.WalkDir(env.FS, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		pkgs = append(pkgs, jn.Stdlib+tokens.DOUBLE_COLON+strings.Join(parts, tokens.DOUBLE_COLON))
+		return nil
+	})
+	return pkgs
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// TestEnvStdlibPath uses package from standard library of environment,
+// global standard library path is not set by tests.
+func TestEnvStdlibPath(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":                {Data: []byte("use std::greet::*\n\nmain() {\n\thello()\n}\n")},
+		"lib/std/greet/greet.jn": {Data: []byte("pub hello() {}\n")},
+	}
+	p := parseFS(t, fsys, "lib/std")
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	uses := p.used()
+	if len(uses) != 1 || uses[0].Path != "lib/std/greet" {
+		t.Fatalf("used packages are not from environment: %v", uses)
+	}
+}
@@ -1,19 +1,17 @@
 package parser
 
 import (
-	"strconv"
-
 	"github.com/DeRuneLabs/jane/ast"
 	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jn"
-	"github.com/DeRuneLabs/jane/package/jnapi"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
 
 type value struct {
 	data      models.Data
-	model     iExpr
+	model     ir.Expr
 	expr      any
 	constExpr bool
 	heapMust  bool
@@ -23,10 +21,6 @@ type value struct {
 	isField   bool
 }
 
-func isOperator(process Toks) bool {
-	return len(process) == 1 && process[0].Id == tokens.Operator
-}
-
 type eval struct {
 	p        *Parser
 	hasError bool
@@ -40,132 +34,116 @@ func (e *eval) pusherrtok(tok Tok, err string, args ...any) {
 	e.p.pusherrtok(tok, err, args...)
 }
 
-func (e *eval) toks(toks Toks) (value, iExpr) {
+func (e *eval) toks(toks Toks) (value, ir.Expr) {
 	return e.expr(new(ast.Builder).Expr(toks))
 }
 
-func (e *eval) expr(expr Expr) (value, iExpr) {
-	processes := make([]Toks, len(expr.Processes))
-	copy(processes, expr.Processes)
-	return e.processes(processes)
+func (e *eval) expr(expr Expr) (value, ir.Expr) {
+	return e.tree(expr.Tree)
 }
 
-func (e *eval) processes(processes []Toks) (v value, model iExpr) {
+type operand struct {
+	v     value
+	model ir.Expr
+}
+
+func (e *eval) tree(root models.ExprNode) (v value, model ir.Expr) {
 	defer func() {
 		if typeIsVoid(v.data.Type) {
 			v.data.Type.Id = jntype.Void
 			v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 		}
 	}()
-	if processes == nil || e.hasError {
+	if root == nil || e.hasError {
 		return
 	}
-	if len(processes) == 1 {
-		m := newExprModel(processes)
-		model = m
-		v = e.process(processes[0], m)
-		if v.constExpr {
-			model = v.model
+	switch t := root.(type) {
+	case *models.BadExpr:
+		if t.Tok.Id == tokens.NA {
+			return
 		}
-		return
+	case *models.BinaryExpr:
+		return e.binaryTree(t)
 	}
-	valProcesses := make([]any, len(processes))
+	v = e.node(root)
+	model = v.model
+	return
+}
+
+func (e *eval) binaryTree(root *models.BinaryExpr) (v value, model ir.Expr) {
+	var operands []operand
 	hasError := e.hasError
-	for i, process := range processes {
-		if isOperator(process) {
-			valProcesses[i] = nil
-			continue
-		}
-		val, model := e.p.evalToks(process)
-		hasError = hasError || e.hasError
-		valProcesses[i] = []any{val, model}
-	}
+	e.operands(root, &operands, &hasError)
 	if hasError {
 		e.hasError = true
 		return
 	}
-	return e.valProcesses(valProcesses, processes)
+	if !e.checkOperators(root) {
+		return
+	}
+	i := 0
+	v, model = e.binary(root, operands, &i)
+	v.lvalue = typeIsLvalue(v.data.Type)
+	v.model = model
+	return
 }
 
-func (e *eval) valProcesses(exprs []any, processes []Toks) (v value, model iExpr) {
-	switch len(exprs) {
-	case 0:
-		v.data.Type.Id = jntype.Void
-		v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
-		return
-	case 1:
-		expr := exprs[0].([]any)
-		v, model = expr[0].(value), expr[1].(iExpr)
-		v.lvalue = typeIsLvalue(v.data.Type)
-		if v.constExpr {
-			model = v.model
-		}
+// operands evaluates operands of binary expression from left to right.
+func (e *eval) operands(n models.ExprNode, operands *[]operand, hasError *bool) {
+	if b, ok := n.(*models.BinaryExpr); ok {
+		e.operands(b.Left, operands, hasError)
+		e.operands(b.Right, operands, hasError)
 		return
 	}
-	i := e.nextOperator(processes)
+	e.hasError = false
+	v, model := e.tree(n)
+	*hasError = *hasError || e.hasError
+	*operands = append(*operands, operand{v, model})
+}
+
+func (e *eval) checkOperators(n models.ExprNode) bool {
+	b, ok := n.(*models.BinaryExpr)
+	if !ok {
+		return true
+	}
+	if !e.checkOperators(b.Left) {
+		return false
+	}
+	if ast.BinaryPrecedence(b.Op.Kind) == 0 {
+		e.pusherrtok(b.Op, "invalid_operator")
+		return false
+	}
+	return e.checkOperators(b.Right)
+}
+
+func (e *eval) binary(n models.ExprNode, operands []operand, i *int) (value, ir.Expr) {
+	b, ok := n.(*models.BinaryExpr)
+	if !ok {
+		operand := operands[*i]
+		*i++
+		return operand.v, operand.model
+	}
+	leftV, leftExpr := e.binary(b.Left, operands, i)
+	rightV, rightExpr := e.binary(b.Right, operands, i)
 	process := solver{p: e.p}
-	process.operator = processes[i][0]
-	left := exprs[i-1].([]any)
-	leftV, leftExpr := left[0].(value), left[1].(iExpr)
-	right := exprs[i+1].([]any)
-	rightV, rightExpr := right[0].(value), right[1].(iExpr)
-	process.left = processes[i-1]
+	process.operator = b.Op
 	process.leftVal = leftV
-	process.right = processes[i+1]
 	process.rightVal = rightV
 	val := process.solve()
-	var expr iExpr
 	if val.constExpr {
-		expr = val.model
-	} else {
-		sexpr := serieExpr{}
-		sexpr.exprs = make([]any, 5)
-		sexpr.exprs[0] = exprNode{tokens.LPARENTHESES}
-		sexpr.exprs[1] = leftExpr
-		sexpr.exprs[2] = exprNode{process.operator.Kind}
-		sexpr.exprs[3] = rightExpr
-		sexpr.exprs[4] = exprNode{tokens.RPARENTHESES}
-		expr = sexpr
-	}
-	processes = append(processes[:i-1], append([]Toks{{}}, processes[i+2:]...)...)
-	exprs = append(exprs[:i-1], append([]any{[]any{val, expr}}, exprs[i+2:]...)...)
-	return e.valProcesses(exprs, processes)
-}
-
-func (e *eval) nextOperator(processes []Toks) int {
-	prec := precedencer{}
-	for i, process := range processes {
-		switch {
-		case !isOperator(process),
-			processes[i-1] == nil && processes[i+1] == nil:
-			continue
-		}
-		switch process[0].Kind {
-		case tokens.STAR, tokens.PERCENT, tokens.SOLIDUS,
-			tokens.RSHIFT, tokens.LSHIFT, tokens.AMPER:
-			prec.set(5, i)
-		case tokens.PLUS, tokens.MINUS, tokens.VLINE, tokens.CARET:
-			prec.set(4, i)
-		case tokens.EQUALS, tokens.NOT_EQUALS, tokens.LESS,
-			tokens.LESS_EQUAL, tokens.GREAT, tokens.GREAT_EQUAL:
-			prec.set(3, i)
-		case tokens.AND:
-			prec.set(2, i)
-		case tokens.OR:
-			prec.set(1, i)
-		default:
-			e.pusherrtok(process[0], "invalid_operator")
-		}
+		return val, val.model
 	}
-	data := prec.get()
-	if data == nil {
-		return -1
+	model := &ir.Binary{
+		Type: val.data.Type,
+		Op:   process.operator.Kind,
+		X:    leftExpr,
+		Y:    rightExpr,
 	}
-	return data.(int)
+	return val, model
 }
 
-func (e *eval) single(tok Tok, m *exprModel) (v value, ok bool) {
-	eval := valueEvaluator{tok, m, e.p}
+func (e *eval) single(tok Tok) (v value, ok bool) {
+	eval := valueEvaluator{tok, e.p}
 	v.data.Type.Id = jntype.Void
 	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 	v.data.Tok = tok
@@ -192,32 +170,21 @@ func (e *eval) single(tok Tok, m *exprModel) (v value, ok bool) {
 	return
 }
 
-func (e *eval) unary(toks Toks, m *exprModel) value {
+func (e *eval) unary(n *models.UnaryExpr) value {
 	var v value
-	exprToks := toks[1:]
-	processor := unary{toks[0], exprToks, m, e.p}
-	if processor.toks == nil {
-		e.pusherrtok(processor.tok, "invalid_syntax")
-		return v
-	}
+	processor := unary{n.Op, n.Expr, e.p}
 	switch processor.tok.Kind {
 	case tokens.MINUS:
-		m.appendSubNode(exprNode{processor.tok.Kind})
 		v = processor.minus()
 	case tokens.PLUS:
-		m.appendSubNode(exprNode{processor.tok.Kind})
 		v = processor.plus()
 	case tokens.CARET:
-		m.appendSubNode(exprNode{"~"})
 		v = processor.caret()
 	case tokens.EXCLAMATION:
-		m.appendSubNode(exprNode{processor.tok.Kind})
 		v = processor.logicalNot()
 	case tokens.STAR:
-		m.appendSubNode(exprNode{processor.tok.Kind})
 		v = processor.star()
 	case tokens.AMPER:
-		m.appendSubNode(exprNode{processor.tok.Kind})
 		v = processor.amper()
 	default:
 		e.pusherrtok(processor.tok, "invalid_syntax")
@@ -226,28 +193,37 @@ func (e *eval) unary(toks Toks, m *exprModel) value {
 	return v
 }
 
-func (e *eval) betweenParentheses(toks Toks, m *exprModel) value {
-	m.appendSubNode(exprNode{tokens.LPARENTHESES})
-	defer m.appendSubNode(exprNode{tokens.RPARENTHESES})
-
-	tk := toks[0]
-	toks = toks[1 : len(toks)-1]
-	if len(toks) == 0 {
-		e.pusherrtok(tk, "invalid_syntax")
+func (e *eval) paren(n *models.ParenExpr) value {
+	if len(n.Toks) == 2 {
+		e.pusherrtok(n.Toks[0], "invalid_syntax")
+	}
+	val, model := e.tree(n.Expr)
+	if !val.constExpr {
+		val.model = &ir.Paren{Type: val.data.Type, X: model}
 	}
-	val, model := e.toks(toks)
-	m.appendSubNode(model)
 	return val
 }
 
-func (e *eval) dataTypeFunc(expr Tok, callRange Toks, m *exprModel) (v value, isret bool) {
+// convOperand returns operand of type conversion call, such as "int(x)".
+func convOperand(callRange Toks, args *models.Args) models.ExprNode {
+	if args != nil && len(args.Src) == 1 {
+		return &models.ParenExpr{Toks: callRange, Expr: args.Src[0].Expr.Tree}
+	}
+	return ast.Operand(callRange)
+}
+
+func (e *eval) dataTypeFunc(expr Tok, callRange Toks, args *models.Args) (v value, isret bool) {
 	switch expr.Id {
 	case tokens.DataType:
 		switch expr.Kind {
 		case tokens.STR:
-			m.appendSubNode(exprNode{"tostr"})
-			v.data.Type = DataType{Id: jntype.Func, Kind: "()", Tag: strDefaultFunc}
 			isret = true
+			data := callData{args: callRange, parsed: args}
+			v = e.p.callFunc(&strDefaultFunc, nil, data)
+			v.data.Type = DataType{Id: jntype.Str, Kind: tokens.STR}
+			if call, ok := v.model.(*ir.Call); ok && len(call.Args) == 1 {
+				v.model = &ir.Conv{Type: v.data.Type, X: call.Args[0]}
+			}
 		default:
 			dt := DataType{
 				Tok:  expr,
@@ -255,7 +231,7 @@ func (e *eval) dataTypeFunc(expr Tok, callRange Toks, m *exprModel) (v value, is
 				Kind: expr.Kind,
 			}
 			isret = true
-			v = e.castExpr(dt, callRange, m, expr)
+			v = e.castExpr(dt, convOperand(callRange, args), expr)
 		}
 	case tokens.Id:
 		def, _, _ := e.p.defById(expr.Kind)
@@ -269,30 +245,22 @@ func (e *eval) dataTypeFunc(expr Tok, callRange Toks, m *exprModel) (v value, is
 				return
 			}
 			isret = true
-			v = e.castExpr(dt, callRange, m, expr)
+			v = e.castExpr(dt, convOperand(callRange, args), expr)
 		}
 	}
 	return
 }
 
+// callData is call of function.
+// Parsed are arguments of args, nil if they are not parsed yet.
 type callData struct {
 	expr     Toks
 	args     Toks
 	generics Toks
+	parsed   *models.Args
 }
 
-func getCallData(toks Toks, m *exprModel) (data callData) {
-	data.expr, data.args = ast.RangeLast(toks)
-	if len(data.expr) == 0 {
-		return
-	}
-	if tok := data.expr[len(data.expr)-1]; tok.Id == tokens.Brace && tok.Kind == tokens.RBRACKET {
-		data.expr, data.generics = ast.RangeLast(data.expr)
-	}
-	return
-}
-
-func (e *eval) callCppLink(data callData, m *exprModel) (v value) {
+func (e *eval) callCppLink(data callData) (v value) {
 	v.data.Type.Id = jntype.Void
 	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 	tok := data.expr[0]
@@ -318,54 +286,51 @@ func (e *eval) callCppLink(data callData, m *exprModel) (v value) {
 	}
 	link := e.p.linkById(tok.Kind)
 	if link == nil {
-		e.pusherrtok(tok, "id_noexist", tok.Kind)
+		e.pusherrtokSuggest(tok, tok.Kind, e.p.linkIds(), "id_noexist", tok.Kind)
 		return
 	}
-	m.appendSubNode(exprNode{link.Link.Id})
-	return e.p.callFunc(link.Link, data, m)
+	fn := &ir.LinkRef{Type: funcDataType(link.Link), Func: link.Link}
+	return e.p.callFunc(link.Link, fn, data)
 }
 
-func (e *eval) parenthesesRange(toks Toks, m *exprModel) (v value) {
-	tok := toks[0]
-	switch tok.Id {
-	case tokens.Brace:
-		switch tok.Kind {
-		case tokens.LPARENTHESES:
-			val, ok := e.tryCast(toks, m)
-			if ok {
-				v = val
-				return
-			}
-		}
+func (e *eval) call(n *models.CallExpr) (v value) {
+	if n.Fn == nil {
+		e.pusherrtok(n.Generics[0], "invalid_syntax")
+		return
 	}
-	data := getCallData(toks, m)
-	if len(data.expr) == 0 {
-		return e.betweenParentheses(data.args, m)
+	data := callData{
+		expr:     n.Fn.Span(),
+		args:     n.Range,
+		generics: n.Generics,
+		parsed:   n.Args,
 	}
 	switch tok := data.expr[0]; tok.Id {
 	case tokens.Cpp:
-		return e.callCppLink(data, m)
+		return e.callCppLink(data)
 	case tokens.DataType, tokens.Id:
 		if len(data.expr) == 1 && len(data.generics) == 0 {
-			v, isret := e.dataTypeFunc(data.expr[0], data.args, m)
+			v, isret := e.dataTypeFunc(data.expr[0], data.args, data.parsed)
 			if isret {
 				return v
 			}
 		}
-		fallthrough
-	default:
-		v = e.process(data.expr, m)
 	}
+	v = e.node(n.Fn)
 	switch {
 	case typeIsFunc(v.data.Type):
 		f := v.data.Type.Tag.(*Func)
-		return e.p.callFunc(f, data, m)
+		return e.p.callFunc(f, v.model, data)
 	}
 	e.pusherrtok(data.expr[len(data.expr)-1], "invalid_syntax")
 	return
 }
 
-func (e *eval) process(toks Toks, m *exprModel) (v value) {
+// process evaluates tokens as single operand.
+func (e *eval) process(toks Toks) value {
+	return e.node(ast.Operand(toks))
+}
+
+func (e *eval) node(n models.ExprNode) (v value) {
 	defer func() {
 		if typeIsVoid(v.data.Type) {
 			v.data.Type.Kind = jntype.TypeMap[jntype.Void]
@@ -373,59 +338,64 @@ func (e *eval) process(toks Toks, m *exprModel) (v value) {
 		}
 	}()
 	v.constExpr = true
-	if len(toks) == 1 {
-		v, _ = e.single(toks[0], m)
-		return
-	}
-	tok := toks[0]
-	switch tok.Id {
-	case tokens.Operator:
-		return e.unary(toks, m)
-	}
-	tok = toks[len(toks)-1]
-	switch tok.Id {
-	case tokens.Id:
-		return e.id(toks, m)
-	case tokens.Operator:
-		return e.operatorRight(toks, m)
-	case tokens.Brace:
-		switch tok.Kind {
-		case tokens.RPARENTHESES:
-			return e.parenthesesRange(toks, m)
-		case tokens.RBRACE:
-			return e.braceRange(toks, m)
-		case tokens.RBRACKET:
-			return e.bracketRange(toks, m)
+	switch t := n.(type) {
+	case *models.LitExpr:
+		v, _ = e.single(t.Tok)
+	case *models.IdExpr:
+		v, _ = e.single(t.Tok)
+	case *models.UnaryExpr:
+		v = e.unary(t)
+	case *models.SelectorExpr:
+		v = e.subId(t)
+	case *models.NsSelectorExpr:
+		v = e.nsSubId(t)
+	case *models.VariadicExpr:
+		v = e.variadic(t)
+	case *models.CastExpr:
+		v = e.castNode(t)
+	case *models.ParenExpr:
+		v = e.paren(t)
+	case *models.CallExpr:
+		v = e.call(t)
+	case *models.CompositeExpr:
+		v = e.composite(t)
+	case *models.AnonFuncExpr:
+		v = e.anonFunc(t)
+	case *models.IndexExpr:
+		v = e.index(t)
+	case *models.SliceExpr:
+		v = e.slice(t)
+	case *models.BadExpr:
+		if t.Tok.Id != tokens.NA {
+			e.pusherrtok(t.Tok, "invalid_syntax")
 		}
 	}
-	e.pusherrtok(toks[0], "invalid_syntax")
 	return
 }
 
-func (e *eval) subId(toks Toks, m *exprModel) (v value) {
-	i := len(toks) - 1
-	idTok := toks[i]
-	i--
-	dotTok := toks[i]
-	toks = toks[:i]
-	switch len(toks) {
-	case 0:
+func (e *eval) subId(n *models.SelectorExpr) (v value) {
+	idTok := n.Id
+	dotTok := n.Dot
+	var val value
+	if n.Expr == nil {
 		tok := dotTok
 		tok.Id = tokens.Self
 		tok.Kind = tokens.SELF
-		toks = Toks{tok}
-	case 1:
-		tok := toks[0]
-		if tok.Id == tokens.DataType {
-			return e.typeSubId(tok, idTok, m)
-		} else if tok.Id == tokens.Id {
-			t, _, _ := e.p.typeById(tok.Kind)
-			if t != nil {
-				return e.typeSubId(t.Type.Tok, idTok, m)
+		val, _ = e.single(tok)
+	} else {
+		if toks := n.Expr.Span(); len(toks) == 1 {
+			tok := toks[0]
+			if tok.Id == tokens.DataType {
+				return e.typeSubId(tok, idTok)
+			} else if tok.Id == tokens.Id {
+				t, _, _ := e.p.typeById(tok.Kind)
+				if t != nil {
+					return e.typeSubId(t.Type.Tok, idTok)
+				}
 			}
 		}
+		val = e.node(n.Expr)
 	}
-	val := e.process(toks, m)
 	checkType := val.data.Type
 	if typeIsExplicitPtr(checkType) {
 		checkType = unptrType(checkType)
@@ -434,87 +404,38 @@ func (e *eval) subId(toks Toks, m *exprModel) (v value) {
 	case typeIsPure(checkType):
 		switch {
 		case checkType.Id == jntype.Str:
-			return e.strObjSubId(val, idTok, m)
+			return e.strObjSubId(val, idTok)
 		case valIsEnumType(val):
-			return e.enumSubId(val, idTok, m)
+			return e.enumSubId(val, idTok)
 		case valIsStructIns(val):
-			return e.structObjSubId(val, idTok, m)
+			return e.structObjSubId(val, idTok)
 		case valIsTraitIns(val):
-			return e.traitObjSubId(val, idTok, m)
+			return e.traitObjSubId(val, idTok)
 		}
 	case typeIsSlice(checkType):
-		return e.sliceObjSubId(val, idTok, m)
+		return e.sliceObjSubId(val, idTok)
 	case typeIsArray(checkType):
-		return e.arrayObjSubId(val, idTok, m)
+		return e.arrayObjSubId(val, idTok)
 	case typeIsMap(checkType):
-		return e.mapObjSubId(val, idTok, m)
+		return e.mapObjSubId(val, idTok)
 	}
 	e.pusherrtok(dotTok, "obj_not_support_sub_fields", val.data.Type.Kind)
 	return
 }
 
-func (e *eval) castExpr(dt DataType, exprToks Toks, m *exprModel, errTok Tok) value {
-	val, model := e.toks(exprToks)
-	m.appendSubNode(exprNode{tokens.LPARENTHESES + dt.String() + tokens.RPARENTHESES})
-	m.appendSubNode(exprNode{tokens.LPARENTHESES})
-	m.appendSubNode(model)
-	m.appendSubNode(exprNode{tokens.RPARENTHESES})
+func (e *eval) castExpr(dt DataType, expr models.ExprNode, errTok Tok) value {
+	val, model := e.tree(expr)
 	val = e.cast(val, dt, errTok)
-	if val.constExpr {
-		val.model = m
-	}
+	val.model = &ir.Cast{Type: dt, X: model}
 	return val
 }
 
-func (e *eval) tryCast(toks Toks, m *exprModel) (v value, _ bool) {
-	braceCount := 0
-	errTok := toks[0]
-	for i, tok := range toks {
-		if tok.Id == tokens.Brace {
-			switch tok.Kind {
-			case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
-				braceCount++
-				continue
-			default:
-				braceCount--
-			}
-		}
-		if braceCount > 0 {
-			continue
-		} else if i+1 == len(toks) {
-			return
-		}
-		b := ast.NewBuilder(nil)
-		dtindex := 0
-		typeToks := toks[1:i]
-		dt, ok := b.DataType(typeToks, &dtindex, false, false)
-		b.Wait()
-		if !ok {
-			return
-		}
-		dt, ok = e.p.realType(dt, false)
-		if !ok {
-			return
-		}
-		if dtindex+1 < len(typeToks) {
-			return
-		}
-		exprToks := toks[i+1:]
-		if len(exprToks) == 0 {
-			return
-		}
-		tok = exprToks[0]
-		if tok.Id != tokens.Brace || tok.Kind != tokens.LPARENTHESES {
-			return
-		}
-		exprToks, ok = e.p.getrange(tokens.LPARENTHESES, tokens.RPARENTHESES, exprToks)
-		if !ok {
-			return
-		}
-		val := e.castExpr(dt, exprToks, m, errTok)
-		return val, true
+func (e *eval) castNode(n *models.CastExpr) value {
+	dt, ok := e.p.realType(typeClone(n.Type), false)
+	if !ok {
+		return e.node(n.Call)
 	}
-	return
+	return e.castExpr(dt, n.Expr, n.Toks[0])
 }
 
 func (e *eval) cast(v value, t DataType, errtok Tok) value {
@@ -618,12 +539,13 @@ func (e *eval) castSlice(t, vt DataType, errtok Tok) {
 	}
 }
 
-func (e *eval) jntypeSubId(dm *Defmap, idTok Tok, m *exprModel) (v value) {
-	i, dm, t := dm.findById(idTok.Kind, nil)
+func (e *eval) jntypeSubId(dm *Defmap, idTok Tok) (v value) {
+	i, fdm, t := dm.findById(idTok.Kind, nil)
 	if i == -1 {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+		e.pusherrtokSuggest(idTok, idTok.Kind, dm.ids(), "obj_have_not_id", idTok.Kind)
 		return
 	}
+	dm = fdm
 	v.lvalue = false
 	v.data.Value = idTok.Kind
 	switch t {
@@ -634,135 +556,100 @@ func (e *eval) jntypeSubId(dm *Defmap, idTok Tok, m *exprModel) (v value) {
 		if v.constExpr {
 			v.expr = g.ExprTag
 			v.model = g.Expr.Model
-			m.appendSubNode(v.model)
 		} else {
-			m.appendSubNode(exprNode{g.Tag.(string)})
+			v.model = &ir.Field{Type: g.Type, Var: g}
 		}
 	}
 	return
 }
 
-func (e *eval) i8SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(i8statics, idTok, m)
+func (e *eval) i8SubId(idTok Tok) value {
+	return e.jntypeSubId(i8statics, idTok)
 }
 
-func (e *eval) i16SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(i16statics, idTok, m)
+func (e *eval) i16SubId(idTok Tok) value {
+	return e.jntypeSubId(i16statics, idTok)
 }
 
-func (e *eval) i32SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(i32statics, idTok, m)
+func (e *eval) i32SubId(idTok Tok) value {
+	return e.jntypeSubId(i32statics, idTok)
 }
 
-func (e *eval) i64SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(i64statics, idTok, m)
+func (e *eval) i64SubId(idTok Tok) value {
+	return e.jntypeSubId(i64statics, idTok)
 }
 
-func (e *eval) u8SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(u8statics, idTok, m)
+func (e *eval) u8SubId(idTok Tok) value {
+	return e.jntypeSubId(u8statics, idTok)
 }
 
-func (e *eval) u16SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(u16statics, idTok, m)
+func (e *eval) u16SubId(idTok Tok) value {
+	return e.jntypeSubId(u16statics, idTok)
 }
 
-func (e *eval) u32SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(u32statics, idTok, m)
+func (e *eval) u32SubId(idTok Tok) value {
+	return e.jntypeSubId(u32statics, idTok)
 }
 
-func (e *eval) u64SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(u64statics, idTok, m)
+func (e *eval) u64SubId(idTok Tok) value {
+	return e.jntypeSubId(u64statics, idTok)
 }
 
-func (e *eval) uintSubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(uintStatics, idTok, m)
+func (e *eval) uintSubId(idTok Tok) value {
+	return e.jntypeSubId(uintStatics, idTok)
 }
 
-func (e *eval) intSubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(intStatics, idTok, m)
+func (e *eval) intSubId(idTok Tok) value {
+	return e.jntypeSubId(intStatics, idTok)
 }
 
-func (e *eval) f32SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(f32statics, idTok, m)
+func (e *eval) f32SubId(idTok Tok) value {
+	return e.jntypeSubId(f32statics, idTok)
 }
 
-func (e *eval) f64SubId(idTok Tok, m *exprModel) value {
-	return e.jntypeSubId(f64statics, idTok, m)
+func (e *eval) f64SubId(idTok Tok) value {
+	return e.jntypeSubId(f64statics, idTok)
 }
 
-func (e *eval) typeSubId(typeTok, idTok Tok, m *exprModel) (v value) {
+func (e *eval) typeSubId(typeTok, idTok Tok) (v value) {
 	switch typeTok.Kind {
 	case tokens.I8:
-		return e.i8SubId(idTok, m)
+		return e.i8SubId(idTok)
 	case tokens.I16:
-		return e.i16SubId(idTok, m)
+		return e.i16SubId(idTok)
 	case tokens.I32:
-		return e.i32SubId(idTok, m)
+		return e.i32SubId(idTok)
 	case tokens.I64:
-		return e.i64SubId(idTok, m)
+		return e.i64SubId(idTok)
 	case tokens.U8:
-		return e.u8SubId(idTok, m)
+		return e.u8SubId(idTok)
 	case tokens.U16:
-		return e.u16SubId(idTok, m)
+		return e.u16SubId(idTok)
 	case tokens.U32:
-		return e.u32SubId(idTok, m)
+		return e.u32SubId(idTok)
 	case tokens.U64:
-		return e.u64SubId(idTok, m)
+		return e.u64SubId(idTok)
 	case tokens.UINT:
-		return e.uintSubId(idTok, m)
+		return e.uintSubId(idTok)
 	case tokens.INT:
-		return e.intSubId(idTok, m)
+		return e.intSubId(idTok)
 	case tokens.F32:
-		return e.f32SubId(idTok, m)
+		return e.f32SubId(idTok)
 	case tokens.F64:
-		return e.f64SubId(idTok, m)
+		return e.f64SubId(idTok)
 	}
 	e.pusherrtok(typeTok, "obj_not_support_sub_fields", typeTok.Kind)
 	return
 }
 
-func (e *eval) typeId(toks Toks, m *exprModel) (v value) {
-	v.data.Type.Id = jntype.Void
-	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
-	b := ast.NewBuilder(nil)
-	i := 0
-	t, ok := b.DataType(toks, &i, true, true)
-	b.Wait()
-	if !ok {
-		e.p.pusherrs(b.Errors...)
-		return
-	} else if i+1 >= len(toks) {
-		e.pusherrtok(toks[0], "invalid_syntax")
-		return
-	}
-	t, ok = e.p.realType(t, true)
-	if !ok {
-		return
-	}
-	toks = toks[i+1:]
-	if typeIsPure(t) && typeIsStruct(t) {
-		if toks[0].Id != tokens.Brace || toks[0].Kind != tokens.LBRACE {
-			e.pusherrtok(toks[0], "invalid_syntax")
-			return
-		}
-		s := t.Tag.(*jnstruct)
-		return e.p.callStructConstructor(s, toks, m)
-	}
-	if toks[0].Id != tokens.Brace || toks[0].Kind != tokens.LBRACKET {
-		e.pusherrtok(toks[0], "invalid_syntax")
-		return
-	}
-	return e.enumerable(toks, t, m)
-}
-
-func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v value) {
-	i, dm, t := dm.findById(idTok.Kind, idTok.File)
+func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok) (v value) {
+	i, fdm, t := dm.findById(idTok.Kind, idTok.File)
 	if i == -1 {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+		e.pusherrtokSuggest(idTok, idTok.Kind, dm.ids(), "obj_have_not_id", idTok.Kind)
 		return
 	}
+	dm = fdm
 	v = val
-	m.appendSubNode(exprNode{subIdAccessorOfType(val.data.Type)})
 	switch t {
 	case 'g':
 		g := dm.Globals[i]
@@ -771,11 +658,7 @@ func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v valu
 		v.lvalue = true
 		v.constExpr = g.Const
 		v.isField = true
-		if g.Tag != nil {
-			m.appendSubNode(exprNode{g.Tag.(string)})
-		} else {
-			m.appendSubNode(exprNode{g.OutId()})
-		}
+		v.model = &ir.Field{Type: g.Type, X: val.model, Var: g}
 	case 'f':
 		f := dm.Funcs[i]
 		f.used = true
@@ -783,74 +666,76 @@ func (e *eval) xObjSubId(dm *Defmap, val value, idTok Tok, m *exprModel) (v valu
 		v.data.Type.Tag = f.Ast
 		v.data.Type.Kind = f.Ast.DataTypeString()
 		v.data.Tok = f.Ast.Tok
-		m.appendSubNode(exprNode{f.Ast.Id})
+		v.model = &ir.Method{Type: v.data.Type, X: val.model, Func: f.Ast}
 	}
 	return
 }
 
-func (e *eval) strObjSubId(val value, idTok Tok, m *exprModel) value {
-	v := e.xObjSubId(strDefs, val, idTok, m)
+func (e *eval) strObjSubId(val value, idTok Tok) value {
+	v := e.xObjSubId(e.p.Env.strDefs, val, idTok)
 	v.lvalue = false
 	return v
 }
 
-func (e *eval) sliceObjSubId(val value, idTok Tok, m *exprModel) value {
-	v := e.xObjSubId(sliceDefs, val, idTok, m)
+func (e *eval) sliceObjSubId(val value, idTok Tok) value {
+	v := e.xObjSubId(e.p.Env.sliceDefs, val, idTok)
 	v.lvalue = false
 	return v
 }
 
-func (e *eval) arrayObjSubId(val value, idTok Tok, m *exprModel) value {
-	v := e.xObjSubId(arrayDefs, val, idTok, m)
+func (e *eval) arrayObjSubId(val value, idTok Tok) value {
+	v := e.xObjSubId(e.p.Env.arrayDefs, val, idTok)
 	v.lvalue = false
 	return v
 }
 
-func (e *eval) mapObjSubId(val value, idTok Tok, m *exprModel) value {
-	readyMapDefs(val.data.Type)
-	v := e.xObjSubId(mapDefs, val, idTok, m)
+func (e *eval) mapObjSubId(val value, idTok Tok) value {
+	readyMapDefs(e.p.Env.mapDefs, val.data.Type)
+	v := e.xObjSubId(e.p.Env.mapDefs, val, idTok)
 	v.lvalue = false
 	return v
 }
 
-func (e *eval) enumSubId(val value, idTok Tok, m *exprModel) (v value) {
+func (e *eval) enumSubId(val value, idTok Tok) (v value) {
 	enum := val.data.Type.Tag.(*Enum)
 	v = val
 	v.data.Type.Tok = enum.Tok
 	v.constExpr = false
 	v.lvalue = false
 	v.isType = false
-	m.appendSubNode(exprNode{"::"})
-	m.appendSubNode(exprNode{jnapi.OutId(idTok.Kind, enum.Tok.File)})
-	if enum.ItemById(idTok.Kind) == nil {
-		e.pusherrtok(idTok, "obj_have_not_id", idTok.Kind)
+	item := enum.ItemById(idTok.Kind)
+	if item == nil {
+		e.pusherrtokSuggest(idTok, idTok.Kind, enumItemIds(enum), "obj_have_not_id", idTok.Kind)
+		return
 	}
+	v.model = &ir.EnumItemRef{Type: v.data.Type, Enum: val.model, Item: item}
 	return
 }
 
-func (e *eval) structObjSubId(val value, idTok Tok, m *exprModel) value {
+func (e *eval) structObjSubId(val value, idTok Tok) value {
 	s := val.data.Type.Tag.(*jnstruct)
 	val.constExpr = false
 	val.lvalue = false
 	val.isType = false
-	val = e.xObjSubId(s.Defs, val, idTok, m)
+	val = e.xObjSubId(s.Defs, val, idTok)
 	val.constExpr = false
 	return val
 }
 
-func (e *eval) traitObjSubId(val value, idTok Tok, m *exprModel) value {
-	m.appendSubNode(exprNode{".get()"})
+func (e *eval) traitObjSubId(val value, idTok Tok) value {
+	val.model = &ir.TraitData{Type: val.data.Type, X: val.model}
 	t := val.data.Type.Tag.(*trait)
 	val.constExpr = false
 	val.lvalue = false
 	val.isType = false
-	val = e.xObjSubId(t.Defs, val, idTok, m)
+	val = e.xObjSubId(t.Defs, val, idTok)
 	val.constExpr = false
 	return val
 }
 
 type nsFind interface {
 	nsById(string) *namespace
+	nsIds() []string
 }
 
 func (e *eval) getNs(toks *Toks) *Defmap {
@@ -868,7 +753,7 @@ func (e *eval) getNs(toks *Toks) *Defmap {
 					*toks = (*toks)[i:]
 					return ns.defs
 				}
-				e.pusherrtok(tok, "namespace_not_exist", tok.Kind)
+				e.pusherrtokSuggest(tok, tok.Kind, prev.nsIds(), "namespace_not_exist", tok.Kind)
 				return nil
 			}
 			prev = src.defs
@@ -882,59 +767,26 @@ func (e *eval) getNs(toks *Toks) *Defmap {
 	return ns.defs
 }
 
-func (e *eval) nsSubId(toks Toks, m *exprModel) (v value) {
+func (e *eval) nsSubId(n *models.NsSelectorExpr) (v value) {
+	toks := n.Toks
 	defs := e.getNs(&toks)
 	if defs == nil {
 		return
 	}
-	blockTypes := e.p.blockTypes
-	blockVars := e.p.blockVars
-	e.p.blockTypes = nil
-	e.p.blockVars = nil
+	blockScope := e.p.scope
+	e.p.scope = nil
 	pdefs := e.p.Defs
 	e.p.Defs = defs
-	v, _ = e.single(toks[0], m)
-	e.p.blockTypes = blockTypes
-	e.p.blockVars = blockVars
+	v, _ = e.single(toks[0])
+	e.p.scope = blockScope
 	e.p.Defs = pdefs
-	return e.process(toks, m)
-}
-
-func (e *eval) id(toks Toks, m *exprModel) (v value) {
-	i := len(toks) - 1
-	tok := toks[i]
-	if i <= 0 {
-		v, _ = e.single(tok, m)
-		return
-	}
-	i--
-	tok = toks[i]
-	switch tok.Id {
-	case tokens.Dot:
-		return e.subId(toks, m)
-	case tokens.DoubleColon:
-		return e.nsSubId(toks, m)
-	}
-	e.pusherrtok(toks[i], "invalid_syntax")
-	return
-}
-
-func (e *eval) operatorRight(toks Toks, m *exprModel) (v value) {
-	tok := toks[len(toks)-1]
-	switch tok.Kind {
-	case tokens.TRIPLE_DOT:
-		toks = toks[:len(toks)-1]
-		return e.variadic(toks, m, tok)
-	default:
-		e.pusherrtok(tok, "invalid_syntax")
-	}
-	return
+	return e.process(toks)
 }
 
-func (e *eval) variadic(toks Toks, m *exprModel, errtok Tok) (v value) {
-	v = e.process(toks, m)
+func (e *eval) variadic(n *models.VariadicExpr) (v value) {
+	v = e.node(n.Expr)
 	if !typeIsVariadicable(v.data.Type) {
-		e.pusherrtok(errtok, "variadic_with_nonvariadicable", v.data.Type.Kind)
+		e.pusherrtok(n.Op, "variadic_with_nonvariadicable", v.data.Type.Kind)
 		return
 	}
 	v.data.Type = *v.data.Type.ComponentType
@@ -942,69 +794,44 @@ func (e *eval) variadic(toks Toks, m *exprModel, errtok Tok) (v value) {
 	return
 }
 
-func (e *eval) bracketRange(toks Toks, m *exprModel) (v value) {
-	errTok := toks[0]
-	var exprToks Toks
-	braceCount := 0
-	for i := len(toks) - 1; i >= 0; i-- {
-		tok := toks[i]
-		if tok.Id == tokens.Brace {
-			switch tok.Kind {
-			case tokens.RBRACE, tokens.RBRACKET, tokens.RPARENTHESES:
-				braceCount++
-			default:
-				braceCount--
-			}
-		}
-		if braceCount == 0 {
-			exprToks = toks[:i]
-			break
+func (e *eval) slice(n *models.SliceExpr) (v value) {
+	errTok := n.Toks[0]
+	model := new(ir.Slice)
+	v, model.X = e.tree(n.Expr)
+	var leftV, rightV value
+	if n.Low != nil {
+		e.hasError = false
+		leftV, model.Low = e.tree(n.Low)
+		e.checkIntegerIndexing(leftV, errTok)
+		if leftV.constExpr && tonums(leftV.expr) < 0 {
+			e.p.pusherrtok(leftV.data.Tok, "invalid_expr")
 		}
 	}
-	if len(exprToks) == 0 || braceCount > 0 {
-		e.pusherrtok(errTok, "invalid_syntax")
-		return
-	}
-	var model iExpr
-	v, model = e.toks(exprToks)
-	m.appendSubNode(model)
-	toks = toks[len(exprToks):]
-	if toks, colon := ast.SplitColon(toks, new(int)); colon != -1 {
-		var leftV, rightV value
-		leftToks := toks[:colon]
-		rightToks := toks[colon+1:]
-		m.appendSubNode(exprNode{".___slice("})
-		if len(leftToks) > 0 {
-			var model iExpr
-			leftV, model = e.p.evalToks(leftToks)
-			m.appendSubNode(model)
-			e.checkIntegerIndexing(leftV, errTok)
-			if leftV.constExpr && tonums(leftV.expr) < 0 {
-				e.p.pusherrtok(leftV.data.Tok, "invalid_expr")
-			}
-		} else {
-			m.appendSubNode(exprNode{"0"})
+	if n.High != nil {
+		e.hasError = false
+		rightV, model.High = e.tree(n.High)
+		e.checkIntegerIndexing(rightV, errTok)
+		if rightV.constExpr && tonums(rightV.expr) < 0 {
+			e.p.pusherrtok(rightV.data.Tok, "invalid_expr")
 		}
-		if len(rightToks) > 0 {
-			m.appendSubNode(exprNode{","})
-			var model iExpr
-			rightV, model = e.p.evalToks(rightToks)
-			m.appendSubNode(model)
-			e.checkIntegerIndexing(rightV, errTok)
-			if rightV.constExpr && tonums(rightV.expr) < 0 {
-				e.p.pusherrtok(rightV.data.Tok, "invalid_expr")
-			}
-		}
-		m.appendSubNode(exprNode{")"})
-		return e.slicing(v, errTok)
 	}
-	m.appendSubNode(exprNode{tokens.LBRACKET})
-	leftv, model := e.toks(toks[1 : len(toks)-1])
-	m.appendSubNode(model)
-	m.appendSubNode(exprNode{tokens.RBRACKET})
+	v = e.slicing(v, errTok)
+	model.Type = v.data.Type
+	v.model = model
+	return v
+}
+
+func (e *eval) index(n *models.IndexExpr) (v value) {
+	errTok := n.Toks[0]
+	model := new(ir.Index)
+	v, model.X = e.tree(n.Expr)
+	leftv, index := e.tree(n.Index)
+	model.Index = index
 	v = e.indexing(v, leftv, errTok)
 	v.data.Type.Pure = true
 	v.data.Type.Original = nil
+	model.Type = v.data.Type
+	v.model = model
 	return v
 }
 
@@ -1043,7 +870,6 @@ func (e *eval) indexingSlice(slicev, index value, errtok Tok) value {
 
 func (e *eval) indexingArray(arrv, index value, errtok Tok) value {
 	arrv.data.Type = *arrv.data.Type.ComponentType
-	e.p.wg.Add(1)
 	e.checkIntegerIndexing(index, errtok)
 	if index.constExpr && tonums(index.expr) < 0 {
 		e.p.pusherrtok(index.data.Tok, "invalid_expr")
@@ -1103,201 +929,228 @@ func (e *eval) slicingStr(v value, errtok Tok) value {
 	return v
 }
 
-func (e *eval) enumerableParts(toks Toks) []Toks {
-	toks = toks[1 : len(toks)-1]
-	parts, errs := ast.Parts(toks, tokens.Comma, true)
-	e.p.pusherrs(errs...)
-	return parts
-}
-
-func (e *eval) buildArray(parts []Toks, t DataType, errtok Tok) (value, iExpr) {
+func (e *eval) buildArray(elems []models.ExprNode, t DataType, errtok Tok) (value, ir.Expr) {
 	if !t.Size.AutoSized {
-		if models.Size(len(parts)) > t.Size.N {
+		if models.Size(len(elems)) > t.Size.N {
 			e.p.pusherrtok(errtok, "overflow_limits")
 		}
 	} else {
-		t.Size.N = models.Size(len(parts))
+		t.Size.N = models.Size(len(elems))
 		t.Size.Expr = models.Expr{
-			Model: exprNode{
-				value: jntype.TypeMap[jntype.UInt] + "{" + strconv.FormatUint(uint64(t.Size.N), 10) + "}",
+			Model: &ir.Const{
+				Type:  DataType{Id: jntype.UInt, Kind: jntype.TypeMap[jntype.UInt]},
+				Value: uint64(t.Size.N),
 			},
 		}
 	}
 	var v value
 	v.data.Value = t.Kind
 	v.data.Type = t
-	model := sliceExpr{dataType: t}
-	for _, part := range parts {
-		partVal, expModel := e.toks(part)
-		model.expr = append(model.expr, expModel)
-		assignChecker{
-			p:      e.p,
-			t:      *t.ComponentType,
-			v:      partVal,
-			errtok: part[0],
-		}.checkAssignType()
-	}
+	model := &ir.Composite{Type: t}
+	e.buildElems(model, elems, *t.ComponentType)
 	return v, model
 }
 
-func (e *eval) buildSlice(parts []Toks, t DataType, errtok Tok) (value, iExpr) {
+func (e *eval) buildSlice(elems []models.ExprNode, t DataType, errtok Tok) (value, ir.Expr) {
 	var v value
 	v.data.Value = t.Kind
 	v.data.Type = t
-	model := sliceExpr{dataType: t}
-	for _, part := range parts {
-		partVal, expModel := e.toks(part)
-		model.expr = append(model.expr, expModel)
+	model := &ir.Composite{Type: t}
+	e.buildElems(model, elems, *t.ComponentType)
+	return v, model
+}
+
+// buildElems evaluates elements of array or slice literal into model.
+func (e *eval) buildElems(model *ir.Composite, elems []models.ExprNode, t DataType) {
+	for _, elem := range elems {
+		switch elem := elem.(type) {
+		case nil:
+			continue
+		case *models.KeyValueExpr:
+			e.pusherrtok(elem.Colon, "invalid_syntax")
+			continue
+		}
+		elemVal, elemModel := e.tree(elem)
+		model.Elems = append(model.Elems, elemModel)
 		assignChecker{
 			p:      e.p,
-			t:      *t.ComponentType,
-			v:      partVal,
-			errtok: part[0],
+			t:      t,
+			v:      elemVal,
+			errtok: elem.Span()[0],
 		}.checkAssignType()
 	}
-	return v, model
 }
 
-func (e *eval) buildMap(parts []Toks, t DataType, errtok Tok) (value, iExpr) {
+func (e *eval) buildMap(elems []models.ExprNode, t DataType, errtok Tok) (value, ir.Expr) {
 	var v value
 	v.data.Value = t.Kind
 	v.data.Type = t
-	model := mapExpr{dataType: t}
+	model := &ir.MapLit{Type: t}
 	types := t.Tag.([]DataType)
 	keyType := types[0]
 	valType := types[1]
-	for _, part := range parts {
-		braceCount := 0
-		colon := -1
-		for i, tok := range part {
-			if tok.Id == tokens.Brace {
-				switch tok.Kind {
-				case tokens.LBRACE, tokens.LBRACKET, tokens.LPARENTHESES:
-					braceCount++
-				default:
-					braceCount--
-				}
-			}
-			if braceCount != 0 {
-				continue
-			}
-			if tok.Id == tokens.Colon {
-				colon = i
-				break
-			}
+	for _, elem := range elems {
+		if elem == nil {
+			continue
 		}
-		if colon < 1 || colon+1 >= len(part) {
+		pair, ok := elem.(*models.KeyValueExpr)
+		if !ok || pair.Key == nil || pair.Value == nil {
 			e.pusherrtok(errtok, "missing_expr")
 			continue
 		}
-		colonTok := part[colon]
-		keyToks := part[:colon]
-		valToks := part[colon+1:]
-		key, keyModel := e.toks(keyToks)
-		model.keyExprs = append(model.keyExprs, keyModel)
-		val, valModel := e.toks(valToks)
-		model.valExprs = append(model.valExprs, valModel)
+		key, keyModel := e.tree(pair.Key)
+		model.Keys = append(model.Keys, keyModel)
+		val, valModel := e.tree(pair.Value)
+		model.Values = append(model.Values, valModel)
 		assignChecker{
 			p:      e.p,
 			t:      keyType,
 			v:      key,
-			errtok: colonTok,
+			errtok: pair.Colon,
 		}.checkAssignType()
 		assignChecker{
 			p:      e.p,
 			t:      valType,
 			v:      val,
-			errtok: colonTok,
+			errtok: pair.Colon,
 		}.checkAssignType()
 	}
 	return v, model
 }
 
-func (e *eval) enumerable(exprToks Toks, t DataType, m *exprModel) (v value) {
-	var model iExpr
+func (e *eval) enumerable(n *models.CompositeExpr, t DataType) (v value) {
+	var model ir.Expr
 	t, ok := e.p.realType(t, true)
 	if !ok {
 		return
 	}
+	elems := n.Elems
+	if elems == nil && len(n.Body) > 2 {
+		// Body has syntax errors, elements are split again to report them.
+		parsed, errs := ast.Elems(n.Body)
+		e.p.pusherrs(errs...)
+		elems = parsed
+	}
+	errtok := n.Body[0]
 	switch {
 	case typeIsArray(t):
-		v, model = e.buildArray(e.enumerableParts(exprToks), t, exprToks[0])
+		v, model = e.buildArray(elems, t, errtok)
 	case typeIsSlice(t):
-		v, model = e.buildSlice(e.enumerableParts(exprToks), t, exprToks[0])
+		v, model = e.buildSlice(elems, t, errtok)
 	case typeIsMap(t):
-		v, model = e.buildMap(e.enumerableParts(exprToks), t, exprToks[0])
+		v, model = e.buildMap(elems, t, errtok)
 	default:
-		e.pusherrtok(exprToks[0], "invalid_type_source")
+		e.pusherrtok(errtok, "invalid_type_source")
 		return
 	}
-	m.appendSubNode(model)
+	v.model = model
 	return
 }
 
-func (e *eval) braceRange(toks Toks, m *exprModel) (v value) {
-	var exprToks Toks
-	braceCount := 0
-	for i := len(toks) - 1; i >= 0; i-- {
-		tok := toks[i]
-		if tok.Id != tokens.Brace {
-			continue
+// compositeType returns type of composite literal.
+// Type is parsed from tokens to report errors if tree has not valid type.
+func (e *eval) compositeType(n *models.CompositeExpr) (t DataType, ok bool) {
+	if n.Type != nil {
+		return typeClone(*n.Type), true
+	}
+	toks := n.Toks[:len(n.Toks)-len(n.Body)]
+	b := ast.NewBuilder(nil)
+	i := 0
+	t, ok = b.DataType(toks, &i, true, true)
+	b.Wait()
+	if !ok {
+		e.p.pusherrs(b.Errors...)
+	} else if i+1 < len(toks) {
+		e.pusherrtok(toks[i+1], "invalid_syntax")
+		// Literals of identifier types are not evaluated with invalid type.
+		ok = toks[0].Id != tokens.Id
+	}
+	return
+}
+
+// structArgs returns arguments of struct literal elements.
+// Returns nil if elements are not valid arguments.
+func structArgs(elems []models.ExprNode) *models.Args {
+	args := new(models.Args)
+	for _, elem := range elems {
+		var arg Arg
+		if pair, ok := elem.(*models.KeyValueExpr); ok {
+			key, _ := pair.Key.(*models.IdExpr)
+			if key == nil || key.Tok.Id != tokens.Id || pair.Value == nil {
+				return nil
+			}
+			args.Targeted = true
+			arg.Tok = key.Tok
+			arg.TargetId = key.Tok.Kind
+			elem = pair.Value
 		}
-		switch tok.Kind {
-		case tokens.RBRACE, tokens.RBRACKET, tokens.RPARENTHESES:
-			braceCount++
-		default:
-			braceCount--
+		if bad, ok := elem.(*models.BadExpr); ok && bad.Tok.Id == tokens.NA {
+			return nil
 		}
-		if braceCount != 0 {
-			continue
+		arg.Expr = Expr{Toks: elem.Span(), Tree: elem}
+		if arg.TargetId == "" {
+			arg.Tok = arg.Expr.Toks[0]
+		}
+		args.Src = append(args.Src, arg)
+	}
+	return args
+}
+
+func (e *eval) structLit(s *jnstruct, n *models.CompositeExpr) value {
+	args := structArgs(n.Elems)
+	if args == nil && len(n.Body) > 2 {
+		// Arguments are parsed from tokens to report syntax errors.
+		b := new(ast.Builder)
+		args = b.Args(n.Body[1:len(n.Body)-1], true)
+		if len(b.Errors) > 0 {
+			e.p.pusherrs(b.Errors...)
+			args = nil
 		}
-		exprToks = toks[:i]
-		break
 	}
-	valToksLen := len(exprToks)
-	if valToksLen == 0 || braceCount > 0 {
-		e.pusherrtok(toks[0], "invalid_syntax")
+	return e.p.callStructConstructor(s, args)
+}
+
+func (e *eval) composite(n *models.CompositeExpr) (v value) {
+	v.data.Type.Id = jntype.Void
+	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
+	t, ok := e.compositeType(n)
+	if !ok {
 		return
 	}
-	switch exprToks[0].Id {
-	case tokens.Id:
-		return e.typeId(toks, m)
-	case tokens.Brace:
-		switch exprToks[0].Kind {
-		case tokens.LBRACKET:
-			b := ast.NewBuilder(nil)
-			i := 0
-			t, ok := b.DataType(exprToks, &i, true, true)
-			b.Wait()
-			if !ok {
-				e.p.pusherrs(b.Errors...)
-				return
-			} else if i+1 < len(exprToks) {
-				e.pusherrtok(toks[i+1], "invalid_syntax")
-			}
-			exprToks = toks[len(exprToks):]
-			return e.enumerable(exprToks, t, m)
-		case tokens.LPARENTHESES:
-			b := ast.NewBuilder(toks)
-			f := b.Func(b.Toks, true, false)
-			b.Wait()
-			if len(b.Errors) > 0 {
-				e.p.pusherrs(b.Errors...)
-				return
-			}
-			e.p.checkAnonFunc(&f)
-			f.Owner = e.p
-			v.data.Value = f.Id
-			v.data.Type.Tag = &f
-			v.data.Type.Id = jntype.Func
-			v.data.Type.Kind = f.DataTypeString()
-			m.appendSubNode(anonFuncExpr{&f, e.p.blockVars})
-			return
-		default:
-			e.pusherrtok(exprToks[0], "invalid_syntax")
-		}
-	default:
-		e.pusherrtok(exprToks[0], "invalid_syntax")
+	if n.Toks[0].Id != tokens.Id {
+		return e.enumerable(n, t)
+	}
+	t, ok = e.p.realType(t, true)
+	if !ok {
+		return
+	}
+	if !typeIsPure(t) || !typeIsStruct(t) {
+		e.pusherrtok(n.Body[0], "invalid_syntax")
+		return
+	}
+	return e.structLit(t.Tag.(*jnstruct), n)
+}
+
+func (e *eval) anonFunc(n *models.AnonFuncExpr) (v value) {
+	if n.Func == nil {
+		e.p.pusherrs(n.Errors...)
+		return
+	}
+	// Node is shared by evaluations, such as instances of generic
+	// functions, so types of parameters are reloaded on copies.
+	f := *n.Func
+	f.Params = append([]Param(nil), f.Params...)
+	e.p.checkAnonFunc(&f)
+	f.Owner = e.p
+	v.data.Value = f.Id
+	v.data.Type.Tag = &f
+	v.data.Type.Id = jntype.Func
+	v.data.Type.Kind = f.DataTypeString()
+	v.model = &ir.Closure{
+		Type:     v.data.Type,
+		Func:     &f,
+		Captures: e.p.scope.visibleVars(),
+		Body:     e.p.lowerBlock(f.Block),
 	}
 	return
 }
@@ -0,0 +1,518 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+)
+
+// flowNode is node of control flow graph.
+// Nodes are statements or parts of statements
+// such as conditions of loops and cases of matches.
+type flowNode struct {
+	tok     Tok
+	reads   []models.Expr
+	writes  []*Var
+	decl    *Var
+	succs   []*flowNode
+	reached bool
+	// Variables that may be unassigned at beginning of node.
+	unassigned map[varKey]bool
+}
+
+type flowLoop struct {
+	breaks []*flowNode
+	conts  []*flowNode
+}
+
+type flowMatch struct {
+	breaks []*flowNode
+}
+
+type flowJump struct {
+	n      *flowNode
+	label  string
+	target *models.Case
+}
+
+type flowChecker struct {
+	p       *Parser
+	nodes   []*flowNode
+	blocks  [][]*flowNode
+	loops   []*flowLoop
+	matches map[*models.Case]*flowMatch
+	cases   map[*models.Case]*flowNode
+	labels  map[string]*flowNode
+	jumps   []flowJump
+}
+
+func newFlowChecker(p *Parser) *flowChecker {
+	return &flowChecker{
+		p:       p,
+		matches: map[*models.Case]*flowMatch{},
+		cases:   map[*models.Case]*flowNode{},
+		labels:  map[string]*flowNode{},
+	}
+}
+
+func link(preds []*flowNode, n *flowNode) {
+	for _, pred := range preds {
+		pred.succs = append(pred.succs, n)
+	}
+}
+
+// flowTracks reports variables of type t are tracked
+// for use before assignment. Other types have usable defaults.
+func flowTracks(t DataType) bool {
+	return typeIsPure(t) && !typeIsStruct(t) && !typeIsTrait(t)
+}
+
+// rootVar returns variable of expression if expression is variable.
+func rootVar(model ir.Expr) *Var {
+	switch t := model.(type) {
+	case *ir.Paren:
+		return rootVar(t.X)
+	case *ir.VarRef:
+		return t.Var
+	}
+	return nil
+}
+
+// inspectVars calls f for variables of expression.
+// Escape reports address of variable is taken.
+// Bodies of closures are skipped because call time is unknown.
+func inspectVars(model ir.Expr, f func(v *Var, escape bool)) {
+	if model == nil {
+		return
+	}
+	ir.Inspect(model, func(node any) bool {
+		switch t := node.(type) {
+		case *ir.Closure:
+			return false
+		case *ir.AddrOf:
+			if v := rootVar(t.X); v != nil {
+				f(v, true)
+				return false
+			}
+		case *ir.VarRef:
+			f(t.Var, false)
+		}
+		return true
+	})
+}
+
+func isConstTrue(model ir.Expr) bool {
+	switch t := model.(type) {
+	case *ir.Paren:
+		return isConstTrue(t.X)
+	case *ir.Const:
+		b, ok := t.Value.(bool)
+		return ok && b
+	}
+	return false
+}
+
+func (fc *flowChecker) isPanic(model ir.Expr) bool {
+	call, ok := model.(*ir.Call)
+	if !ok {
+		return false
+	}
+	ref, ok := call.Fn.(*ir.FuncRef)
+	return ok && fc.p.Env.isBuiltinFunc(ref.Func, "panic")
+}
+
+func (fc *flowChecker) node(tok Tok, preds []*flowNode) *flowNode {
+	n := &flowNode{tok: tok}
+	fc.nodes = append(fc.nodes, n)
+	link(preds, n)
+	return n
+}
+
+// check checks flow of block of function.
+// Reports whether end of block is reachable.
+func (fc *flowChecker) check(b *models.Block) bool {
+	entry := fc.node(Tok{}, nil)
+	outs := fc.block(b, []*flowNode{entry})
+	for _, jump := range fc.jumps {
+		var target *flowNode
+		if jump.target != nil {
+			target = fc.cases[jump.target]
+		} else {
+			target = fc.labels[jump.label]
+		}
+		if target != nil {
+			jump.n.succs = append(jump.n.succs, target)
+		}
+	}
+	fc.reach(entry)
+	fc.checkUnreachable()
+	fc.checkAssigns()
+	for _, n := range outs {
+		if n.reached {
+			return true
+		}
+	}
+	return false
+}
+
+// block builds nodes of block with predecessors
+// and returns nodes that flows to end of block.
+func (fc *flowChecker) block(b *models.Block, preds []*flowNode) []*flowNode {
+	if b == nil {
+		return preds
+	}
+	index := len(fc.blocks)
+	fc.blocks = append(fc.blocks, nil)
+	var stmts []*flowNode
+	// Recovered panics continues after block.
+	var recovers []*flowNode
+	for i := 0; i < len(b.Tree); i++ {
+		var n *flowNode
+		n, preds = fc.stmt(b.Tree, &i, preds)
+		if n == nil {
+			continue
+		}
+		stmts = append(stmts, n)
+		if s, ok := b.Tree[i].Data.(models.ExprStatement); ok {
+			if _, ok := s.Expr.Model.(*recoverExpr); ok {
+				recovers = append(recovers, n)
+			}
+		}
+	}
+	fc.blocks[index] = stmts
+	return append(preds, recovers...)
+}
+
+// stmt builds nodes of statement with predecessors.
+// Returns first node of statement and nodes that flows to next statement.
+func (fc *flowChecker) stmt(tree []models.Statement, i *int, preds []*flowNode) (*flowNode, []*flowNode) {
+	s := &tree[*i]
+	switch t := s.Data.(type) {
+	case nil, models.Comment, Type:
+		return nil, preds
+	case Var:
+		if t.Const {
+			return nil, preds
+		}
+	case models.If:
+		return fc.ifChain(tree, i, preds)
+	case models.Iter:
+		return fc.iter(s.Tok, &t, preds)
+	case models.Match:
+		return fc.match(s.Tok, &t, preds)
+	}
+	n := fc.node(s.Tok, preds)
+	outs := []*flowNode{n}
+	switch t := s.Data.(type) {
+	case Var:
+		if t.SetterTok.Id != tokens.NA {
+			n.reads = append(n.reads, t.Expr)
+		} else if flowTracks(t.Type) {
+			n.decl = &t
+		}
+	case models.Assign:
+		fc.assign(n, &t)
+	case models.ExprStatement:
+		n.reads = append(n.reads, t.Expr)
+		if fc.isPanic(t.Expr.Model) {
+			outs = nil
+		}
+	case models.Defer:
+		n.reads = append(n.reads, t.Expr)
+	case models.ConcurrentCall:
+		n.reads = append(n.reads, t.Expr)
+	case models.Ret:
+		n.reads = append(n.reads, t.Expr)
+		outs = nil
+	case models.Break:
+		if t.Case != nil {
+			if m := fc.matches[t.Case]; m != nil {
+				m.breaks = append(m.breaks, n)
+			}
+		} else if len(fc.loops) > 0 {
+			loop := fc.loops[len(fc.loops)-1]
+			loop.breaks = append(loop.breaks, n)
+		}
+		outs = nil
+	case models.Continue:
+		if len(fc.loops) > 0 {
+			loop := fc.loops[len(fc.loops)-1]
+			loop.conts = append(loop.conts, n)
+		}
+		outs = nil
+	case models.Fallthrough:
+		if t.Case != nil && t.Case.Next != nil {
+			fc.jumps = append(fc.jumps, flowJump{n: n, target: t.Case.Next})
+		}
+		outs = nil
+	case models.Goto:
+		fc.jumps = append(fc.jumps, flowJump{n: n, label: t.Label})
+		outs = nil
+	case models.Label:
+		fc.labels[t.Label] = n
+	case *models.Block:
+		outs = fc.block(t, outs)
+	}
+	return n, outs
+}
+
+func (fc *flowChecker) assign(n *flowNode, a *models.Assign) {
+	n.reads = append(n.reads, a.Right...)
+	for i := range a.Left {
+		left := &a.Left[i]
+		if left.Ignore || left.Var.New {
+			continue
+		}
+		if len(left.Expr.Toks) == 1 && jnapi.IsIgnoreId(left.Expr.Toks[0].Kind) {
+			continue
+		}
+		v := rootVar(left.Expr.Model)
+		// Compound assignments and suffixes reads left operand.
+		if v == nil || len(a.Right) == 0 || a.Setter.Kind != tokens.EQUAL {
+			n.reads = append(n.reads, left.Expr)
+		}
+		if v != nil {
+			n.writes = append(n.writes, v)
+		}
+	}
+}
+
+func (fc *flowChecker) ifChain(tree []models.Statement, i *int, preds []*flowNode) (*flowNode, []*flowNode) {
+	ifast := tree[*i].Data.(models.If)
+	root := fc.node(tree[*i].Tok, preds)
+	root.reads = append(root.reads, ifast.Expr)
+	outs := fc.block(ifast.Block, []*flowNode{root})
+	cond := root
+	for !tree[*i].WithTerminator && *i+1 < len(tree) {
+		switch t := tree[*i+1].Data.(type) {
+		case models.ElseIf:
+			n := fc.node(tree[*i+1].Tok, []*flowNode{cond})
+			n.reads = append(n.reads, t.Expr)
+			outs = append(outs, fc.block(t.Block, []*flowNode{n})...)
+			cond = n
+		case models.Else:
+			*i++
+			return root, append(outs, fc.block(t.Block, []*flowNode{cond})...)
+		default:
+			return root, append(outs, cond)
+		}
+		*i++
+	}
+	return root, append(outs, cond)
+}
+
+func (fc *flowChecker) iter(tok Tok, iter *models.Iter, preds []*flowNode) (*flowNode, []*flowNode) {
+	var first, head *flowNode
+	var next []models.Statement
+	exits := true
+	switch t := iter.Profile.(type) {
+	case models.IterWhile:
+		head = fc.node(tok, preds)
+		head.reads = append(head.reads, t.Expr)
+		exits = !isConstTrue(t.Expr.Model)
+	case models.IterFor:
+		if t.Once.Data != nil {
+			zero := 0
+			first, preds = fc.stmt([]models.Statement{t.Once}, &zero, preds)
+		}
+		head = fc.node(tok, preds)
+		head.reads = append(head.reads, t.Condition)
+		exits = t.Condition.Model != nil && !isConstTrue(t.Condition.Model)
+		if t.Next.Data != nil {
+			next = []models.Statement{t.Next}
+		}
+	case models.IterForeach:
+		head = fc.node(tok, preds)
+		head.reads = append(head.reads, t.Expr)
+	default:
+		head = fc.node(tok, preds)
+		exits = false
+	}
+	if first == nil {
+		first = head
+	}
+	loop := &flowLoop{}
+	fc.loops = append(fc.loops, loop)
+	outs := fc.block(iter.Block, []*flowNode{head})
+	fc.loops = fc.loops[:len(fc.loops)-1]
+	outs = append(outs, loop.conts...)
+	if next != nil {
+		zero := 0
+		_, outs = fc.stmt(next, &zero, outs)
+	}
+	link(outs, head)
+	if exits {
+		return first, append(loop.breaks, head)
+	}
+	return first, loop.breaks
+}
+
+func (fc *flowChecker) match(tok Tok, m *models.Match, preds []*flowNode) (*flowNode, []*flowNode) {
+	head := fc.node(tok, preds)
+	head.reads = append(head.reads, m.Expr)
+	fm := &flowMatch{}
+	for i := range m.Cases {
+		fc.matches[&m.Cases[i]] = fm
+	}
+	if m.Default != nil {
+		fc.matches[m.Default] = fm
+	}
+	var outs []*flowNode
+	cond := head
+	for i := range m.Cases {
+		c := &m.Cases[i]
+		n := fc.node(c.Tok, []*flowNode{cond})
+		n.reads = append(n.reads, c.Exprs...)
+		body := fc.node(c.Tok, []*flowNode{n})
+		fc.cases[c] = body
+		outs = append(outs, fc.block(c.Block, []*flowNode{body})...)
+		cond = n
+	}
+	if m.Default != nil {
+		body := fc.node(m.Default.Tok, []*flowNode{cond})
+		fc.cases[m.Default] = body
+		outs = append(outs, fc.block(m.Default.Block, []*flowNode{body})...)
+	} else if !isExhaustive(m) {
+		outs = append(outs, cond)
+	}
+	return head, append(outs, fm.breaks...)
+}
+
+func (fc *flowChecker) reach(entry *flowNode) {
+	stack := []*flowNode{entry}
+	entry.reached = true
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, succ := range n.succs {
+			if !succ.reached {
+				succ.reached = true
+				stack = append(stack, succ)
+			}
+		}
+	}
+}
+
+// checkUnreachable reports first statement of
+// each unreachable sequence of statements.
+func (fc *flowChecker) checkUnreachable() {
+	for _, stmts := range fc.blocks {
+		// First statement is unreachable only if block is unreachable,
+		// which is reported at owner of block.
+		reached := false
+		for _, n := range stmts {
+			if reached && !n.reached {
+				fc.p.pushwarntok(n.tok, "unreachable_code")
+			}
+			reached = n.reached
+		}
+	}
+}
+
+// transfer returns variables that may be unassigned at end of node.
+func (n *flowNode) transfer() map[varKey]bool {
+	out := make(map[varKey]bool, len(n.unassigned))
+	for key := range n.unassigned {
+		out[key] = true
+	}
+	for _, expr := range n.reads {
+		inspectVars(expr.Model, func(v *Var, escape bool) {
+			if escape {
+				delete(out, keyOfVar(v))
+			}
+		})
+	}
+	for _, v := range n.writes {
+		delete(out, keyOfVar(v))
+	}
+	if n.decl != nil {
+		out[keyOfVar(n.decl)] = true
+	}
+	return out
+}
+
+// merge merges variables into unassigned variables of node.
+// Reports whether new variable merged.
+func (n *flowNode) merge(vars map[varKey]bool) (changed bool) {
+	if n.unassigned == nil {
+		n.unassigned = map[varKey]bool{}
+	}
+	for key := range vars {
+		if !n.unassigned[key] {
+			n.unassigned[key] = true
+			changed = true
+		}
+	}
+	return
+}
+
+func (fc *flowChecker) checkAssigns() {
+	for changed := true; changed; {
+		changed = false
+		for _, n := range fc.nodes {
+			if !n.reached {
+				continue
+			}
+			out := n.transfer()
+			for _, succ := range n.succs {
+				changed = succ.merge(out) || changed
+			}
+		}
+	}
+	reported := map[varKey]bool{}
+	for _, n := range fc.nodes {
+		if !n.reached || len(n.unassigned) == 0 {
+			continue
+		}
+		escaped := map[varKey]bool{}
+		for _, expr := range n.reads {
+			inspectVars(expr.Model, func(v *Var, escape bool) {
+				if escape {
+					escaped[keyOfVar(v)] = true
+				}
+			})
+		}
+		for _, expr := range n.reads {
+			inspectVars(expr.Model, func(v *Var, escape bool) {
+				key := keyOfVar(v)
+				if escape || escaped[key] || reported[key] || !n.unassigned[key] {
+					return
+				}
+				reported[key] = true
+				fc.p.pushwarntok(readTok(expr, v, n.tok), "use_before_assign", v.Id)
+			})
+		}
+	}
+}
+
+// readTok returns token of variable in expression.
+// Returns def if variable is not found.
+func readTok(expr models.Expr, v *Var, def Tok) Tok {
+	for _, tok := range expr.Toks {
+		if tok.Id == tokens.Id && tok.Kind == v.Id {
+			return tok
+		}
+	}
+	return def
+}
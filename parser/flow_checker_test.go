@@ -0,0 +1,268 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlowChecker(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name: "code after return",
+			src: `f() int {
+	ret 1
+	println(2)
+}`,
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "missing return",
+			src: `f(b bool) int {
+	if b {
+		ret 1
+	}
+}`,
+			errors: []string{"missing_ret"},
+		},
+		{
+			name: "return in all branches",
+			src: `f(b bool) int {
+	if b {
+		ret 1
+	} else {
+		ret 0
+	}
+}`,
+		},
+		{
+			name: "code after goto",
+			src: `f() int {
+	goto done
+	println(1)
+done:
+	ret 1
+}`,
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "backward goto",
+			src: `f() int {
+	i: = 0
+again:
+	i++
+	if i < 10 {
+		goto again
+	}
+	ret i
+}`,
+		},
+		{
+			name: "code after break",
+			src: `f() int {
+	for {
+		break
+		println(1)
+	}
+	ret 1
+}`,
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "code after continue",
+			src: `f() int {
+	i: = 0
+	for i < 10 {
+		i++
+		continue
+		println(i)
+	}
+	ret i
+}`,
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "infinite loop",
+			src: `f() int {
+	for {
+	}
+}`,
+		},
+		{
+			name: "code after infinite loop",
+			src: `f() int {
+	for {
+	}
+	ret 1
+}`,
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "loop with break needs return",
+			src: `f() int {
+	for {
+		break
+	}
+}`,
+			errors: []string{"missing_ret"},
+		},
+		{
+			name: "code after fallthrough",
+			src: `f(i int) int {
+	match i {
+	case 1:
+		fallthrough
+		println(i)
+	case 2:
+		ret 2
+	}
+	ret 0
+}`,
+			errors:   []string{"fallthrough_wrong_use"},
+			warnings: []string{"unreachable_code"},
+		},
+		{
+			name: "fallthrough into returning case",
+			src: `f(i int) int {
+	match i {
+	case 1:
+		fallthrough
+	default:
+		ret 2
+	}
+}`,
+		},
+		{
+			name: "exhaustive bool match",
+			src: `f(b bool) int {
+	match b {
+	case true:
+		ret 1
+	case false:
+		ret 0
+	}
+}`,
+		},
+		{
+			name: "exhaustive enum match",
+			src: `enum color {
+	red,
+	green,
+	blue,
+}
+
+f(c color) int {
+	match c {
+	case color.red:
+		ret 1
+	case color.green, color.blue:
+		ret 2
+	}
+}`,
+		},
+		{
+			name: "not exhaustive enum match",
+			src: `enum color {
+	red,
+	green,
+	blue,
+}
+
+f(c color) int {
+	match c {
+	case color.red:
+		ret 1
+	case color.green:
+		ret 2
+	}
+}`,
+			errors:   []string{"missing_ret"},
+			warnings: []string{"match_not_exhaustive"},
+		},
+		{
+			name: "not exhaustive int match",
+			src: `f(i int) int {
+	match i {
+	case 1:
+		ret 1
+	}
+}`,
+			errors: []string{"missing_ret"},
+		},
+		{
+			name: "use before assign",
+			src: `f(b bool) int {
+	x: int
+	if b {
+		x = 1
+	}
+	ret x
+}`,
+			warnings: []string{"use_before_assign"},
+		},
+		{
+			name: "assign in all branches",
+			src: `f(b bool) int {
+	x: int
+	if b {
+		x = 1
+	} else {
+		x = 2
+	}
+	ret x
+}`,
+		},
+		{
+			name: "assign in exhaustive match",
+			src: `f(b bool) int {
+	x: int
+	match b {
+	case true:
+		x = 1
+	case false:
+		x = 2
+	}
+	ret x
+}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := parseSource(t, c.src+"\n\nmain() {}\n")
+			if got := logKeys(p.Errors); !equalKeys(got, c.errors) {
+				t.Errorf("errors %v, want %v", got, c.errors)
+			}
+			if got := logKeys(p.Warnings); !equalKeys(got, c.warnings) {
+				t.Errorf("warnings %v, want %v", got, c.warnings)
+			}
+		})
+	}
+}
+
+func equalKeys(got, want []string) bool {
+	return len(got) == 0 && len(want) == 0 || reflect.DeepEqual(got, want)
+}
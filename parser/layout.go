@@ -0,0 +1,146 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jnbits"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// primitiveSize returns size in bytes of primitive type.
+// Returns zero if size is not known while checking.
+func primitiveSize(t DataType) uint64 {
+	if !typeIsPure(t) || t.MultiTyped {
+		return 0
+	}
+	switch {
+	case t.Id == jntype.Bool:
+		return 1
+	case jntype.IsNumeric(t.Id):
+		return uint64(jnbits.BitsizeType(t.Id)) / 8
+	}
+	return 0
+}
+
+// foldLayout folds sizeof and alignof calls of primitive types.
+// Alignments greater than word size are depends on C++ ABI.
+func (p *Parser) foldLayout(v *value, align bool) {
+	call, ok := v.model.(*ir.Call)
+	if !ok || len(call.Generics) != 1 {
+		return
+	}
+	n := primitiveSize(call.Generics[0])
+	if n == 0 || (align && n > uint64(jntype.BitSize/8)) {
+		return
+	}
+	v.expr = n
+	v.constExpr = true
+	v.model = getModel(*v)
+}
+
+func (p *Parser) callOffsetof(data callData) (v value) {
+	errTok := data.expr[0]
+	v.data.Value = errTok.Kind
+	v.data.Type = DataType{Id: jntype.UInt, Kind: jntype.TypeMap[jntype.UInt]}
+	generics, err := p.getGenerics(data.generics)
+	if err {
+		p.eval.hasError = true
+		return
+	}
+	if !p.checkGenericsQuantity(1, len(generics), errTok) {
+		return
+	}
+	t := generics[0]
+	if !typeIsPure(t) || !typeIsStruct(t) {
+		p.pusherrtok(errTok, "offsetof_not_struct", t.Kind)
+		return
+	}
+	args := data.parsed
+	if args == nil || len(args.Src) != 1 {
+		p.pusherrtok(data.args[0], "invalid_syntax")
+		return
+	}
+	id, _ := args.Src[0].Expr.Tree.(*models.IdExpr)
+	if id == nil || id.Tok.Id != tokens.Id {
+		p.pusherrtok(data.args[0], "invalid_syntax")
+		return
+	}
+	s := t.Tag.(*jnstruct)
+	for _, field := range s.Defs.Globals {
+		if field.Id == id.Tok.Kind {
+			v.model = &ir.Offsetof{Type: v.data.Type, Struct: t, Field: field}
+			return
+		}
+	}
+	p.pusherrtok(id.Tok, "obj_have_not_id", id.Tok.Kind)
+	return
+}
+
+// staticAssert checks assertion if condition is constant.
+// Other conditions are must be constant for C++ compiler.
+func (p *Parser) staticAssert(v *value, errTok Tok) {
+	call, ok := v.model.(*ir.Call)
+	if !ok || len(call.Args) != 2 {
+		return
+	}
+	msg, _ := call.Args[1].(*ir.Const)
+	if msg == nil {
+		p.eval.pusherrtok(errTok, "expr_not_const")
+		return
+	}
+	s, _ := msg.Value.(string)
+	switch cond := call.Args[0].(type) {
+	case *ir.Const:
+		if ok, _ := cond.Value.(bool); !ok {
+			p.eval.pusherrtok(errTok, "static_assert_failed", s)
+		}
+	default:
+		if !p.isCppConst(cond) {
+			p.eval.pusherrtok(errTok, "expr_not_const")
+			return
+		}
+	}
+	v.model = &ir.StaticAssert{Type: v.data.Type, Cond: call.Args[0], Msg: s}
+}
+
+// isCppConst reports whether expression is constant expression for C++.
+func (p *Parser) isCppConst(e ir.Expr) bool {
+	switch t := e.(type) {
+	case *ir.Const, *ir.Offsetof:
+		return true
+	case *ir.Paren:
+		return p.isCppConst(t.X)
+	case *ir.Cast:
+		return p.isCppConst(t.X)
+	case *ir.Unary:
+		return t.Op != tokens.STAR && p.isCppConst(t.X)
+	case *ir.Binary:
+		return p.isCppConst(t.X) && p.isCppConst(t.Y)
+	case *ir.Call:
+		ref, ok := t.Fn.(*ir.FuncRef)
+		return ok && (p.Env.isBuiltinFunc(ref.Func, "sizeof") ||
+			p.Env.isBuiltinFunc(ref.Func, "alignof"))
+	}
+	return false
+}
@@ -0,0 +1,454 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnapi"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// recoverExpr is model of recover call statement.
+// It is lowered to try statement that covers rest of block.
+type recoverExpr struct {
+	handler ir.Expr
+	param   *Param
+}
+
+func (*recoverExpr) DataType() DataType {
+	return DataType{Id: jntype.Void, Kind: jntype.TypeMap[jntype.Void]}
+}
+
+func funcDataType(f *Func) DataType {
+	return DataType{
+		Tok:  f.Tok,
+		Id:   jntype.Func,
+		Kind: f.DataTypeString(),
+		Tag:  f,
+	}
+}
+
+// enumItemModel returns model of enum item that has not expression.
+func enumItemModel(t DataType, i int) ir.Expr {
+	c := &ir.Const{Type: t}
+	if jntype.IsSignedInteger(t.Id) {
+		c.Value = int64(i)
+	} else {
+		c.Value = uint64(i)
+	}
+	return c
+}
+
+// lowerer lowers checked models to IR.
+type lowerer struct {
+	p     *Parser
+	cases map[*models.Case]*ir.Case
+}
+
+func newLowerer(p *Parser) *lowerer {
+	return &lowerer{
+		p:     p,
+		cases: map[*models.Case]*ir.Case{},
+	}
+}
+
+func (p *Parser) lowerBlock(b *models.Block) *ir.Block {
+	return newLowerer(p).block(b)
+}
+
+func (l *lowerer) block(b *models.Block) *ir.Block {
+	block := new(ir.Block)
+	if b == nil {
+		return block
+	}
+	block.Stmts = l.stmts(b.Tree)
+	return block
+}
+
+func (l *lowerer) stmts(tree []models.Statement) []ir.Stmt {
+	var stmts []ir.Stmt
+	for i := 0; i < len(tree); i++ {
+		s := tree[i]
+		switch t := s.Data.(type) {
+		case nil:
+		case models.If:
+			stmts = append(stmts, l.ifChain(t, tree, &i))
+		case models.ExprStatement:
+			if r, ok := t.Expr.Model.(*recoverExpr); ok {
+				stmts = append(stmts, &ir.Try{
+					Pos:     ir.Pos{Tok: s.Tok},
+					Body:    &ir.Block{Stmts: l.stmts(tree[i+1:])},
+					Handler: r.handler,
+					Param:   r.param,
+				})
+				return stmts
+			}
+			// Constant assertions are checked already.
+			if a, ok := t.Expr.Model.(*ir.StaticAssert); ok {
+				if _, ok := a.Cond.(*ir.Const); ok {
+					continue
+				}
+			}
+			stmts = append(stmts, &ir.ExprStmt{
+				Pos: ir.Pos{Tok: s.Tok},
+				X:   t.Expr.Model,
+			})
+		default:
+			stmt := l.stmt(s.Data)
+			if stmt != nil {
+				setPos(stmt, s.Tok)
+				stmts = append(stmts, stmt)
+			}
+		}
+	}
+	return stmts
+}
+
+// setPos sets position of stmt to tok if it has no position.
+func setPos(stmt ir.Stmt, tok Tok) {
+	pos := stmt.Position()
+	if pos.Tok.File == nil {
+		pos.Tok = tok
+	}
+}
+
+func (l *lowerer) ifChain(ifast models.If, tree []models.Statement, i *int) *ir.If {
+	root := &ir.If{
+		Pos:  ir.Pos{Tok: tree[*i].Tok},
+		Cond: ifast.Expr.Model,
+		Then: l.block(ifast.Block),
+	}
+	node := root
+	for !tree[*i].WithTerminator && *i+1 < len(tree) {
+		switch t := tree[*i+1].Data.(type) {
+		case models.ElseIf:
+			elif := &ir.If{
+				Pos:  ir.Pos{Tok: tree[*i+1].Tok},
+				Cond: t.Expr.Model,
+				Then: l.block(t.Block),
+			}
+			node.Else = elif
+			node = elif
+		case models.Else:
+			node.Else = l.block(t.Block)
+			*i++
+			return root
+		default:
+			return root
+		}
+		*i++
+	}
+	return root
+}
+
+func (l *lowerer) stmt(data any) ir.Stmt {
+	switch t := data.(type) {
+	case Var:
+		if t.Const {
+			return nil
+		}
+		return &ir.VarDecl{Var: &t, Init: t.Expr.Model}
+	case models.Assign:
+		return l.assign(&t)
+	case models.Iter:
+		return l.iter(&t)
+	case models.Break:
+		if t.Case != nil {
+			return &ir.Break{Match: l.caseOf(t.Case).Match}
+		}
+		return &ir.Break{}
+	case models.Continue:
+		return &ir.Continue{}
+	case models.Fallthrough:
+		return &ir.Fallthrough{Case: l.caseOf(t.Case)}
+	case Type:
+		return &ir.TypeAlias{Ast: &t}
+	case *models.Block:
+		return l.block(t)
+	case models.Defer:
+		return &ir.Defer{Call: t.Expr.Model}
+	case models.ConcurrentCall:
+		return &ir.Co{Call: t.Expr.Model}
+	case models.Match:
+		return l.match(&t)
+	case models.Comment:
+		return &ir.Comment{Text: t.Content}
+	case models.Goto:
+		return &ir.Goto{Label: t.Label}
+	case models.Label:
+		return &ir.Label{Label: t.Label}
+	case models.Ret:
+		return &ir.Ret{X: t.Expr.Model}
+	}
+	return nil
+}
+
+func (l *lowerer) assign(a *models.Assign) ir.Stmt {
+	switch {
+	case len(a.Right) == 0:
+		return &ir.IncDec{Op: a.Setter.Kind, X: a.Left[0].Expr.Model}
+	case !a.MultipleRet && len(a.Left) == 1:
+		left := &a.Left[0]
+		if left.Var.New {
+			v := left.Var
+			return &ir.VarDecl{Var: &v, Init: a.Right[0].Model}
+		}
+		assign := &ir.Assign{Op: a.Setter.Kind, Right: a.Right[0].Model}
+		if len(left.Expr.Toks) != 1 || !jnapi.IsIgnoreId(left.Expr.Toks[0].Kind) {
+			assign.Left = left.Expr.Model
+		}
+		return assign
+	}
+	assign := &ir.TupleAssign{Op: a.Setter.Kind}
+	for i := range a.Left {
+		left := &a.Left[i]
+		switch {
+		case left.Ignore:
+			assign.Left = append(assign.Left, nil)
+		case left.Var.New:
+			v := left.Var
+			assign.Decls = append(assign.Decls, &ir.VarDecl{Var: &v})
+			assign.Left = append(assign.Left, &ir.VarRef{Type: v.Type, Var: &v})
+		default:
+			assign.Left = append(assign.Left, left.Expr.Model)
+		}
+	}
+	for _, right := range a.Right {
+		assign.Right = append(assign.Right, right.Model)
+	}
+	return assign
+}
+
+func (l *lowerer) iter(iter *models.Iter) ir.Stmt {
+	switch t := iter.Profile.(type) {
+	case models.IterWhile:
+		return &ir.While{Cond: t.Expr.Model, Body: l.block(iter.Block)}
+	case models.IterFor:
+		f := &ir.For{Cond: t.Condition.Model, Body: l.block(iter.Block)}
+		if t.Once.Data != nil {
+			f.Init = l.stmt(t.Once.Data)
+			setPos(f.Init, t.Once.Tok)
+		}
+		if t.Next.Data != nil {
+			f.Post = l.stmt(t.Next.Data)
+			setPos(f.Post, t.Next.Tok)
+		}
+		return f
+	case models.IterForeach:
+		return &ir.Foreach{
+			Key:   &t.KeyA,
+			Value: &t.KeyB,
+			X:     t.Expr.Model,
+			Type:  t.ExprType,
+			Body:  l.block(iter.Block),
+		}
+	}
+	return &ir.Loop{Body: l.block(iter.Block)}
+}
+
+// caseOf returns IR of case.
+// Cases are created while lowering of their match statement,
+// new one is created for unknown cases to keep references valid.
+func (l *lowerer) caseOf(c *models.Case) *ir.Case {
+	if c == nil {
+		return nil
+	}
+	irc, ok := l.cases[c]
+	if !ok {
+		irc = &ir.Case{Pos: ir.Pos{Tok: c.Tok}}
+		if c.Match != nil {
+			irc.Match = &ir.Match{Pos: ir.Pos{Tok: c.Match.Tok}}
+		}
+		l.cases[c] = irc
+	}
+	return irc
+}
+
+func (l *lowerer) match(m *models.Match) *ir.Match {
+	match := &ir.Match{
+		Pos:  ir.Pos{Tok: m.Tok},
+		X:    m.Expr.Model,
+		Type: m.ExprType,
+	}
+	cases := make([]*models.Case, 0, len(m.Cases)+1)
+	for i := range m.Cases {
+		cases = append(cases, &m.Cases[i])
+	}
+	if m.Default != nil {
+		cases = append(cases, m.Default)
+	}
+	for _, c := range cases {
+		irc := &ir.Case{Pos: ir.Pos{Tok: c.Tok}, Match: match}
+		l.cases[c] = irc
+	}
+	for _, c := range cases {
+		irc := l.cases[c]
+		irc.Next = l.caseOf(c.Next)
+		for _, expr := range c.Exprs {
+			irc.Exprs = append(irc.Exprs, expr.Model)
+		}
+		irc.Body = l.block(c.Block)
+	}
+	for i := range m.Cases {
+		match.Cases = append(match.Cases, l.cases[&m.Cases[i]])
+	}
+	if m.Default != nil {
+		match.Default = l.cases[m.Default]
+	}
+	return match
+}
+
+func (l *lowerer) fn(f *function) *ir.Func {
+	irf := &ir.Func{Ast: f.Ast, Entry: f.isEntryPoint}
+	body := l.block(f.Ast.Block)
+	var stmts []ir.Stmt
+	if f.Ast.Receiver != nil && !typeIsPtr(*f.Ast.Receiver) {
+		s := f.Ast.Receiver.Tag.(*jnstruct)
+		self := s.selfVar(*f.Ast.Receiver)
+		stmts = append(stmts, &ir.VarDecl{Var: self, Init: self.Expr.Model})
+	}
+	for _, v := range f.Ast.RetType.Vars() {
+		if v != nil {
+			stmts = append(stmts, &ir.VarDecl{Var: v, Init: v.Expr.Model})
+		}
+	}
+	body.Stmts = append(stmts, body.Stmts...)
+	irf.Body = body
+	return irf
+}
+
+func isLowerable(tok Tok) bool {
+	return tok.Id != tokens.NA
+}
+
+// defs lowers definitions of dm into pkg.
+// Public definitions of compiled package are marked as exported.
+func (l *lowerer) defs(pkg *ir.Package, dm *Defmap) {
+	local := dm == l.p.Defs
+	for _, t := range dm.Types {
+		if t.Used && isLowerable(t.Tok) {
+			pkg.Types = append(pkg.Types, &ir.TypeAlias{
+				Pos: ir.Pos{Tok: t.Tok},
+				Ast: t,
+			})
+		}
+	}
+	for _, e := range dm.Enums {
+		if e.Used && isLowerable(e.Tok) {
+			enum := &ir.Enum{Ast: e}
+			for _, item := range e.Items {
+				enum.Items = append(enum.Items, &ir.EnumItem{
+					Ast:   item,
+					Value: item.Expr.Model,
+				})
+			}
+			pkg.Enums = append(pkg.Enums, enum)
+		}
+	}
+	for _, t := range dm.Traits {
+		if t.Used && isLowerable(t.Ast.Tok) {
+			pkg.Traits = append(pkg.Traits, lowerTrait(t))
+		}
+	}
+	for _, s := range dm.Structs {
+		if s.Used && isLowerable(s.Ast.Tok) {
+			pkg.Structs = append(pkg.Structs, l.jnstruct(s))
+		}
+	}
+	for _, g := range dm.Globals {
+		export := local && g.Pub
+		if !g.Const && (g.Used || export) && isLowerable(g.Token) {
+			pkg.Globals = append(pkg.Globals, &ir.VarDecl{
+				Pos:    ir.Pos{Tok: g.Token},
+				Var:    g,
+				Init:   g.Expr.Model,
+				Export: export,
+			})
+		}
+	}
+	for _, f := range dm.Funcs {
+		export := local && f.Ast.Pub && len(f.Ast.Generics) == 0
+		if (f.used || export) && isLowerable(f.Ast.Tok) {
+			irf := l.fn(f)
+			irf.Export = export
+			pkg.Funcs = append(pkg.Funcs, irf)
+		}
+	}
+	f, _, _ := dm.funcById(jn.InitializerFunction, nil)
+	if f != nil {
+		pkg.Inits = append(pkg.Inits, &ir.Func{Ast: f.Ast})
+	}
+}
+
+// lowerTrait returns IR of trait.
+// Functions are taken from definitions, because
+// builtin traits are not have functions in AST.
+func lowerTrait(t *trait) *ir.Trait {
+	irt := &ir.Trait{Ast: t.Ast}
+	for _, f := range t.Defs.Funcs {
+		irt.Funcs = append(irt.Funcs, f.Ast.Id)
+	}
+	return irt
+}
+
+func (l *lowerer) jnstruct(s *jnstruct) *ir.Struct {
+	irs := &ir.Struct{
+		Ast:         &s.Ast,
+		Constructor: s.constructor,
+		Fields:      s.Defs.Globals,
+	}
+	for _, t := range s.traits {
+		irs.Traits = append(irs.Traits, lowerTrait(t))
+	}
+	for _, f := range s.Defs.Funcs {
+		if f.used {
+			irs.Funcs = append(irs.Funcs, l.fn(f))
+		}
+	}
+	return irs
+}
+
+// IR returns typed intermediate representation of checked package.
+// Used packages are lowered before package itself.
+func (p *Parser) IR() *ir.Package {
+	pkg := new(ir.Package)
+	l := newLowerer(p)
+	for _, use := range p.used() {
+		if use.cppLink {
+			pkg.Links = append(pkg.Links, use.Path)
+		} else {
+			l.defs(pkg, use.defs)
+		}
+	}
+	l.defs(pkg, p.Defs)
+	if p.IsTest {
+		pkg.IsTest = true
+		for _, f := range p.Tests() {
+			if p.TestFilter == nil || p.TestFilter(f.Ast.Id) {
+				pkg.Tests = append(pkg.Tests, &ir.Func{Ast: f.Ast})
+			}
+		}
+	}
+	return pkg
+}
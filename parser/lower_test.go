@@ -0,0 +1,277 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeRuneLabs/jane/ir"
+)
+
+// lowerSource parses src and returns IR of it.
+func lowerSource(t *testing.T, src string) *ir.Package {
+	t.Helper()
+	p := parseSource(t, src)
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	return p.IR()
+}
+
+// irFunc returns function of pkg by identifier.
+func irFunc(t *testing.T, pkg *ir.Package, id string) *ir.Func {
+	t.Helper()
+	for _, f := range pkg.Funcs {
+		if f.Ast.Id == id {
+			return f
+		}
+	}
+	t.Fatalf("function %s is not lowered", id)
+	return nil
+}
+
+// stmtKinds returns kinds of statements, nested blocks in parentheses.
+func stmtKinds(stmts []ir.Stmt) string {
+	kinds := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		kind := strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ir.")
+		switch t := stmt.(type) {
+		case *ir.Block:
+			kind += "(" + stmtKinds(t.Stmts) + ")"
+		case *ir.If:
+			kind += "(" + stmtKinds(t.Then.Stmts) + ")"
+			if t.Else != nil {
+				kind += " else " + stmtKinds([]ir.Stmt{t.Else})
+			}
+		case *ir.Loop:
+			kind += "(" + stmtKinds(t.Body.Stmts) + ")"
+		case *ir.While:
+			kind += "(" + stmtKinds(t.Body.Stmts) + ")"
+		case *ir.For:
+			kind += "(" + stmtKinds(t.Body.Stmts) + ")"
+		case *ir.Foreach:
+			kind += "(" + stmtKinds(t.Body.Stmts) + ")"
+		case *ir.Try:
+			kind += "(" + stmtKinds(t.Body.Stmts) + ")"
+		}
+		kinds[i] = kind
+	}
+	return strings.Join(kinds, " ")
+}
+
+func TestLowerStmts(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"vars", "x: = 1\n\tx = 2\n\tx++\n\tx += 3", "VarDecl Assign IncDec Assign"},
+		{"ignore", "_ = 1", "Assign"},
+		{"tuple", "a:, b: = 1, 2\n\ta, b = b, a\n\t_ = a + b", "TupleAssign TupleAssign Assign"},
+		{"constant", "const x: = 1\n\t_ = x", "Assign"},
+		{"if chain", "x: = 1\n\tif x == 1 {\n\t\tx = 2\n\t} else if x == 2 {\n\t} else {\n\t\tx++\n\t}",
+			"VarDecl If(Assign) else If() else Block(IncDec)"},
+		{"if without else", "if true {\n\t}\n\tif false {\n\t}", "If() If()"},
+		{"loops", "for {\n\t\tbreak\n\t}\n\tfor false {\n\t\tcontinue\n\t}\n\tfor i: = 0; i < 3; i++ {\n\t}",
+			"Loop(Break) While(Continue) For()"},
+		{"foreach", "xs: = []int{1, 2}\n\tfor i:, x: in xs {\n\t\t_ = i + x\n\t}", "VarDecl Foreach(Assign)"},
+		{"block", "{\n\t\tx: = 1\n\t\t_ = x\n\t}", "Block(VarDecl Assign)"},
+		{"goto", "goto end\nend:\n\tret", "Goto Label Ret"},
+		{"defer and co", "defer println(1)\n\tco println(2)", "Defer Co"},
+		{"constant assertion", "static_assert(true, \"ok\")\n\tprintln(1)", "ExprStmt"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pkg := lowerSource(t, "main() {\n\t"+c.body+"\n}\n")
+			f := irFunc(t, pkg, "main")
+			if got := stmtKinds(f.Body.Stmts); got != c.want {
+				t.Errorf("got  %s\nwant %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLowerFor(t *testing.T) {
+	pkg := lowerSource(t, "main() {\n\tfor i: = 0; i < 3; i++ {\n\t\tprintln(i)\n\t}\n}\n")
+	f := irFunc(t, pkg, "main").Body.Stmts[0].(*ir.For)
+	if _, ok := f.Init.(*ir.VarDecl); !ok {
+		t.Errorf("init: %T", f.Init)
+	}
+	if _, ok := f.Post.(*ir.IncDec); !ok {
+		t.Errorf("post: %T", f.Post)
+	}
+	if f.Cond == nil {
+		t.Error("condition is not lowered")
+	}
+	if f.Init.Position().Tok.Row != 2 || f.Post.Position().Tok.Row != 2 {
+		t.Errorf("positions: %d, %d", f.Init.Position().Tok.Row, f.Post.Position().Tok.Row)
+	}
+}
+
+func TestLowerMatch(t *testing.T) {
+	pkg := lowerSource(t, `main() {
+	x: = 1
+	match x {
+	case 1, 2:
+		fallthrough
+	case 3:
+		break
+	default:
+		println(x)
+	}
+}
+`)
+	m, ok := irFunc(t, pkg, "main").Body.Stmts[1].(*ir.Match)
+	if !ok {
+		t.Fatalf("match is not lowered: %T", irFunc(t, pkg, "main").Body.Stmts[1])
+	}
+	if len(m.Cases) != 2 || m.Default == nil {
+		t.Fatalf("cases: %d, default: %v", len(m.Cases), m.Default)
+	}
+	first, second := m.Cases[0], m.Cases[1]
+	if len(first.Exprs) != 2 || len(second.Exprs) != 1 {
+		t.Errorf("case expressions: %d, %d", len(first.Exprs), len(second.Exprs))
+	}
+	if first.Next != second || second.Next != m.Default || m.Default.Next != nil {
+		t.Error("cases are not linked in order")
+	}
+	for _, c := range append(m.Cases, m.Default) {
+		if c.Match != m {
+			t.Errorf("case at %d is not belongs to match", c.Pos.Tok.Row)
+		}
+	}
+	fall, ok := first.Body.Stmts[0].(*ir.Fallthrough)
+	if !ok || fall.Case != first {
+		t.Errorf("fallthrough is not refers to its case: %+v", first.Body.Stmts[0])
+	}
+	brk, ok := second.Body.Stmts[0].(*ir.Break)
+	if !ok || brk.Match != m {
+		t.Errorf("break is not refers to match: %+v", second.Body.Stmts[0])
+	}
+}
+
+func TestLowerPositions(t *testing.T) {
+	pkg := lowerSource(t, "main() {\n\tx: = 1\n\n\tx = 2\n\tif x == 2 {\n\t\tx++\n\t}\n}\n")
+	stmts := irFunc(t, pkg, "main").Body.Stmts
+	var rows []int
+	for _, stmt := range stmts {
+		rows = append(rows, stmt.Position().Tok.Row)
+	}
+	rows = append(rows, stmts[2].(*ir.If).Then.Stmts[0].Position().Tok.Row)
+	if want := []int{2, 4, 5, 6}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows: got %v, want %v", rows, want)
+	}
+}
+
+func TestLowerPackage(t *testing.T) {
+	pkg := lowerSource(t, `enum color {
+	red,
+	green,
+}
+
+struct point {
+	x: int
+	y: int
+}
+
+const limit: = 10
+count: = 0
+pub total: = 0
+
+unused() {}
+
+pub helper() {}
+
+double(x int) int {
+	ret x * 2
+}
+
+main() {
+	p: = point{1, 2}
+	c: = color.green
+	_ = c
+	count = double(p.x) + limit
+}
+`)
+	var funcs []string
+	for _, f := range pkg.Funcs {
+		funcs = append(funcs, f.Ast.Id)
+		if f.Entry != (f.Ast.Id == "main") {
+			t.Errorf("entry point of %s: %v", f.Ast.Id, f.Entry)
+		}
+		if f.Export != (f.Ast.Id == "helper") {
+			t.Errorf("export of %s: %v", f.Ast.Id, f.Export)
+		}
+	}
+	if want := []string{"helper", "double", "main"}; !reflect.DeepEqual(funcs, want) {
+		t.Errorf("functions: got %v, want %v", funcs, want)
+	}
+	var globals []string
+	for _, g := range pkg.Globals {
+		globals = append(globals, g.Var.Id)
+	}
+	if want := []string{"count", "total"}; !reflect.DeepEqual(globals, want) {
+		t.Errorf("globals: got %v, want %v", globals, want)
+	}
+	if len(pkg.Structs) != 1 || len(pkg.Structs[0].Fields) != 2 {
+		t.Fatalf("structs: %+v", pkg.Structs)
+	}
+	if len(pkg.Enums) != 1 || len(pkg.Enums[0].Items) != 2 {
+		t.Fatalf("enums: %+v", pkg.Enums)
+	}
+	for i, item := range pkg.Enums[0].Items {
+		c, ok := item.Value.(*ir.Const)
+		if !ok || fmt.Sprint(c.Value) != fmt.Sprint(i) {
+			t.Errorf("value of %s: %+v", item.Ast.Id, item.Value)
+		}
+	}
+}
+
+// TestLowerUsedPackages lowers definitions of used packages before package.
+func TestLowerUsedPackages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":       {Data: []byte("use cpp \"link.hpp\"\nuse std::calc::*\n\nmain() {\n\t_ = twice(1)\n}\n")},
+		"link.hpp":      {Data: []byte("")},
+		"std/calc/c.jn": {Data: []byte("pub twice(x int) int { ret x * 2 }\n\npub unused() {}\n")},
+	}
+	p := parseFS(t, fsys, "std")
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	pkg := p.IR()
+	if !reflect.DeepEqual(pkg.Links, []string{"link.hpp"}) {
+		t.Errorf("links: %v", pkg.Links)
+	}
+	var funcs []string
+	for _, f := range pkg.Funcs {
+		funcs = append(funcs, f.Ast.Id)
+	}
+	if want := []string{"twice", "main"}; !reflect.DeepEqual(funcs, want) {
+		t.Fatalf("functions: got %v, want %v", funcs, want)
+	}
+	if pkg.Funcs[0].Export {
+		t.Error("function of used package is exported")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+type matchChecker struct {
+	p *Parser
+	m *models.Match
+}
+
+// caseKey returns key of constant case expression.
+// Enum items are keyed by item, other constants by value.
+func caseKey(model ir.Expr) (any, bool) {
+	switch t := model.(type) {
+	case *ir.Paren:
+		return caseKey(t.X)
+	case *ir.EnumItemRef:
+		return t.Item, true
+	case *ir.Const:
+		switch v := t.Value.(type) {
+		case int64:
+			if v >= 0 {
+				return uint64(v), true
+			}
+			return v, true
+		case uint64, float64, bool, string:
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// coveredCases returns keys of constant case expressions of match.
+// dup is called for each case expression that already covered if not nil.
+func coveredCases(m *models.Match, dup func(expr models.Expr)) map[any]bool {
+	covered := map[any]bool{}
+	for i := range m.Cases {
+		for _, expr := range m.Cases[i].Exprs {
+			key, ok := caseKey(expr.Model)
+			if !ok {
+				continue
+			}
+			if covered[key] {
+				if dup != nil {
+					dup(expr)
+				}
+				continue
+			}
+			covered[key] = true
+		}
+	}
+	return covered
+}
+
+// missingCases returns identifiers of values of type t that not covered.
+// Reports false if values of type t are not enumerable.
+func missingCases(t DataType, covered map[any]bool) ([]string, bool) {
+	if !typeIsPure(t) {
+		return nil, false
+	}
+	var missing []string
+	switch {
+	case typeIsEnum(t):
+		for _, item := range t.Tag.(*Enum).Items {
+			if !covered[item] {
+				missing = append(missing, item.Id)
+			}
+		}
+	case t.Id == jntype.Bool:
+		for _, b := range [...]bool{true, false} {
+			if !covered[b] {
+				missing = append(missing, strconv.FormatBool(b))
+			}
+		}
+	default:
+		return nil, false
+	}
+	return missing, true
+}
+
+// isExhaustive reports whether one of cases of match is always executed.
+func isExhaustive(m *models.Match) bool {
+	if m.Default != nil {
+		return true
+	}
+	if m.Expr.Tree == nil {
+		return false
+	}
+	missing, ok := missingCases(m.ExprType, coveredCases(m, nil))
+	return ok && len(missing) == 0
+}
+
+func (mc *matchChecker) check() {
+	// Cases of match without expression are conditions.
+	if mc.m.Expr.Tree == nil {
+		return
+	}
+	covered := coveredCases(mc.m, func(expr models.Expr) {
+		mc.p.pushwarntok(expr.Toks[0], "unreachable_case")
+	})
+	if mc.m.Default == nil {
+		mc.checkExhaustive(covered)
+	}
+}
+
+func (mc *matchChecker) checkExhaustive(covered map[any]bool) {
+	missing, _ := missingCases(mc.m.ExprType, covered)
+	if len(missing) == 0 {
+		return
+	}
+	ids := strings.Join(missing, ", ")
+	if mc.p.Env.Set != nil && mc.p.Env.Set.StrictMatch {
+		mc.p.pusherrtok(mc.m.Tok, "match_not_exhaustive", ids)
+	} else {
+		mc.p.pushwarntok(mc.m.Tok, "match_not_exhaustive", ids)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+	"github.com/DeRuneLabs/jane/package/jnset"
+)
+
+const matchEnum = `enum color {
+	red,
+	green,
+	blue,
+}
+
+`
+
+// parseMatch parses and checks src with match settings of strict.
+func parseMatch(t *testing.T, src string, strict bool) *Parser {
+	t.Helper()
+	fsys := fstest.MapFS{"main.jn": {Data: []byte(matchEnum + src + "\n\nmain() {}\n")}}
+	f, err := jnio.ReadJn(fsys, "main.jn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := *jnset.Default
+	set.StrictMatch = strict
+	p := New(f)
+	p.Env = NewEnv(fsys, "std", &set)
+	p.Parsef(true, false)
+	return p
+}
+
+// logMessages returns keys and messages of logs.
+func logMessages(logs []jnlog.CompilerLog) []string {
+	msgs := make([]string, len(logs))
+	for i, log := range logs {
+		msgs[i] = log.Key + ": " + log.Message
+	}
+	return msgs
+}
+
+func TestMatchExhaustive(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		warnings []string
+	}{
+		{
+			name: "all enum items",
+			src: `f(c color) {
+	match c {
+	case color.red:
+	case color.green, color.blue:
+	}
+}`,
+		},
+		{
+			name: "missing enum items",
+			src: `f(c color) {
+	match c {
+	case color.green:
+	}
+}`,
+			warnings: []string{"match_not_exhaustive: match is not exhaustive, missing cases: red, blue"},
+		},
+		{
+			name: "default",
+			src: `f(c color) {
+	match c {
+	case color.green:
+	default:
+	}
+}`,
+		},
+		{
+			name: "all bools",
+			src: `f(b bool) {
+	match b {
+	case false:
+	case true:
+	}
+}`,
+		},
+		{
+			name: "missing bool",
+			src: `f(b bool) {
+	match b {
+	case true:
+	}
+}`,
+			warnings: []string{"match_not_exhaustive: match is not exhaustive, missing cases: false"},
+		},
+		{
+			name: "integers are not enumerable",
+			src: `f(i int) {
+	match i {
+	case 1:
+	}
+}`,
+		},
+		{
+			name: "match without expression",
+			src: `f(i int) {
+	match {
+	case i == 1:
+	}
+}`,
+		},
+		{
+			name: "duplicate enum item",
+			src: `f(c color) {
+	match c {
+	case color.red, color.green:
+	case color.blue, (color.red):
+	}
+}`,
+			warnings: []string{"unreachable_case: unreachable case, value is already handled by previous case"},
+		},
+		{
+			name: "duplicate integers",
+			src: `f(i int) {
+	match i {
+	case 1, -1:
+	case 0x1:
+	case -1:
+	case 2:
+	}
+}`,
+			warnings: []string{
+				"unreachable_case: unreachable case, value is already handled by previous case",
+				"unreachable_case: unreachable case, value is already handled by previous case",
+			},
+		},
+		{
+			name: "duplicate strings",
+			src: `f(s str) {
+	match s {
+	case "a", "b", "a":
+	}
+}`,
+			warnings: []string{"unreachable_case: unreachable case, value is already handled by previous case"},
+		},
+		{
+			name: "duplicate bool and missing",
+			src: `f(b bool) {
+	match b {
+	case true:
+	case true:
+	}
+}`,
+			warnings: []string{
+				"unreachable_case: unreachable case, value is already handled by previous case",
+				"match_not_exhaustive: match is not exhaustive, missing cases: false",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := parseMatch(t, c.src, false)
+			if len(p.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", p.Errors)
+			}
+			if got := logMessages(p.Warnings); !equalKeys(got, c.warnings) {
+				t.Errorf("warnings:\n%q\nwant:\n%q", got, c.warnings)
+			}
+		})
+	}
+}
+
+func TestMatchExhaustiveStrict(t *testing.T) {
+	src := `f(c color) {
+	match c {
+	case color.red:
+	case color.red:
+	}
+}`
+	p := parseMatch(t, src, true)
+	want := []string{"match_not_exhaustive: match is not exhaustive, missing cases: green, blue"}
+	if got := logMessages(p.Errors); !equalKeys(got, want) {
+		t.Errorf("errors:\n%q\nwant:\n%q", got, want)
+	}
+	// Unreachable cases are warnings even with strict setting.
+	want = []string{"unreachable_case: unreachable case, value is already handled by previous case"}
+	if got := logMessages(p.Warnings); !equalKeys(got, want) {
+		t.Errorf("warnings:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestMatchPositions(t *testing.T) {
+	src := `f(c color) {
+	match c {
+	case color.red:
+	case color.green, color.red:
+	}
+}`
+	p := parseMatch(t, src, false)
+	if len(p.Warnings) != 2 {
+		t.Fatalf("warnings are %v", logMessages(p.Warnings))
+	}
+	// Source starts after 6 rows of enum, tab is four columns.
+	positions := []struct {
+		key         string
+		row, column int
+	}{
+		{"unreachable_case", 10, 23},
+		{"match_not_exhaustive", 8, 5},
+	}
+	for i, pos := range positions {
+		w := p.Warnings[i]
+		if w.Key != pos.key || w.Row != pos.row || w.Column != pos.column {
+			t.Errorf("warning %d is %s at %d:%d, want %s at %d:%d",
+				i, w.Key, w.Row, w.Column, pos.key, pos.row, pos.column)
+		}
+	}
+}
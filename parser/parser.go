@@ -21,17 +21,15 @@
 package parser
 
 import (
-	"io/ioutil"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
-	"unicode"
 	"unicode/utf8"
 
 	"github.com/DeRuneLabs/jane/ast"
 	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jn"
@@ -60,8 +58,6 @@ type (
 	RetType     = models.RetType
 )
 
-var used []*use
-
 type waitingGlobal struct {
 	Var  *Var
 	Defs *Defmap
@@ -76,16 +72,22 @@ type Parser struct {
 	rootBlock      *models.Block
 	nodeBlock      *models.Block
 	generics       []*GenericType
-	blockTypes     []*Type
-	blockVars      []*Var
+	scope          *scope
+	locals         []*Var
 	waitingGlobals []waitingGlobal
+	comptimes      map[*Func]*ir.Block
 	eval           *eval
 	cppLinks       []*models.CppLink
+	pkg            *usePackage
+	deps           []*use
 
 	NoLocalPkg bool
 	JustDefs   bool
 	NoCheck    bool
 	IsMain     bool
+	IsTest     bool
+	TestFilter func(id string) bool
+	Env        *Env
 	Uses       []*use
 	Defs       *Defmap
 	Errors     []jnlog.CompilerLog
@@ -97,33 +99,51 @@ func New(f *File) *Parser {
 	p := new(Parser)
 	p.File = f
 	p.Defs = new(Defmap)
+	p.Env = DefaultEnv()
 	p.eval = new(eval)
 	p.eval.p = p
 	return p
 }
 
-func (p *Parser) pusherrtok(tok Tok, key string, args ...any) {
-	p.pusherrmsgtok(tok, jn.GetError(key, args...))
-}
-
-func (p *Parser) pusherrmsgtok(tok Tok, msg string) {
-	p.Errors = append(p.Errors, jnlog.CompilerLog{
-		Type:    jnlog.Error,
+func logOfTok(tok Tok, t uint8, key, msg string) jnlog.CompilerLog {
+	return jnlog.CompilerLog{
+		Type:    t,
 		Row:     tok.Row,
 		Column:  tok.Column,
+		Length:  utf8.RuneCountInString(tok.Kind),
 		Path:    tok.File.Path(),
+		Line:    tok.File.Line(tok.Row),
+		Key:     key,
 		Message: msg,
-	})
+	}
+}
+
+func relatedOfTok(tok Tok, label string) jnlog.Related {
+	return jnlog.Related{
+		Row:    tok.Row,
+		Column: tok.Column,
+		Length: utf8.RuneCountInString(tok.Kind),
+		Path:   tok.File.Path(),
+		Line:   tok.File.Line(tok.Row),
+		Label:  label,
+	}
+}
+
+func (p *Parser) pusherrtok(tok Tok, key string, args ...any) {
+	p.Errors = append(p.Errors, logOfTok(tok, jnlog.Error, key, jn.GetError(key, args...)))
+}
+
+// pushExistId pushes exist_id error of tok with previous declaration.
+func (p *Parser) pushExistId(tok, declared Tok, id string) {
+	log := logOfTok(tok, jnlog.Error, "exist_id", jn.GetError("exist_id", id))
+	if declared.File != nil {
+		log.Related = append(log.Related, relatedOfTok(declared, jn.GetNote("first_declared_here")))
+	}
+	p.Errors = append(p.Errors, log)
 }
 
 func (p *Parser) pushwarntok(tok Tok, key string, args ...any) {
-	p.Warnings = append(p.Warnings, jnlog.CompilerLog{
-		Type:    jnlog.Warning,
-		Row:     tok.Row,
-		Column:  tok.Column,
-		Path:    tok.File.Path(),
-		Message: jn.GetWarning(key, args...),
-	})
+	p.Warnings = append(p.Warnings, logOfTok(tok, jnlog.Warning, key, jn.GetWarning(key, args...)))
 }
 
 func (p *Parser) pusherrs(errs ...jnlog.CompilerLog) {
@@ -131,7 +151,11 @@ func (p *Parser) pusherrs(errs ...jnlog.CompilerLog) {
 }
 
 func (p *Parser) PushErr(key string, args ...any) {
-	p.pusherrmsg(jn.GetError(key, args...))
+	p.Errors = append(p.Errors, jnlog.CompilerLog{
+		Type:    jnlog.FlatError,
+		Key:     key,
+		Message: jn.GetError(key, args...),
+	})
 }
 
 func (p *Parser) pusherrmsg(msg string) {
@@ -144,241 +168,14 @@ func (p *Parser) pusherrmsg(msg string) {
 func (p *Parser) pushwarn(key string, args ...any) {
 	p.Warnings = append(p.Warnings, jnlog.CompilerLog{
 		Type:    jnlog.FlatWarning,
+		Key:     key,
 		Message: jn.GetWarning(key, args...),
 	})
 }
 
-func (p *Parser) CppLinks() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if use.cppLink {
-			cpp.WriteString(`#include "`)
-			cpp.WriteString(use.Path)
-			cpp.WriteString("\"\n")
-		}
-	}
-	return cpp.String()
-}
-
-func cppTypes(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, t := range dm.Types {
-		if t.Used && t.Tok.Id != tokens.NA {
-			cpp.WriteString(t.String())
-			cpp.WriteByte('\n')
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppTypes() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppTypes(use.defs))
-		}
-	}
-	cpp.WriteString(cppTypes(p.Defs))
-	return cpp.String()
-}
-
-func cppEnums(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, e := range dm.Enums {
-		if e.Used && e.Tok.Id != tokens.NA {
-			cpp.WriteString(e.String())
-			cpp.WriteString("\n\n")
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppEnums() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppEnums(use.defs))
-		}
-	}
-	cpp.WriteString(cppEnums(p.Defs))
-	return cpp.String()
-}
-
-func cppTraits(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, t := range dm.Traits {
-		if t.Used && t.Ast.Tok.Id != tokens.NA {
-			cpp.WriteString(t.String())
-			cpp.WriteString("\n\n")
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppTraits() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppTraits(use.defs))
-		}
-	}
-	cpp.WriteString(cppTraits(p.Defs))
-	return cpp.String()
-}
-
-func cppStructs(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, s := range dm.Structs {
-		if s.Used && s.Ast.Tok.Id != tokens.NA {
-			cpp.WriteString(s.String())
-			cpp.WriteString("\n\n")
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppStructs() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppStructs(use.defs))
-		}
-	}
-	cpp.WriteString(cppStructs(p.Defs))
-	return cpp.String()
-}
-
-func cppStructPrototypes(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, s := range dm.Structs {
-		if s.Used && s.Ast.Tok.Id != tokens.NA {
-			cpp.WriteString(s.prototype())
-			cpp.WriteByte('\n')
-		}
-	}
-	return cpp.String()
-}
-
-func cppFuncPrototypes(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, f := range dm.Funcs {
-		if f.used && f.Ast.Tok.Id != tokens.NA {
-			cpp.WriteString(f.Prototype())
-			cpp.WriteByte('\n')
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppPrototypes() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppStructPrototypes(use.defs))
-		}
-	}
-	cpp.WriteString(cppStructPrototypes(p.Defs))
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppFuncPrototypes(use.defs))
-		}
-	}
-	cpp.WriteString(cppFuncPrototypes(p.Defs))
-	return cpp.String()
-}
-
-func cppGlobals(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, g := range dm.Globals {
-		if !g.Const && g.Used && g.Token.Id != tokens.NA {
-			cpp.WriteString(g.String())
-			cpp.WriteByte('\n')
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppGlobals() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppGlobals(use.defs))
-		}
-	}
-	cpp.WriteString(cppGlobals(p.Defs))
-	return cpp.String()
-}
-
-func cppFuncs(dm *Defmap) string {
-	var cpp strings.Builder
-	for _, f := range dm.Funcs {
-		if f.used && f.Ast.Tok.Id != tokens.NA {
-			cpp.WriteString(f.String())
-			cpp.WriteString("\n\n")
-		}
-	}
-	return cpp.String()
-}
-
-func (p *Parser) CppFuncs() string {
-	var cpp strings.Builder
-	for _, use := range used {
-		if !use.cppLink {
-			cpp.WriteString(cppFuncs(use.defs))
-		}
-	}
-	cpp.WriteString(cppFuncs(p.Defs))
-	return cpp.String()
-}
-
-func (p *Parser) CppInitializerCaller() string {
-	var cpp strings.Builder
-	cpp.WriteString("void ")
-	cpp.WriteString(jnapi.InitializerCaller)
-	cpp.WriteString("(void) {")
-	models.AddIndent()
-	indent := models.IndentString()
-	models.DoneIndent()
-	pushInit := func(defs *Defmap) {
-		f, _, _ := defs.funcById(jn.InitializerFunction, nil)
-		if f == nil {
-			return
-		}
-		cpp.WriteByte('\n')
-		cpp.WriteString(indent)
-		cpp.WriteString(f.outId())
-		cpp.WriteString("();")
-	}
-	for _, use := range used {
-		if !use.cppLink {
-			pushInit(use.defs)
-		}
-	}
-	pushInit(p.Defs)
-	cpp.WriteString("\n}")
-	return cpp.String()
-}
-
-func (p *Parser) Cpp() string {
-	var cpp strings.Builder
-	cpp.WriteString(p.CppLinks())
-	cpp.WriteByte('\n')
-	cpp.WriteString(p.CppTypes())
-	cpp.WriteByte('\n')
-	cpp.WriteString(p.CppEnums())
-	cpp.WriteString(p.CppTraits())
-	cpp.WriteString(p.CppPrototypes())
-	cpp.WriteString(p.CppStructs())
-	cpp.WriteString("\n\n")
-	cpp.WriteString(p.CppGlobals())
-	cpp.WriteString("\n\n")
-	cpp.WriteString(p.CppFuncs())
-	cpp.WriteString(p.CppInitializerCaller())
-	return cpp.String()
-}
-
-func getTree(toks Toks) ([]models.Object, []jnlog.CompilerLog) {
+func (p *Parser) getTree(toks Toks) ([]models.Object, []jnlog.CompilerLog) {
 	b := ast.NewBuilder(toks)
+	b.StdlibPath = p.Env.StdlibPath
 	b.Build()
 	return b.Tree, b.Errors
 }
@@ -389,26 +186,21 @@ func (p *Parser) checkCppUsePath(use *models.Use) bool {
 		p.pusherrtok(use.Tok, "invalid_header_ext", ext)
 		return false
 	}
-	err := os.Chdir(use.Tok.File.Dir)
-	if err != nil {
-		p.pusherrtok(use.Tok, "use_not_found", use.Path)
-		return false
+	if !filepath.IsAbs(use.Path) {
+		use.Path = filepath.Join(use.Tok.File.Dir, use.Path)
 	}
-	info, err := os.Stat(use.Path)
-
+	info, err := p.Env.stat(use.Path)
 	if err != nil || info.IsDir() {
 		p.pusherrtok(use.Tok, "use_not_found", use.Path)
 		return false
 	}
-	use.Path, _ = filepath.Abs(use.Path)
-	_ = os.Chdir(jn.ExecPath)
 	return true
 }
 
 func (p *Parser) checkPureUsePath(use *models.Use) bool {
-	info, err := os.Stat(use.Path)
+	info, err := p.Env.stat(use.Path)
 	if err != nil || !info.IsDir() {
-		p.pusherrtok(use.Tok, "use_not_found", use.Path)
+		p.pusherrtokSuggest(use.Tok, use.LinkString, p.Env.StdPackages(), "use_not_found", use.Path)
 		return false
 	}
 	return true
@@ -442,7 +234,7 @@ func (p *Parser) pushSelects(use *use, selectors []Tok) (addNs bool) {
 			if j >= i {
 				break
 			} else if jid.Kind == id.Kind {
-				p.pusherrtok(id, "exist_id", id.Kind)
+				p.pushExistId(id, jid, id.Kind)
 				i = -1
 				break
 			}
@@ -456,7 +248,7 @@ func (p *Parser) pushSelects(use *use, selectors []Tok) (addNs bool) {
 		}
 		i, m, t := use.defs.findById(id.Kind, p.File)
 		if i == -1 {
-			p.pusherrtok(id, "id_noexist", id.Kind)
+			p.pusherrtokSuggest(id, id.Kind, use.defs.ids(), "id_noexist", id.Kind)
 			continue
 		}
 		switch t {
@@ -505,42 +297,28 @@ func (p *Parser) compileCppLinkUse(useAST *models.Use) (*use, bool) {
 }
 
 func (p *Parser) compilePureUse(useAST *models.Use) (_ *use, hassErr bool) {
-	infos, err := ioutil.ReadDir(useAST.Path)
-	if err != nil {
-		p.pusherrmsg(err.Error())
+	pkg, cycle := p.Env.load(p.pkg, useAST.Path)
+	if cycle {
+		p.pusherrtok(useAST.Tok, "use_cycle", useAST.LinkString)
 		return nil, true
 	}
-	for _, info := range infos {
-		name := info.Name()
-		if info.IsDir() ||
-			!strings.HasSuffix(name, jn.SrcExt) ||
-			!jnio.IsUseable(name) {
-			continue
-		}
-		f, err := jnio.OpenJn(filepath.Join(useAST.Path, name))
-		if err != nil {
-			p.pusherrmsg(err.Error())
-			continue
-		}
-		psub := New(f)
-		psub.Parsef(false, false)
-		use := new(use)
-		use.defs = new(Defmap)
-		use.tok = useAST.Tok
-		use.Path = useAST.Path
-		use.LinkString = useAST.LinkString
-		use.fullUse = useAST.FullUse
-		p.pusherrs(psub.Errors...)
-		p.Warnings = append(p.Warnings, psub.Warnings...)
-		p.pushDefs(use.defs, psub.Defs)
-		p.pushUse(use, useAST.Selectors)
-		if psub.Errors != nil {
-			p.pusherrtok(useAST.Tok, "use_has_errors")
-			return use, true
-		}
-		return use, false
+	p.pushPackageLogs(pkg)
+	if pkg.defs == nil {
+		return nil, len(pkg.errors) > 0
 	}
-	return nil, false
+	use := new(use)
+	use.defs = pkg.defs
+	use.deps = pkg.deps
+	use.tok = useAST.Tok
+	use.Path = useAST.Path
+	use.LinkString = useAST.LinkString
+	use.fullUse = useAST.FullUse
+	p.pushUse(use, useAST.Selectors)
+	if len(pkg.errors) > 0 {
+		p.pusherrtok(useAST.Tok, "use_has_errors")
+		return use, true
+	}
+	return use, false
 }
 
 func (p *Parser) compileUse(useAST *models.Use) (_ *use, hasErr bool) {
@@ -563,37 +341,47 @@ func (p *Parser) use(useAST *models.Use) (err bool) {
 	if !p.checkUsePath(useAST) {
 		return true
 	}
-	for _, use := range used {
-		if useAST.Path == use.Path {
-			p.pushUse(use, nil)
-			p.Uses = append(p.Uses, use)
-			return
-		}
-	}
 	use, err := p.compileUse(useAST)
 	if use == nil {
 		return err
 	}
-	used = append(used, use)
 	p.Uses = append(p.Uses, use)
+	p.deps = append(p.deps, use)
 	return err
 }
 
+// parseUses parses use declarations of tree and trims them.
+// Comments after last use declaration are kept for documentations.
 func (p *Parser) parseUses(tree *[]models.Object) (err bool) {
+	p.prefetchUses(*tree)
+	n := 0
 	for i, obj := range *tree {
 		switch t := obj.Data.(type) {
 		case models.Use:
-			err = err || p.use(&t)
+			n = i + 1
+			if err {
+				p.waitUse(&t)
+				break
+			}
+			err = p.use(&t)
 		case models.Comment:
 		default:
-			*tree = (*tree)[i:]
+			*tree = (*tree)[n:]
 			return
 		}
 	}
-	*tree = nil
+	*tree = (*tree)[n:]
 	return
 }
 
+// waitUse waits prefetched package of use declaration that not used
+// because of previous errors, so no parsing remains in background.
+func (p *Parser) waitUse(useAST *models.Use) {
+	if !useAST.Cpp {
+		_, _ = p.Env.load(p.pkg, useAST.Path)
+	}
+}
+
 func (p *Parser) parseSrcTreeObj(obj models.Object) {
 	switch t := obj.Data.(type) {
 	case Attribute:
@@ -659,34 +447,69 @@ func (p *Parser) checkParse() {
 	go p.check()
 }
 
+// fileTree is syntax tree of source file.
+type fileTree struct {
+	f      *File
+	tree   []models.Object
+	errors []jnlog.CompilerLog
+}
+
+// fileTrees reads and builds syntax trees of files concurrently.
+func (p *Parser) fileTrees(dir string, names []string) []fileTree {
+	trees := make([]fileTree, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(ft *fileTree, path string) {
+			defer wg.Done()
+			f, err := p.Env.open(path)
+			if err != nil {
+				ft.errors = []jnlog.CompilerLog{{Type: jnlog.FlatError, Message: err.Error()}}
+				return
+			}
+			ft.f = f
+			lex := lexer.NewLex(f)
+			toks := lex.Lex()
+			if lex.Logs != nil {
+				ft.errors = lex.Logs
+				return
+			}
+			ft.tree, ft.errors = p.getTree(toks)
+		}(&trees[i], filepath.Join(dir, name))
+	}
+	wg.Wait()
+	return trees
+}
+
 func (p *Parser) useLocalPackage(tree *[]models.Object) (hasErr bool) {
 	if p.File == nil {
 		return
 	}
-	infos, err := ioutil.ReadDir(p.File.Dir)
+	names, err := p.Env.sourceFiles(p.File.Dir)
 	if err != nil {
 		p.pusherrmsg(err.Error())
 		return true
 	}
-	for _, info := range infos {
-		name := info.Name()
-		if info.IsDir() ||
-			!strings.HasSuffix(name, jn.SrcExt) ||
-			!jnio.IsUseable(name) ||
-			name == p.File.Name {
-			continue
+	for i, name := range names {
+		if name == p.File.Name {
+			names = append(names[:i], names[i+1:]...)
+			break
 		}
-		f, err := jnio.OpenJn(filepath.Join(p.File.Dir, name))
-		if err != nil {
-			p.pusherrmsg(err.Error())
+	}
+	for _, ft := range p.fileTrees(p.File.Dir, names) {
+		if len(ft.errors) > 0 {
+			p.pusherrs(ft.errors...)
 			return true
 		}
-		fp := New(f)
+		fp := New(ft.f)
+		fp.Env = p.Env
+		fp.pkg = p.pkg
 		fp.NoLocalPkg = true
 		fp.NoCheck = true
 		fp.Defs = p.Defs
-		fp.Parsef(false, true)
+		fp.Parset(ft.tree, false, true)
 		fp.wg.Wait()
+		p.deps = append(p.deps, fp.deps...)
 		if len(fp.Errors) > 0 {
 			p.pusherrs(fp.Errors...)
 			return true
@@ -713,7 +536,7 @@ func (p *Parser) Parset(tree []models.Object, main, justDefs bool) {
 }
 
 func (p *Parser) Parse(toks Toks, main, justDefs bool) {
-	tree, errors := getTree(toks)
+	tree, errors := p.getTree(toks)
 	if len(errors) > 0 {
 		p.pusherrs(errors...)
 		return
@@ -777,7 +600,7 @@ func (p *Parser) Generics(generics []GenericType) {
 			if j >= i {
 				break
 			} else if generic.Id == cgeneric.Id {
-				p.pusherrtok(generic.Tok, "exist_id", generic.Id)
+				p.pushExistId(generic.Tok, cgeneric.Tok, generic.Id)
 				break
 			}
 		}
@@ -790,7 +613,7 @@ func (p *Parser) Generics(generics []GenericType) {
 func (p *Parser) Type(t Type) {
 	_, tok, canshadow := p.defById(t.Id)
 	if tok.Id != tokens.NA && !canshadow {
-		p.pusherrtok(t.Tok, "exist_id", t.Id)
+		p.pushExistId(t.Tok, tok, t.Id)
 		return
 	} else if jnapi.IsIgnoreId(t.Id) {
 		p.pusherrtok(t.Tok, "ignore_id")
@@ -806,7 +629,7 @@ func (p *Parser) Enum(e Enum) {
 		p.pusherrtok(e.Tok, "ignore_id")
 		return
 	} else if _, tok, _ := p.defById(e.Id); tok.Id != tokens.NA {
-		p.pusherrtok(e.Tok, "exist_id", e.Id)
+		p.pushExistId(e.Tok, tok, e.Id)
 		return
 	}
 	e.Desc = p.docText.String()
@@ -842,7 +665,7 @@ func (p *Parser) Enum(e Enum) {
 					break
 				}
 				if item.Id == checkItem.Id {
-					p.pusherrtok(item.Tok, "exist_id", item.Id)
+					p.pushExistId(item.Tok, checkItem.Tok, item.Id)
 					break
 				}
 			}
@@ -858,7 +681,7 @@ func (p *Parser) Enum(e Enum) {
 				errtok:    item.Tok,
 			}.checkAssignType()
 		} else {
-			item.Expr.Model = exprNode{strconv.Itoa(i)}
+			item.Expr.Model = enumItemModel(e.Type, i)
 		}
 		itemVar := new(Var)
 		itemVar.Const = true
@@ -874,7 +697,7 @@ func (p *Parser) pushField(s *jnstruct, f *Var, i int) {
 			break
 		}
 		if f.Id == cf.Id {
-			p.pusherrtok(f.Token, "exist_id", f.Id)
+			p.pushExistId(f.Token, cf.Token, f.Id)
 			break
 		}
 	}
@@ -883,7 +706,7 @@ func (p *Parser) pushField(s *jnstruct, f *Var, i int) {
 	} else {
 		p.parseNonGenericType(s.Ast.Generics, &f.Type)
 		param := models.Param{Id: f.Id, Type: f.Type}
-		param.Default.Model = exprNode{jnapi.DefaultExpr}
+		param.Default.Model = new(ir.Default)
 		s.constructor.Params[i] = param
 	}
 }
@@ -916,7 +739,7 @@ func (p *Parser) Struct(s Struct) {
 		p.pusherrtok(s.Tok, "ignore_id")
 		return
 	} else if _, tok, _ := p.defById(s.Id); tok.Id != tokens.NA {
-		p.pusherrtok(s.Tok, "exist_id", s.Id)
+		p.pushExistId(s.Tok, tok, s.Id)
 		return
 	}
 	xs := new(jnstruct)
@@ -935,8 +758,8 @@ func (p *Parser) CppLink(link models.CppLink) {
 	if jnapi.IsIgnoreId(link.Link.Id) {
 		p.pusherrtok(link.Tok, "ignore_id")
 		return
-	} else if p.linkById(link.Link.Id) != nil {
-		p.pusherrtok(link.Tok, "exist_id", link.Link.Id)
+	} else if def := p.linkById(link.Link.Id); def != nil {
+		p.pushExistId(link.Tok, def.Tok, link.Link.Id)
 		return
 	}
 	linkf := link.Link
@@ -951,7 +774,7 @@ func (p *Parser) Trait(t models.Trait) {
 		p.pusherrtok(t.Tok, "ignore_id")
 		return
 	} else if _, tok, _ := p.defById(t.Id); tok.Id != tokens.NA {
-		p.pusherrtok(t.Tok, "exist_id", t.Id)
+		p.pushExistId(t.Tok, tok, t.Id)
 		return
 	}
 	trait := new(trait)
@@ -968,7 +791,7 @@ func (p *Parser) Trait(t models.Trait) {
 			if j >= i {
 				break
 			} else if f.Id == jf.Id {
-				p.pusherrtok(f.Tok, "exist_id", f.Id)
+				p.pushExistId(f.Tok, jf.Tok, f.Id)
 			}
 		}
 		_ = p.checkParamDup(f.Params)
@@ -983,14 +806,14 @@ func (p *Parser) Trait(t models.Trait) {
 func (p *Parser) implTrait(impl models.Impl) {
 	trait, _, _ := p.traitById(impl.Trait.Kind)
 	if trait == nil {
-		p.pusherrtok(impl.Trait, "id_noexist", impl.Trait.Kind)
+		p.pusherrtokSuggest(impl.Trait, impl.Trait.Kind, p.Defs.traitIds(), "id_noexist", impl.Trait.Kind)
 		return
 	}
 	trait.Used = true
 	sid, _ := impl.Target.KindId()
 	xs, _, _ := p.Defs.structById(sid, nil)
 	if xs == nil {
-		p.pusherrtok(impl.Target.Tok, "id_noexist", sid)
+		p.pusherrtokSuggest(impl.Target.Tok, sid, p.Defs.structIds(), "id_noexist", sid)
 		return
 	}
 	impl.Target.Tag = xs
@@ -1019,7 +842,7 @@ func (p *Parser) implTrait(impl models.Impl) {
 			p.Comment(t)
 		case *Func:
 			if trait.FindFunc(t.Id) == nil {
-				p.pusherrtok(impl.Target.Tok, "trait_hasnt_id", trait.Ast.Id, t.Id)
+				p.pusherrtokSuggest(impl.Target.Tok, t.Id, trait.Defs.ids(), "trait_hasnt_id", trait.Ast.Id, t.Id)
 				break
 			}
 			i, _, _ := xs.Defs.findById(t.Id, nil)
@@ -1048,7 +871,7 @@ func (p *Parser) implTrait(impl models.Impl) {
 func (p *Parser) implStruct(impl models.Impl) {
 	xs, _, _ := p.Defs.structById(impl.Trait.Kind, nil)
 	if xs == nil {
-		p.pusherrtok(impl.Trait, "id_noexist", impl.Trait.Kind)
+		p.pusherrtokSuggest(impl.Trait, impl.Trait.Kind, p.Defs.structIds(), "id_noexist", impl.Trait.Kind)
 		return
 	}
 	for _, obj := range impl.Tree {
@@ -1151,19 +974,6 @@ func (p *Parser) PushAttribute(attribute Attribute) {
 	p.attributes = append(p.attributes, attribute)
 }
 
-func genericsToCpp(generics []*GenericType) string {
-	if len(generics) == 0 {
-		return ""
-	}
-	var cpp strings.Builder
-	cpp.WriteString("template<")
-	for _, generic := range generics {
-		cpp.WriteString(generic.String())
-		cpp.WriteByte(',')
-	}
-	return cpp.String()[:cpp.Len()-1] + ">"
-}
-
 func (p *Parser) Statement(s models.Statement) {
 	switch t := s.Data.(type) {
 	case Func:
@@ -1372,7 +1182,7 @@ func setGenerics(f *Func, generics []*models.GenericType) {
 func (p *Parser) Func(fast Func) {
 	_, tok, canshadow := p.defById(fast.Id)
 	if tok.Id != tokens.NA && !canshadow {
-		p.pusherrtok(fast.Tok, "exist_id", fast.Id)
+		p.pushExistId(fast.Tok, tok, fast.Id)
 	} else if jnapi.IsIgnoreId(fast.Id) {
 		p.pusherrtok(fast.Tok, "ignore_id")
 	}
@@ -1394,14 +1204,14 @@ func (p *Parser) Func(fast Func) {
 }
 
 func (p *Parser) Global(vast Var) {
-	def, _, _ := p.defById(vast.Id)
+	def, tok, _ := p.defById(vast.Id)
 	if def != nil {
-		p.pusherrtok(vast.Token, "exist_id", vast.Id)
+		p.pushExistId(vast.Token, tok, vast.Id)
 		return
 	} else {
 		for _, g := range p.waitingGlobals {
 			if vast.Id == g.Var.Id {
-				p.pusherrtok(vast.Token, "exist_id", vast.Id)
+				p.pushExistId(vast.Token, g.Var.Token, vast.Id)
 				return
 			}
 		}
@@ -1495,12 +1305,37 @@ func (p *Parser) checkTypeParam(f *function) {
 	}
 }
 
+func (p *Parser) checkTestFunc(f *function) {
+	if len(f.Ast.Generics) != 0 {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_generics_if_has_attribute", jn.Attribute_Test)
+	}
+	if len(f.Ast.Params) != 0 {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_params_if_has_attribute", jn.Attribute_Test)
+	}
+	if f.Ast.RetType.Type.Id != jntype.Void {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_ret_if_has_attribute", jn.Attribute_Test)
+	}
+}
+
+func (p *Parser) checkComptimeFunc(f *function) {
+	if len(f.Ast.Generics) != 0 {
+		p.pusherrtok(f.Ast.Tok, "func_cant_have_generics_if_has_attribute", jn.Attribute_Comptime)
+	}
+	if f.Ast.RetType.Type.Id == jntype.Void {
+		p.pusherrtok(f.Ast.Tok, "func_must_have_ret_if_has_attribute", jn.Attribute_Comptime)
+	}
+}
+
 func (p *Parser) checkFuncAttributes(f *function) {
 	for _, attribute := range f.Ast.Attributes {
 		switch attribute.Tag {
 		case jn.Attribute_Inline:
 		case jn.Attribute_TypeArg:
 			p.checkTypeParam(f)
+		case jn.Attribute_Test:
+			p.checkTestFunc(f)
+		case jn.Attribute_Comptime:
+			p.checkComptimeFunc(f)
 		default:
 			p.pusherrtok(attribute.Tok, "invalid_attribute")
 		}
@@ -1540,129 +1375,13 @@ func (p *Parser) linkById(id string) *models.CppLink {
 	return nil
 }
 
-func (p *Parser) FuncById(id string) (*function, *Defmap, bool) {
-	f, _, _ := Builtin.funcById(id, nil)
-	if f != nil {
-		return f, nil, false
-	}
-	return p.Defs.funcById(id, p.File)
-}
-
-func (p *Parser) globalById(id string) (*Var, *Defmap, bool) {
-	g, m, _ := p.Defs.globalById(id, p.File)
-	return g, m, true
-}
-
-func (p *Parser) nsById(id string) *namespace {
-	return p.Defs.nsById(id)
-}
-
-func (p *Parser) typeById(id string) (*Type, *Defmap, bool) {
-	t := p.blockTypeById(id)
-	if t != nil {
-		return t, nil, false
-	}
-	t, _, _ = Builtin.typeById(id, nil)
-	if t != nil {
-		return t, nil, false
-	}
-	return p.Defs.typeById(id, p.File)
-}
-
-func (p *Parser) enumById(id string) (*Enum, *Defmap, bool) {
-	s, _, _ := Builtin.enumById(id, nil)
-	if s != nil {
-		return s, nil, false
-	}
-	return p.Defs.enumById(id, p.File)
-}
-
-func (p *Parser) structById(id string) (*jnstruct, *Defmap, bool) {
-	s, _, _ := Builtin.structById(id, nil)
-	if s != nil {
-		return s, nil, false
-	}
-	return p.Defs.structById(id, p.File)
-}
-
-func (p *Parser) traitById(id string) (*trait, *Defmap, bool) {
-	t, _, _ := Builtin.traitById(id, nil)
-	if t != nil {
-		return t, nil, false
-	}
-	return p.Defs.traitById(id, p.File)
-}
-
-func (p *Parser) blockTypeById(id string) *Type {
-	for _, t := range p.blockTypes {
-		if t != nil && t.Id == id {
-			return t
-		}
-	}
-	return nil
-}
-
-func (p *Parser) blockVarById(id string) *Var {
-	for _, v := range p.blockVars {
-		if v != nil && v.Id == id {
-			return v
-		}
-	}
-	return nil
-}
-
-func (p *Parser) defById(id string) (def any, tok Tok, canshadow bool) {
-	var t *Type
-	t, _, canshadow = p.typeById(id)
-	if t != nil {
-		return t, t.Tok, canshadow
-	}
-	var e *Enum
-	e, _, canshadow = p.enumById(id)
-	if e != nil {
-		return e, e.Tok, canshadow
-	}
-	var s *jnstruct
-	s, _, canshadow = p.structById(id)
-	if s != nil {
-		return s, s.Ast.Tok, canshadow
-	}
-	var trait *trait
-	trait, _, canshadow = p.traitById(id)
-	if trait != nil {
-		return trait, trait.Ast.Tok, canshadow
-	}
-	var f *function
-	f, _, canshadow = p.FuncById(id)
-	if f != nil {
-		return f, f.Ast.Tok, canshadow
-	}
-	bv := p.blockVarById(id)
-	if bv != nil {
-		return bv, bv.Token, false
-	}
-	g, _, _ := p.globalById(id)
-	if g != nil {
-		return g, g.Token, true
-	}
-	return
-}
-
-func (p *Parser) blockDefById(id string) (def any, tok Tok) {
-	bv := p.blockVarById(id)
-	if bv != nil {
-		return bv, bv.Token
-	}
-	t := p.blockTypeById(id)
-	if t != nil {
-		return t, t.Tok
-	}
-	return
-}
-
 func (p *Parser) check() {
 	defer p.wg.Done()
-	if p.IsMain && !p.JustDefs {
+	p.Env.checking.Lock()
+	defer p.Env.checking.Unlock()
+	if p.IsTest && !p.JustDefs {
+		p.useTests()
+	} else if p.IsMain && !p.JustDefs {
 		f, _, _ := p.Defs.funcById(jn.EntryPoint, nil)
 		if f == nil {
 			p.PushErr("no_entry_point")
@@ -1705,11 +1424,9 @@ func (p *Parser) checkParamDefaultExpr(f *Func, param *Param) {
 	if !paramHasDefaultArg(param) || param.Tok.Id == tokens.NA {
 		return
 	}
-	if param.Default.Model != nil {
-		if param.Default.Model.String() == jnapi.DefaultExpr {
-			p.checkParamDefaultExprWithDefault(param)
-			return
-		}
+	if _, ok := param.Default.Model.(*ir.Default); ok {
+		p.checkParamDefaultExprWithDefault(param)
+		return
 	}
 	dt := param.Type
 	if param.Variadic {
@@ -1745,7 +1462,7 @@ func (p *Parser) checkParamDup(params []models.Param) (err bool) {
 				break
 			} else if param.Id == jparam.Id {
 				err = true
-				p.pusherrtok(param.Tok, "exist_id", param.Id)
+				p.pushExistId(param.Tok, jparam.Tok, param.Id)
 			}
 		}
 	}
@@ -1787,23 +1504,29 @@ func (p *Parser) parsePureFunc(f *Func) (err bool) {
 	if err {
 		return
 	}
-	owner.blockVars = owner.blockVarsOfFunc(f)
+	owner.scope = newScope(owner.scope, scopeFunc)
+	for _, v := range owner.blockVarsOfFunc(f) {
+		owner.scope.pushVar(v)
+	}
+	owner.locals = append(owner.locals, owner.scope.varList...)
 	owner.checkFunc(f)
 	if owner != p {
 		owner.wg.Wait()
 		p.pusherrs(owner.Errors...)
 		owner.Errors = nil
 	}
-	owner.blockTypes = nil
-	owner.blockVars = nil
+	owner.scope = nil
 	return
 }
 
 func (p *Parser) parseFunc(f *function) (err bool) {
-	if f.checked || len(f.Ast.Generics) > 0 {
+	if f.checked || f.checking || len(f.Ast.Generics) > 0 {
 		return false
 	}
-	return p.parsePureFunc(f.Ast)
+	f.checking = true
+	err = p.parsePureFunc(f.Ast)
+	f.checking = false
+	return
 }
 
 func (p *Parser) checkFuncs() {
@@ -1818,7 +1541,7 @@ func (p *Parser) checkFuncs() {
 		if err {
 			return
 		}
-		p.blockTypes = nil
+		p.scope = nil
 		err = p.parseFunc(f)
 		f.checked = true
 	}
@@ -1843,7 +1566,7 @@ func (p *Parser) checkStruct(s *jnstruct) (err bool) {
 		if f.checked {
 			continue
 		}
-		p.blockTypes = nil
+		p.scope = nil
 		err = p.parseStructFunc(s, f)
 		if err {
 			break
@@ -1874,13 +1597,24 @@ func (p *Parser) checkFuncSpecialCases(f *Func) {
 	}
 }
 
-func (p *Parser) callFunc(f *Func, data callData, m *exprModel) value {
-	v := p.parseFuncCallToks(f, data.generics, data.args, m)
+func (p *Parser) callFunc(f *Func, fn ir.Expr, data callData) value {
+	if p.Env.isBuiltinFunc(f, "offsetof") {
+		return p.callOffsetof(data)
+	}
+	v := p.parseFuncCallToks(f, fn, data.generics, data.args, data.parsed)
+	switch {
+	case p.Env.isBuiltinFunc(f, "sizeof"):
+		p.foldLayout(&v, false)
+	case p.Env.isBuiltinFunc(f, "alignof"):
+		p.foldLayout(&v, true)
+	case p.Env.isBuiltinFunc(f, "static_assert"):
+		p.staticAssert(&v, data.expr[0])
+	}
 	v.lvalue = typeIsLvalue(v.data.Type)
 	return v
 }
 
-func (p *Parser) callStructConstructor(s *jnstruct, argsToks Toks, m *exprModel) (v value) {
+func (p *Parser) callStructConstructor(s *jnstruct, args *models.Args) (v value) {
 	f := s.constructor
 	s = f.RetType.Type.Tag.(*jnstruct)
 	v.data.Type = f.RetType.Type.Copy()
@@ -1889,16 +1623,11 @@ func (p *Parser) callStructConstructor(s *jnstruct, argsToks Toks, m *exprModel)
 	v.lvalue = false
 	v.constExpr = false
 	v.data.Value = s.Ast.Id
-	argsToks[0].Kind = tokens.LPARENTHESES
-	argsToks[len(argsToks)-1].Kind = tokens.RPARENTHESES
-	args := p.getArgs(argsToks, true)
-	m.appendSubNode(exprNode{f.RetType.String()})
-	m.appendSubNode(exprNode{tokens.LPARENTHESES})
-	p.parseArgs(f, args, m, f.Tok)
-	if m != nil {
-		m.appendSubNode(argsExpr{args.Src})
-	}
-	m.appendSubNode(exprNode{tokens.RPARENTHESES})
+	if args == nil {
+		return v
+	}
+	p.parseArgs(f, args, f.Tok)
+	v.model = &ir.StructLit{Type: v.data.Type, Args: argModels(args)}
 	return v
 }
 
@@ -1912,7 +1641,7 @@ func (p *Parser) parseField(s *jnstruct, f **Var, i int) {
 	if hasExpr(v.Expr) {
 		param.Default = v.Expr
 	} else {
-		param.Default.Model = exprNode{jnapi.DefaultExpr}
+		param.Default.Model = new(ir.Default)
 	}
 	s.constructor.Params[i] = param
 }
@@ -1938,16 +1667,19 @@ func (p *Parser) structConstructorInstance(as *jnstruct) *jnstruct {
 func (p *Parser) checkAnonFunc(f *Func) {
 	p.reloadFuncTypes(f)
 	globals := p.Defs.Globals
-	blockVariables := p.blockVars
-	p.Defs.Globals = append(blockVariables, p.Defs.Globals...)
-	p.blockVars = p.varsFromParams(f.Params)
+	outer := p.scope
+	p.Defs.Globals = append(outer.visibleVars(), p.Defs.Globals...)
+	p.scope = newScope(outer, scopeFunc)
+	for _, v := range p.varsFromParams(f.Params) {
+		p.scope.pushVar(v)
+	}
 	rootBlock := p.rootBlock
 	nodeBlock := p.nodeBlock
 	p.checkFunc(f)
 	p.rootBlock = rootBlock
 	p.nodeBlock = nodeBlock
 	p.Defs.Globals = globals
-	p.blockVars = blockVariables
+	p.scope = outer
 }
 
 func (p *Parser) getArgs(toks Toks, targeting bool) *models.Args {
@@ -2020,7 +1752,7 @@ func (p *Parser) pushGeneric(generic *GenericType, source DataType) {
 		Used:    true,
 		Generic: true,
 	}
-	p.blockTypes = append(p.blockTypes, t)
+	p.pushBlockType(t)
 }
 
 func (p *Parser) pushGenerics(generics []*GenericType, sources []DataType) {
@@ -2094,7 +1826,7 @@ ok:
 	return true
 }
 
-func (p *Parser) parseFuncCall(f *Func, args *models.Args, m *exprModel, errTok Tok) (v value) {
+func (p *Parser) parseFuncCall(f *Func, fn ir.Expr, args *models.Args, errTok Tok) (v value) {
 	args.NeedsPureType = p.rootBlock == nil || len(p.rootBlock.Func.Generics) == 0
 	if len(f.Generics) > 0 {
 		params := make([]Param, len(f.Params))
@@ -2103,13 +1835,13 @@ func (p *Parser) parseFuncCall(f *Func, args *models.Args, m *exprModel, errTok
 		owner := f.Owner.(*Parser)
 		rootBlock := owner.rootBlock
 		nodeBlock := owner.nodeBlock
-		blockVars := owner.blockVars
-		blockTypes := owner.blockTypes
+		outer := owner.scope
+		// generics are pushed to new scope
+		owner.scope = newScope(outer, scopeBlock)
 		defer func() {
 			owner.rootBlock = rootBlock
 			owner.nodeBlock = nodeBlock
-			owner.blockVars = blockVars
-			owner.blockTypes = blockTypes
+			owner.scope = outer
 
 			for i := range params {
 				params[i].Type.Generic = f.Params[i].Type.Generic
@@ -2133,19 +1865,16 @@ func (p *Parser) parseFuncCall(f *Func, args *models.Args, m *exprModel, errTok
 			}
 		}
 	}
+	var call *ir.Call
 	if args == nil {
 		goto end
 	}
-	p.parseArgs(f, args, m, errTok)
+	call = &ir.Call{Fn: fn, Generics: args.Generics}
+	call.TupleArgs = p.parseArgs(f, args, errTok)
 	if len(args.Generics) > 0 {
 		p.parseGenericFunc(f, args.Generics, errTok)
 	}
-	if m != nil {
-		m.appendSubNode(callExpr{
-			generics: genericsExpr{args.Generics},
-			args:     argsExpr{args.Src},
-		})
-	}
+	call.Args = argModels(args)
 end:
 	v.data.Value = f.Id
 	v.data.Type = f.RetType.Type.Copy()
@@ -2153,10 +1882,39 @@ end:
 		v.data.Type.Pure = true
 		v.data.Type.Original = nil
 	}
+	if call != nil {
+		call.Type = v.data.Type
+		v.model = call
+		if f.FindAttribute(jn.Attribute_Comptime) != nil {
+			p.comptimeCall(f, call, &v, errTok)
+		}
+	}
 	return
 }
 
-func (p *Parser) parseFuncCallToks(f *Func, genericsToks, argsToks Toks, m *exprModel) (v value) {
+func argModels(args *models.Args) []ir.Expr {
+	exprs := make([]ir.Expr, len(args.Src))
+	for i, arg := range args.Src {
+		exprs[i] = arg.Expr.Model
+	}
+	return exprs
+}
+
+// callArgs returns copy of parsed arguments of call,
+// arguments are parsed from tokens if parsed is nil.
+// Evaluation writes models of arguments, so parsed arguments are not changed.
+func (p *Parser) callArgs(toks Toks, parsed *models.Args) *models.Args {
+	if parsed == nil {
+		return p.getArgs(toks, false)
+	}
+	args := new(models.Args)
+	*args = *parsed
+	args.Src = make([]Arg, len(parsed.Src))
+	copy(args.Src, parsed.Src)
+	return args
+}
+
+func (p *Parser) parseFuncCallToks(f *Func, fn ir.Expr, genericsToks, argsToks Toks, parsed *models.Args) (v value) {
 	var generics []DataType
 	var args *models.Args
 	if f.FindAttribute(jn.Attribute_TypeArg) != nil {
@@ -2179,10 +1937,14 @@ func (p *Parser) parseFuncCallToks(f *Func, genericsToks, argsToks Toks, m *expr
 			p.eval.hasError = true
 			return
 		}
-		args = p.getArgs(argsToks, false)
+		args = p.callArgs(argsToks, parsed)
+		if args == nil {
+			p.eval.hasError = true
+			return
+		}
 		args.Generics = generics
 	}
-	return p.parseFuncCall(f, args, m, argsToks[0])
+	return p.parseFuncCall(f, fn, args, argsToks[0])
 }
 
 func (p *Parser) parseStructArgs(f *Func, args *models.Args, errTok Tok) {
@@ -2195,27 +1957,29 @@ func (p *Parser) parseStructArgs(f *Func, args *models.Args, errTok Tok) {
 	sap.parse()
 }
 
-func (p *Parser) parsePureArgs(f *Func, args *models.Args, m *exprModel, errTok Tok) {
+// parsePureArgs parses arguments and reports whether
+// multiple returns of single argument passed as arguments.
+func (p *Parser) parsePureArgs(f *Func, args *models.Args, errTok Tok) (tupleArgs bool) {
 	pap := pureArgParser{
 		p:      p,
 		f:      f,
 		args:   args,
 		errTok: errTok,
-		m:      m,
 	}
 	pap.parse()
+	return pap.tupleArgs
 }
 
-func (p *Parser) parseArgs(f *Func, args *models.Args, m *exprModel, errTok Tok) {
+func (p *Parser) parseArgs(f *Func, args *models.Args, errTok Tok) (tupleArgs bool) {
 	if args.Targeted {
 		p.parseStructArgs(f, args, errTok)
-		return
+		return false
 	}
-	p.parsePureArgs(f, args, m, errTok)
+	return p.parsePureArgs(f, args, errTok)
 }
 
 func hasExpr(expr Expr) bool {
-	return len(expr.Processes) > 0 || expr.Model != nil
+	return expr.Tree != nil || expr.Model != nil
 }
 
 func paramHasDefaultArg(param *Param) bool {
@@ -2383,7 +2147,7 @@ func (p *Parser) checkSolidFuncSpecialCases(f *Func) {
 	}
 }
 
-func (p *Parser) checkNewBlockCustom(b *models.Block, oldBlockVars []*Var) {
+func (p *Parser) checkNewBlockCustom(b *models.Block, s *scope) {
 	b.Gotos = new(models.Gotos)
 	b.Labels = new(models.Labels)
 	if p.rootBlock == nil {
@@ -2402,27 +2166,24 @@ func (p *Parser) checkNewBlockCustom(b *models.Block, oldBlockVars []*Var) {
 		p.nodeBlock = b
 		defer func() { p.nodeBlock = oldNode }()
 	}
-	blockTypes := p.blockTypes
+	p.scope = s
 	p.checkBlock(b)
 
-	vars := p.blockVars[len(oldBlockVars):]
-	types := p.blockTypes[len(blockTypes):]
-	for _, v := range vars {
+	for _, v := range s.varList {
 		if !v.Used {
 			p.pusherrtok(v.Token, "declared_but_not_used", v.Id)
 		}
 	}
-	for _, t := range types {
+	for _, t := range s.typeList {
 		if !t.Used {
 			p.pusherrtok(t.Tok, "declared_but_not_used", t.Id)
 		}
 	}
-	p.blockVars = oldBlockVars
-	p.blockTypes = blockTypes
+	p.scope = s.parent
 }
 
 func (p *Parser) checkNewBlock(b *models.Block) {
-	p.checkNewBlockCustom(b, p.blockVars)
+	p.checkNewBlockCustom(b, newScope(p.scope, scopeBlock))
 }
 
 func (p *Parser) statement(s *models.Statement, recover bool) bool {
@@ -2445,15 +2206,15 @@ func (p *Parser) statement(s *models.Statement, recover bool) bool {
 	case models.Continue:
 		p.continueStatement(&t)
 	case Type:
-		if def, _ := p.blockDefById(t.Id); def != nil {
-			p.pusherrtok(t.Tok, "exist_id", t.Id)
+		if def, tok := p.blockDefById(t.Id); def != nil {
+			p.pushExistId(t.Tok, tok, t.Id)
 			break
 		} else if jnapi.IsIgnoreId(t.Id) {
 			p.pusherrtok(t.Tok, "ignore_id")
 			break
 		}
 		t.Type, _ = p.realType(t.Type, true)
-		p.blockTypes = append(p.blockTypes, &t)
+		p.pushBlockType(&t)
 	case *models.Block:
 		p.checkNewBlock(t)
 		s.Data = t
@@ -2521,60 +2282,37 @@ func (p *Parser) checkBlock(b *models.Block) {
 	}
 }
 
-func (p *Parser) recoverFuncExprStatement(s *models.ExprStatement) {
-	errtok := s.Expr.Processes[0][0]
-	callToks := s.Expr.Processes[0][1:]
-	args := p.getArgs(callToks, false)
-	handleParam := recoverFunc.Ast.Params[0]
+func (p *Parser) recoverFuncExprStatement(s *models.ExprStatement, call *models.CallExpr) {
+	errtok := call.Toks[0]
+	args := p.getArgs(call.Range, false)
+	handleParam := p.Env.recoverFunc().Ast.Params[0]
 	if len(args.Src) == 0 {
 		p.pusherrtok(errtok, "missing_expr_for", handleParam.Id)
 		return
 	} else if len(args.Src) > 1 {
 		p.pusherrtok(errtok, "argument_overflow")
 	}
-	v, _ := p.evalExpr(args.Src[0].Expr)
+	v, model := p.evalExpr(args.Src[0].Expr)
 	if v.data.Type.Kind != handleParam.Type.Kind {
 		p.eval.pusherrtok(errtok, "incompatible_datatype", handleParam.Type.Kind, v.data.Type.Kind)
 		return
 	}
 	handler := v.data.Type.Tag.(*Func)
-	s.Expr.Model = exprNode{"try{\n"}
-	var catcher serieExpr
-	catcher.exprs = append(catcher.exprs, "} catch(trait<XID(Error)> ")
-	catcher.exprs = append(catcher.exprs, handler.Params[0].OutId())
-	catcher.exprs = append(catcher.exprs, ") ")
-	r, _ := utf8.DecodeRuneInString(v.data.Value)
-	if r == '_' || unicode.IsLetter(r) {
-		catcher.exprs = append(catcher.exprs, "{")
-		catcher.exprs = append(catcher.exprs, handler.OutId())
-		catcher.exprs = append(catcher.exprs, "(")
-		catcher.exprs = append(catcher.exprs, handler.Params[0].OutId())
-		catcher.exprs = append(catcher.exprs, "); }")
-	} else {
-		catcher.exprs = append(catcher.exprs, handler.Block)
-	}
-	catchExpr := models.Statement{
-		Data: models.ExprStatement{
-			Expr: models.Expr{Model: catcher},
-		},
-	}
-	p.nodeBlock.Tree = append(p.nodeBlock.Tree, catchExpr)
+	s.Expr.Model = &recoverExpr{handler: model, param: &handler.Params[0]}
 }
 
 func (p *Parser) exprStatement(s *models.ExprStatement, recover bool) {
-	if s.Expr.Processes != nil && !isOperator(s.Expr.Processes[0]) {
-		process := s.Expr.Processes[0]
-		tok := process[0]
-		if tok.Id == tokens.Id && tok.Kind == recoverFunc.Ast.Id {
-			if ast.IsFuncCall(s.Expr.Toks) != nil {
-				if !recover {
-					p.pusherrtok(tok, "invalid_syntax")
-				}
-				def, _, _ := p.defById(tok.Kind)
-				if def == recoverFunc {
-					p.recoverFuncExprStatement(s)
-					return
-				}
+	if call, ok := s.Expr.Tree.(*models.CallExpr); ok {
+		id, _ := call.Fn.(*models.IdExpr)
+		recoverFunc := p.Env.recoverFunc()
+		if id != nil && id.Tok.Id == tokens.Id && id.Tok.Kind == recoverFunc.Ast.Id {
+			if !recover {
+				p.pusherrtok(id.Tok, "invalid_syntax")
+			}
+			def, _, _ := p.defById(id.Tok.Kind)
+			if def == recoverFunc {
+				p.recoverFuncExprStatement(s, call)
+				return
 			}
 		}
 	}
@@ -2611,7 +2349,7 @@ func (p *Parser) cases(m *models.Match, t DataType) {
 }
 
 func (p *Parser) matchcase(t *models.Match) {
-	if len(t.Expr.Processes) > 0 {
+	if t.Expr.Tree != nil {
 		value, model := p.evalExpr(t.Expr)
 		t.Expr.Model = model
 		t.ExprType = value.data.Type
@@ -2623,6 +2361,8 @@ func (p *Parser) matchcase(t *models.Match) {
 	if t.Default != nil {
 		p.parseCase(t.Default, t.ExprType)
 	}
+	mc := matchChecker{p, t}
+	mc.check()
 }
 
 func (p *Parser) findLabel(id string) *models.Label {
@@ -2767,13 +2507,8 @@ func (p *Parser) checkLabelNGoto() {
 }
 
 func (p *Parser) checkRets(f *Func) {
-	if f.Block != nil {
-		for _, s := range f.Block.Tree {
-			switch s.Data.(type) {
-			case models.Ret:
-				return
-			}
-		}
+	if f.Block != nil && !newFlowChecker(p).check(f.Block) {
+		return
 	}
 	if !typeIsVoid(f.RetType.Type) {
 		p.pusherrtok(f.Tok, "missing_ret")
@@ -2799,24 +2534,21 @@ always:
 
 func (p *Parser) varStatement(v *Var, noParse bool) {
 	if _, tok := p.blockDefById(v.Id); tok.Id != tokens.NA {
-		p.pusherrtok(v.Token, "exist_id", v.Id)
+		p.pushExistId(v.Token, tok, v.Id)
 	}
 	if !noParse {
 		*v = *p.Var(*v)
 	}
 	v.IsField = true
-	p.blockVars = append(p.blockVars, v)
+	p.pushBlockVar(v)
+	p.locals = append(p.locals, v)
 }
 
 func (p *Parser) deferredCall(d *models.Defer) {
-	m := new(exprModel)
-	m.nodes = make([]exprBuildNode, 1)
 	_, d.Expr.Model = p.evalExpr(d.Expr)
 }
 
 func (p *Parser) concurrentCall(cc *models.ConcurrentCall) {
-	m := new(exprModel)
-	m.nodes = make([]exprBuildNode, 1)
 	_, cc.Expr.Model = p.evalExpr(cc.Expr)
 }
 
@@ -2851,7 +2583,7 @@ func (p *Parser) singleAssign(assign *models.Assign, exprs []value) {
 	leftExpr, model := p.evalExpr(*left)
 	left.Model = model
 	if leftExpr.isField {
-		right.Model = exprNode{exprMustHeap(right.Model.String())}
+		right.Model = &ir.MustHeap{Type: val.data.Type, X: right.Model}
 	}
 	if !p.assignment(leftExpr, assign.Setter) {
 		return
@@ -2911,9 +2643,9 @@ func (p *Parser) multiAssign(assign *models.Assign, right []value) {
 			}
 			leftExpr, model := p.evalExpr(left.Expr)
 			left.Expr.Model = model
-			if leftExpr.isField {
-				right := &assign.Right[i]
-				right.Model = exprNode{exprMustHeap(right.Model.String())}
+			if leftExpr.isField && i < len(assign.Right) {
+				expr := &assign.Right[i]
+				expr.Model = &ir.MustHeap{Type: right.data.Type, X: expr.Model}
 			}
 			if !p.assignment(leftExpr, assign.Setter) {
 				return
@@ -3006,7 +2738,7 @@ func (p *Parser) foreachProfile(iter *models.Iter) {
 		fc.check()
 	}
 	iter.Profile = profile
-	blockVars := p.blockVars
+	p.scope = newScope(p.scope, scopeBlock)
 	if profile.KeyA.New {
 		if jnapi.IsIgnoreId(profile.KeyA.Id) {
 			p.pusherrtok(profile.KeyA.Token, "ignore_id")
@@ -3019,16 +2751,16 @@ func (p *Parser) foreachProfile(iter *models.Iter) {
 		}
 		p.varStatement(&profile.KeyB, true)
 	}
-	p.checkNewBlockCustom(iter.Block, blockVars)
+	p.checkNewBlockCustom(iter.Block, p.scope)
 }
 
 func (p *Parser) forProfile(iter *models.Iter) {
 	profile := iter.Profile.(models.IterFor)
-	blockVars := p.blockVars
+	p.scope = newScope(p.scope, scopeBlock)
 	if profile.Once.Data != nil {
 		_ = p.statement(&profile.Once, false)
 	}
-	if len(profile.Condition.Processes) > 0 {
+	if profile.Condition.Tree != nil {
 		val, model := p.evalExpr(profile.Condition)
 		profile.Condition.Model = model
 		assignChecker{
@@ -3043,7 +2775,7 @@ func (p *Parser) forProfile(iter *models.Iter) {
 	}
 	iter.Profile = profile
 	p.checkNewBlock(iter.Block)
-	p.blockVars = blockVars
+	p.scope = p.scope.parent
 }
 
 func (p *Parser) iter(iter *models.Iter) {
@@ -3204,9 +2936,9 @@ func (p *Parser) typeSourceIsStruct(s *jnstruct, t DataType) (dt DataType, _ boo
 		}
 		*s.constructor.Combines = append(*s.constructor.Combines, generics)
 		owner := s.Ast.Owner.(*Parser)
-		blockTypes := owner.blockTypes
-		owner.blockTypes = nil
-		defer func() { owner.blockTypes = blockTypes }()
+		outer := owner.scope
+		owner.scope = newScope(nil, scopeBlock)
+		defer func() { owner.scope = outer }()
 		owner.pushGenerics(s.Ast.Generics, generics)
 		for i, f := range s.Ast.Fields {
 			owner.parseField(s, &f, i)
@@ -3214,12 +2946,10 @@ func (p *Parser) typeSourceIsStruct(s *jnstruct, t DataType) (dt DataType, _ boo
 		if len(s.Defs.Funcs) > 0 {
 			for _, f := range s.Defs.Funcs {
 				if len(f.Ast.Generics) == 0 {
-					blockVars := owner.blockVars
-					blockTypes := owner.blockTypes
+					generics := owner.scope
 					owner.reloadFuncTypes(f.Ast)
 					_ = p.parsePureFunc(f.Ast)
-					owner.blockVars = blockVars
-					owner.blockTypes = blockTypes
+					owner.scope = generics
 				}
 			}
 		}
@@ -3442,12 +3172,12 @@ func (p *Parser) checkType(real, check DataType, ignoreAny bool, errTok Tok) {
 	}
 }
 
-func (p *Parser) evalExpr(expr Expr) (value, iExpr) {
+func (p *Parser) evalExpr(expr Expr) (value, ir.Expr) {
 	p.eval.hasError = false
 	return p.eval.expr(expr)
 }
 
-func (p *Parser) evalToks(toks Toks) (value, iExpr) {
+func (p *Parser) evalToks(toks Toks) (value, ir.Expr) {
 	p.eval.hasError = false
 	return p.eval.toks(toks)
 }
@@ -0,0 +1,57 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+// parseSource parses and checks src as main source file.
+func parseSource(t *testing.T, src string) *Parser {
+	t.Helper()
+	return parseFS(t, fstest.MapFS{"main.jn": {Data: []byte(src)}}, "std")
+}
+
+// parseFS parses and checks main.jn of fsys with standard library of stdlib.
+func parseFS(t *testing.T, fsys fstest.MapFS, stdlib string) *Parser {
+	t.Helper()
+	f, err := jnio.ReadJn(fsys, "main.jn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := New(f)
+	p.Env = NewEnv(fsys, stdlib, nil)
+	p.Parsef(true, false)
+	return p
+}
+
+// logKeys returns keys of logs.
+func logKeys(logs []jnlog.CompilerLog) []string {
+	keys := make([]string, len(logs))
+	for i, log := range logs {
+		keys[i] = log.Key
+	}
+	return keys
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jntype"
+)
+
+// Symbol kinds.
+const (
+	SymbolFunc uint8 = iota
+	SymbolMethod
+	SymbolVar
+	SymbolConst
+	SymbolField
+	SymbolStruct
+	SymbolTrait
+	SymbolEnum
+	SymbolEnumItem
+	SymbolType
+	SymbolNamespace
+)
+
+// Symbol is definition information for tooling.
+type Symbol struct {
+	Id       string
+	Kind     uint8
+	Detail   string
+	Doc      string
+	Tok      Tok
+	Children []*Symbol
+}
+
+func funcSymbol(f *function, kind uint8) *Symbol {
+	return &Symbol{
+		Id:     f.Ast.Id,
+		Kind:   kind,
+		Detail: f.Ast.DefString(),
+		Doc:    f.Desc,
+		Tok:    f.Ast.Tok,
+	}
+}
+
+func varSymbol(v *Var, kind uint8) *Symbol {
+	if v.Const {
+		kind = SymbolConst
+	}
+	return &Symbol{
+		Id:     v.Id,
+		Kind:   kind,
+		Detail: v.Type.Kind,
+		Doc:    v.Desc,
+		Tok:    v.Token,
+	}
+}
+
+func structSymbol(s *jnstruct) *Symbol {
+	sym := &Symbol{
+		Id:     s.Ast.Id,
+		Kind:   SymbolStruct,
+		Detail: tokens.STRUCT + " " + s.Ast.Id,
+		Doc:    s.Desc,
+		Tok:    s.Ast.Tok,
+	}
+	sym.Children = membersOf(s.Defs)
+	return sym
+}
+
+func traitSymbol(t *trait) *Symbol {
+	sym := &Symbol{
+		Id:     t.Ast.Id,
+		Kind:   SymbolTrait,
+		Detail: tokens.TRAIT + " " + t.Ast.Id,
+		Doc:    t.Desc,
+		Tok:    t.Ast.Tok,
+	}
+	sym.Children = membersOf(t.Defs)
+	return sym
+}
+
+func enumSymbol(e *Enum) *Symbol {
+	sym := &Symbol{
+		Id:     e.Id,
+		Kind:   SymbolEnum,
+		Detail: tokens.ENUM + " " + e.Id + ": " + e.Type.Kind,
+		Doc:    e.Desc,
+		Tok:    e.Tok,
+	}
+	for _, item := range e.Items {
+		sym.Children = append(sym.Children, &Symbol{
+			Id:     item.Id,
+			Kind:   SymbolEnumItem,
+			Detail: e.Id + "." + item.Id,
+			Tok:    item.Tok,
+		})
+	}
+	return sym
+}
+
+func typeSymbol(t *Type) *Symbol {
+	return &Symbol{
+		Id:     t.Id,
+		Kind:   SymbolType,
+		Detail: tokens.TYPE + " " + t.Id + ": " + t.Type.Kind,
+		Doc:    t.Desc,
+		Tok:    t.Tok,
+	}
+}
+
+func membersOf(dm *Defmap) []*Symbol {
+	var syms []*Symbol
+	for _, v := range dm.Globals {
+		syms = append(syms, varSymbol(v, SymbolField))
+	}
+	for _, f := range dm.Funcs {
+		syms = append(syms, funcSymbol(f, SymbolMethod))
+	}
+	return syms
+}
+
+func inFile(tok Tok, f *File) bool {
+	return f == nil || tok.File == f
+}
+
+// Symbols returns symbols of defines.
+// returns only defines of file if f is not nil.
+func (dm *Defmap) Symbols(f *File) []*Symbol {
+	var syms []*Symbol
+	for _, ns := range dm.Namespaces {
+		if inFile(ns.Tok, f) {
+			syms = append(syms, &Symbol{Id: ns.Id, Kind: SymbolNamespace, Tok: ns.Tok})
+		}
+	}
+	for _, t := range dm.Types {
+		if inFile(t.Tok, f) {
+			syms = append(syms, typeSymbol(t))
+		}
+	}
+	for _, e := range dm.Enums {
+		if inFile(e.Tok, f) {
+			syms = append(syms, enumSymbol(e))
+		}
+	}
+	for _, s := range dm.Structs {
+		if inFile(s.Ast.Tok, f) {
+			syms = append(syms, structSymbol(s))
+		}
+	}
+	for _, t := range dm.Traits {
+		if inFile(t.Ast.Tok, f) {
+			syms = append(syms, traitSymbol(t))
+		}
+	}
+	for _, g := range dm.Globals {
+		if inFile(g.Token, f) {
+			syms = append(syms, varSymbol(g, SymbolVar))
+		}
+	}
+	for _, fn := range dm.Funcs {
+		if inFile(fn.Ast.Tok, f) {
+			syms = append(syms, funcSymbol(fn, SymbolFunc))
+		}
+	}
+	return syms
+}
+
+// Symbols returns symbols of file of parser.
+func (p *Parser) Symbols() []*Symbol {
+	return p.Defs.Symbols(p.File)
+}
+
+func before(a, b Tok) bool {
+	return a.Row < b.Row || (a.Row == b.Row && a.Column < b.Column)
+}
+
+// funcAt returns function that contains tok.
+func (p *Parser) funcAt(tok Tok) *Func {
+	var funcs []*function
+	funcs = append(funcs, p.Defs.Funcs...)
+	for _, s := range p.Defs.Structs {
+		funcs = append(funcs, s.Defs.Funcs...)
+	}
+	var at *Func
+	for _, f := range funcs {
+		if f.Ast.Tok.File != tok.File || !before(f.Ast.Tok, tok) {
+			continue
+		}
+		if at == nil || before(at.Tok, f.Ast.Tok) {
+			at = f.Ast
+		}
+	}
+	return at
+}
+
+// localsAt returns local variables that visible at tok.
+// latest declarations are comes first.
+func (p *Parser) localsAt(tok Tok) []*Var {
+	f := p.funcAt(tok)
+	if f == nil {
+		return nil
+	}
+	var vars []*Var
+	for i := len(p.locals) - 1; i >= 0; i-- {
+		v := p.locals[i]
+		if v.Token.File != tok.File || !before(v.Token, tok) || before(v.Token, f.Tok) {
+			continue
+		}
+		vars = append(vars, v)
+	}
+	if f.Receiver != nil {
+		if s, ok := f.Receiver.Tag.(*jnstruct); ok {
+			vars = append(vars, s.selfVar(*f.Receiver))
+		}
+	}
+	return vars
+}
+
+func defSymbol(dm *Defmap, id string, f *File) *Symbol {
+	i, m, t := dm.findById(id, f)
+	if i == -1 {
+		return nil
+	}
+	switch t {
+	case 'g':
+		return varSymbol(m.Globals[i], SymbolVar)
+	case 'f':
+		return funcSymbol(m.Funcs[i], SymbolFunc)
+	case 'e':
+		return enumSymbol(m.Enums[i])
+	case 's':
+		return structSymbol(m.Structs[i])
+	case 't':
+		return typeSymbol(m.Types[i])
+	case 'i':
+		return traitSymbol(m.Traits[i])
+	}
+	return nil
+}
+
+// Lookup returns symbol of identifier that visible at tok.
+func (p *Parser) Lookup(id string, tok Tok) *Symbol {
+	for _, v := range p.localsAt(tok) {
+		if v.Id == id {
+			return varSymbol(v, SymbolVar)
+		}
+	}
+	if sym := defSymbol(p.Defs, id, tok.File); sym != nil {
+		return sym
+	}
+	return defSymbol(p.Env.builtin, id, nil)
+}
+
+// Scope returns symbols that visible at tok.
+func (p *Parser) Scope(tok Tok) []*Symbol {
+	var syms []*Symbol
+	declared := map[string]bool{}
+	for _, v := range p.localsAt(tok) {
+		if !declared[v.Id] {
+			declared[v.Id] = true
+			syms = append(syms, varSymbol(v, SymbolVar))
+		}
+	}
+	for dm := p.Defs; dm != nil; dm = dm.side {
+		syms = append(syms, dm.Symbols(nil)...)
+	}
+	return append(syms, p.Env.builtin.Symbols(nil)...)
+}
+
+func (p *Parser) membersOfType(t DataType) []*Symbol {
+	switch tag := t.Tag.(type) {
+	case *jnstruct:
+		return membersOf(tag.Defs)
+	case *trait:
+		return membersOf(tag.Defs)
+	case *Enum:
+		return enumSymbol(tag).Children
+	}
+	switch {
+	case typeIsSlice(t):
+		return membersOf(p.Env.sliceDefs)
+	case typeIsArray(t):
+		return membersOf(p.Env.arrayDefs)
+	case typeIsMap(t):
+		return membersOf(p.Env.mapDefs)
+	case t.Id == jntype.Str:
+		return membersOf(p.Env.strDefs)
+	}
+	return nil
+}
+
+// Members returns members of identifier that visible at tok.
+// members are fields and methods of variables, and items of enums.
+func (p *Parser) Members(id string, tok Tok) []*Symbol {
+	for _, v := range p.localsAt(tok) {
+		if v.Id == id {
+			return p.membersOfType(v.Type)
+		}
+	}
+	i, m, t := p.Defs.findById(id, tok.File)
+	if i == -1 {
+		return nil
+	}
+	switch t {
+	case 'g':
+		return p.membersOfType(m.Globals[i].Type)
+	case 'e':
+		return enumSymbol(m.Enums[i]).Children
+	}
+	return nil
+}
+
+// NamespaceSymbols returns symbols of namespace path.
+func (p *Parser) NamespaceSymbols(path []string) []*Symbol {
+	dm := p.Defs
+	for _, id := range path {
+		ns := dm.nsById(id)
+		if ns == nil {
+			return nil
+		}
+		dm = ns.defs
+	}
+	return dm.Symbols(nil)
+}
@@ -22,15 +22,33 @@ package parser
 
 import (
 	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jnapi"
 )
 
 type retChecker struct {
-	p        *Parser
-	retAST   *models.Ret
-	f        *Func
-	expModel retExpr
+	p      *Parser
+	retAST *models.Ret
+	f      *Func
+	models []ir.Expr
+	values []value
+}
+
+// model returns model of returned values.
+// Pointers are must be escape to heap.
+func (rc *retChecker) model() ir.Expr {
+	exprs := make([]ir.Expr, len(rc.models))
+	for i, model := range rc.models {
+		if i < len(rc.values) && typeIsPtr(rc.values[i].data.Type) {
+			model = &ir.MustHeap{Type: rc.values[i].data.Type, X: model}
+		}
+		exprs[i] = model
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return &ir.Tuple{Type: rc.f.RetType.Type, Values: exprs}
 }
 
 func (rc *retChecker) pushval(last, current int, errTok Tok) {
@@ -40,8 +58,8 @@ func (rc *retChecker) pushval(last, current int, errTok Tok) {
 	}
 	toks := rc.retAST.Expr.Toks[last:current]
 	val, model := rc.p.evalToks(toks)
-	rc.expModel.models = append(rc.expModel.models, model)
-	rc.expModel.values = append(rc.expModel.values, val)
+	rc.models = append(rc.models, model)
+	rc.values = append(rc.values, val)
 }
 
 func (rc *retChecker) checkepxrs() {
@@ -72,27 +90,25 @@ func (rc *retChecker) checkepxrs() {
 	}
 	if !typeIsVoid(rc.f.RetType.Type) {
 		rc.checkExprTypes()
-		rc.retAST.Expr.Model = rc.expModel
+		rc.retAST.Expr.Model = rc.model()
 	}
 }
 
 func (rc *retChecker) single() {
-	rc.expModel.models = append(rc.expModel.models, rc.expModel.models[0])
-	if len(rc.expModel.values) > 1 {
+	if len(rc.values) > 1 {
 		rc.p.pusherrtok(rc.retAST.Tok, "overflow_return")
 	}
 	assignChecker{
 		p:      rc.p,
 		t:      rc.f.RetType.Type,
-		v:      rc.expModel.values[0],
+		v:      rc.values[0],
 		errtok: rc.retAST.Tok,
 	}.checkAssignType()
 }
 
 func (rc *retChecker) multi() {
-	rc.retAST.Expr.Model = rc.expModel
 	types := rc.f.RetType.Type.Tag.([]DataType)
-	valLength := len(rc.expModel.values)
+	valLength := len(rc.values)
 	if valLength == 1 {
 		rc.checkMultiRetAsMutliRet()
 		return
@@ -106,7 +122,7 @@ func (rc *retChecker) multi() {
 		assignChecker{
 			p:      rc.p,
 			t:      t,
-			v:      rc.expModel.values[i],
+			v:      rc.values[i],
 			errtok: rc.retAST.Tok,
 		}.checkAssignType()
 	}
@@ -121,7 +137,7 @@ func (rc *retChecker) checkExprTypes() {
 }
 
 func (rc *retChecker) checkMultiRetAsMutliRet() {
-	val := rc.expModel.values[0]
+	val := rc.values[0]
 	if !val.data.Type.MultiTyped {
 		rc.p.pusherrtok(rc.retAST.Tok, "missing_multi_return")
 		return
@@ -135,7 +151,6 @@ func (rc *retChecker) checkMultiRetAsMutliRet() {
 		rc.p.pusherrtok(rc.retAST.Tok, "overflow_return")
 		return
 	}
-	rc.expModel.models = append(rc.expModel.models, rc.expModel.models[0])
 	for i, rt := range retTypes {
 		vt := valTypes[i]
 		val := value{data: models.Data{Type: vt}}
@@ -152,34 +167,29 @@ func (rc *retChecker) retsVars() {
 	if !rc.f.RetType.Type.MultiTyped {
 		for _, v := range rc.f.RetType.Identifiers {
 			if !jnapi.IsIgnoreId(v.Kind) {
-				model := new(exprModel)
-				model.index = 0
-				model.nodes = make([]exprBuildNode, 1)
-				val, _ := rc.p.eval.single(v, model)
-				rc.expModel.models = append(rc.expModel.models, model)
-				rc.expModel.values = append(rc.expModel.values, val)
+				val, _ := rc.p.eval.single(v)
+				rc.models = append(rc.models, val.model)
+				rc.values = append(rc.values, val)
 				break
 			}
 		}
+		if len(rc.models) > 0 {
+			rc.retAST.Expr.Model = rc.model()
+		}
 		return
 	}
 	types := rc.f.RetType.Type.Tag.([]DataType)
 	for i, v := range rc.f.RetType.Identifiers {
 		if jnapi.IsIgnoreId(v.Kind) {
-			node := exprNode{}
-			node.value = types[i].String()
-			node.value += jnapi.DefaultExpr
-			rc.expModel.models = append(rc.expModel.models, node)
+			rc.models = append(rc.models, &ir.Default{Type: types[i]})
+			rc.values = append(rc.values, value{})
 			continue
 		}
-		model := new(exprModel)
-		model.index = 0
-		model.nodes = make([]exprBuildNode, 1)
-		val, _ := rc.p.eval.single(v, model)
-		rc.expModel.models = append(rc.expModel.models, model)
-		rc.expModel.values = append(rc.expModel.values, val)
-	}
-	rc.retAST.Expr.Model = rc.expModel
+		val, _ := rc.p.eval.single(v)
+		rc.models = append(rc.models, val.model)
+		rc.values = append(rc.values, val)
+	}
+	rc.retAST.Expr.Model = rc.model()
 }
 
 func (rc *retChecker) check() {
@@ -0,0 +1,251 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+// scopeKind is kind of scope.
+type scopeKind uint8
+
+const (
+	// scopeBlock is scope of block.
+	scopeBlock scopeKind = iota
+	// scopeFunc is scope of function parameters.
+	// variables of enclosing scopes are not visible from function.
+	scopeFunc
+)
+
+// scope is symbol table of a function or block scope.
+// symbols are hashed by identifier, and lookups walks parent chain.
+// variable lookups are stops at function scope, type lookups are not.
+//
+// each kind of define is searched in order of block scopes, builtin defines,
+// package defines and defines of used packages. defines of used packages
+// are can shadowed.
+type scope struct {
+	parent   *scope
+	kind     scopeKind
+	vars     map[string]*Var
+	types    map[string]*Type
+	varList  []*Var
+	typeList []*Type
+}
+
+func newScope(parent *scope, kind scopeKind) *scope {
+	return &scope{parent: parent, kind: kind}
+}
+
+func (s *scope) pushVar(v *Var) {
+	s.varList = append(s.varList, v)
+	if s.vars == nil {
+		s.vars = map[string]*Var{}
+	}
+	// first declaration is remains visible for redeclarations
+	if _, ok := s.vars[v.Id]; !ok {
+		s.vars[v.Id] = v
+	}
+}
+
+func (s *scope) pushType(t *Type) {
+	s.typeList = append(s.typeList, t)
+	if s.types == nil {
+		s.types = map[string]*Type{}
+	}
+	if _, ok := s.types[t.Id]; !ok {
+		s.types[t.Id] = t
+	}
+}
+
+func (s *scope) varById(id string) *Var {
+	for ; s != nil; s = s.parent {
+		if v := s.vars[id]; v != nil {
+			return v
+		}
+		if s.kind == scopeFunc {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *scope) typeById(id string) *Type {
+	for ; s != nil; s = s.parent {
+		if t := s.types[id]; t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// visibleVars returns variables of function that visible from scope.
+// variables are ordered by declaration.
+func (s *scope) visibleVars() []*Var {
+	var scopes []*scope
+	for ; s != nil; s = s.parent {
+		scopes = append(scopes, s)
+		if s.kind == scopeFunc {
+			break
+		}
+	}
+	var vars []*Var
+	for i := len(scopes) - 1; i >= 0; i-- {
+		vars = append(vars, scopes[i].varList...)
+	}
+	return vars
+}
+
+// visibleTypes returns types that visible from scope.
+// types are ordered by declaration.
+func (s *scope) visibleTypes() []*Type {
+	var scopes []*scope
+	for ; s != nil; s = s.parent {
+		scopes = append(scopes, s)
+	}
+	var types []*Type
+	for i := len(scopes) - 1; i >= 0; i-- {
+		types = append(types, scopes[i].typeList...)
+	}
+	return types
+}
+
+func (p *Parser) pushBlockVar(v *Var) {
+	if p.scope == nil {
+		p.scope = newScope(nil, scopeBlock)
+	}
+	p.scope.pushVar(v)
+}
+
+func (p *Parser) pushBlockType(t *Type) {
+	if p.scope == nil {
+		p.scope = newScope(nil, scopeBlock)
+	}
+	p.scope.pushType(t)
+}
+
+func (p *Parser) FuncById(id string) (*function, *Defmap, bool) {
+	f, _, _ := p.Env.builtin.funcById(id, nil)
+	if f != nil {
+		return f, nil, false
+	}
+	return p.Defs.funcById(id, p.File)
+}
+
+func (p *Parser) globalById(id string) (*Var, *Defmap, bool) {
+	g, m, _ := p.Defs.globalById(id, p.File)
+	return g, m, true
+}
+
+func (p *Parser) nsById(id string) *namespace {
+	return p.Defs.nsById(id)
+}
+
+func (p *Parser) typeById(id string) (*Type, *Defmap, bool) {
+	t := p.blockTypeById(id)
+	if t != nil {
+		return t, nil, false
+	}
+	t, _, _ = p.Env.builtin.typeById(id, nil)
+	if t != nil {
+		return t, nil, false
+	}
+	return p.Defs.typeById(id, p.File)
+}
+
+func (p *Parser) enumById(id string) (*Enum, *Defmap, bool) {
+	s, _, _ := p.Env.builtin.enumById(id, nil)
+	if s != nil {
+		return s, nil, false
+	}
+	return p.Defs.enumById(id, p.File)
+}
+
+func (p *Parser) structById(id string) (*jnstruct, *Defmap, bool) {
+	s, _, _ := p.Env.builtin.structById(id, nil)
+	if s != nil {
+		return s, nil, false
+	}
+	return p.Defs.structById(id, p.File)
+}
+
+func (p *Parser) traitById(id string) (*trait, *Defmap, bool) {
+	t, _, _ := p.Env.builtin.traitById(id, nil)
+	if t != nil {
+		return t, nil, false
+	}
+	return p.Defs.traitById(id, p.File)
+}
+
+func (p *Parser) blockTypeById(id string) *Type {
+	return p.scope.typeById(id)
+}
+
+func (p *Parser) blockVarById(id string) *Var {
+	return p.scope.varById(id)
+}
+
+// defById returns define by identifier.
+// canshadow reports define is can shadowed by block scope.
+func (p *Parser) defById(id string) (def any, tok Tok, canshadow bool) {
+	var t *Type
+	t, _, canshadow = p.typeById(id)
+	if t != nil {
+		return t, t.Tok, canshadow
+	}
+	var e *Enum
+	e, _, canshadow = p.enumById(id)
+	if e != nil {
+		return e, e.Tok, canshadow
+	}
+	var s *jnstruct
+	s, _, canshadow = p.structById(id)
+	if s != nil {
+		return s, s.Ast.Tok, canshadow
+	}
+	var trait *trait
+	trait, _, canshadow = p.traitById(id)
+	if trait != nil {
+		return trait, trait.Ast.Tok, canshadow
+	}
+	var f *function
+	f, _, canshadow = p.FuncById(id)
+	if f != nil {
+		return f, f.Ast.Tok, canshadow
+	}
+	bv := p.blockVarById(id)
+	if bv != nil {
+		return bv, bv.Token, false
+	}
+	g, _, _ := p.globalById(id)
+	if g != nil {
+		return g, g.Token, true
+	}
+	return
+}
+
+func (p *Parser) blockDefById(id string) (def any, tok Tok) {
+	bv := p.blockVarById(id)
+	if bv != nil {
+		return bv, bv.Token
+	}
+	t := p.blockTypeById(id)
+	if t != nil {
+		return t, t.Tok
+	}
+	return
+}
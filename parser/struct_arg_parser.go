@@ -22,7 +22,7 @@ package parser
 
 import (
 	"github.com/DeRuneLabs/jane/ast/models"
-	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/ir"
 )
 
 func (p *Parser) getFieldMap(f *Func) *paramMap {
@@ -59,8 +59,7 @@ func (sap *structArgParser) buildArgs() {
 			arg := Arg{Expr: pair.param.Default}
 			sap.args.Src[i] = arg
 		case pair.param.Variadic:
-			model := sliceExpr{pair.param.Type, nil}
-			model.dataType.Kind = jn.Prefix_Slice + model.dataType.Kind
+			model := &ir.Composite{Type: variadicParamType(pair.param)}
 			arg := Arg{Expr: Expr{Model: model}}
 			sap.args.Src[i] = arg
 		}
@@ -75,7 +74,7 @@ func (sap *structArgParser) pushArg() {
 	}
 	pair, ok := (*sap.fmap)[sap.arg.TargetId]
 	if !ok {
-		sap.p.pusherrtok(sap.arg.Tok, "id_noexist", sap.arg.TargetId)
+		sap.p.pusherrtokSuggest(sap.arg.Tok, sap.arg.TargetId, sap.fmap.ids(), "id_noexist", sap.arg.TargetId)
 		return
 	} else if pair.arg != nil {
 		sap.p.pusherrtok(sap.arg.Tok, "already_has_expr", sap.arg.TargetId)
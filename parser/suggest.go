@@ -0,0 +1,207 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+func levenshtein(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// splitPath returns prefix and last component of double colon separated path.
+// prefix is empty if path has not any separator.
+func splitPath(path string) (prefix, last string) {
+	i := strings.LastIndex(path, tokens.DOUBLE_COLON)
+	if i == -1 {
+		return "", path
+	}
+	return path[:i], path[i+len(tokens.DOUBLE_COLON):]
+}
+
+// suggest returns closest candidate to id by edit distance.
+// returns empty string if not exist any close enough candidate.
+// paths are compared by last components of candidates that have same prefix.
+func suggest(id string, candidates []string) string {
+	prefix, last := splitPath(id)
+	n := utf8.RuneCountInString(last)
+	limit := max(1, n/2)
+	best := ""
+	bestDist := limit + 1
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	for _, candidate := range sorted {
+		if candidate == id || candidate == "" {
+			continue
+		}
+		candidatePrefix, candidateLast := splitPath(candidate)
+		if candidatePrefix != prefix {
+			continue
+		}
+		dist := levenshtein(last, candidateLast)
+		if dist < bestDist && dist < n {
+			best = candidate
+			bestDist = dist
+		}
+	}
+	return best
+}
+
+func withSuggestion(msg, id string, candidates []string) string {
+	s := suggest(id, candidates)
+	if s == "" {
+		return msg
+	}
+	return msg + " (" + jn.GetNote("did_you_mean", s) + ")"
+}
+
+func (dm *Defmap) ids() []string {
+	var ids []string
+	for ; dm != nil; dm = dm.side {
+		for _, t := range dm.Types {
+			ids = append(ids, t.Id)
+		}
+		for _, e := range dm.Enums {
+			ids = append(ids, e.Id)
+		}
+		for _, s := range dm.Structs {
+			ids = append(ids, s.Ast.Id)
+		}
+		for _, t := range dm.Traits {
+			ids = append(ids, t.Ast.Id)
+		}
+		for _, f := range dm.Funcs {
+			ids = append(ids, f.Ast.Id)
+		}
+		for _, g := range dm.Globals {
+			ids = append(ids, g.Id)
+		}
+	}
+	return ids
+}
+
+func (dm *Defmap) structIds() []string {
+	var ids []string
+	for ; dm != nil; dm = dm.side {
+		for _, s := range dm.Structs {
+			ids = append(ids, s.Ast.Id)
+		}
+	}
+	return ids
+}
+
+func (dm *Defmap) traitIds() []string {
+	var ids []string
+	for ; dm != nil; dm = dm.side {
+		for _, t := range dm.Traits {
+			ids = append(ids, t.Ast.Id)
+		}
+	}
+	return ids
+}
+
+func (dm *Defmap) nsIds() []string {
+	ids := make([]string, len(dm.Namespaces))
+	for i, ns := range dm.Namespaces {
+		ids[i] = ns.Id
+	}
+	return ids
+}
+
+func (p *Parser) nsIds() []string {
+	return p.Defs.nsIds()
+}
+
+func (p *Parser) linkIds() []string {
+	ids := make([]string, len(p.cppLinks))
+	for i, link := range p.cppLinks {
+		ids[i] = link.Link.Id
+	}
+	return ids
+}
+
+func enumItemIds(e *Enum) []string {
+	ids := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		ids[i] = item.Id
+	}
+	return ids
+}
+
+func (pmap *paramMap) ids() []string {
+	ids := make([]string, 0, len(*pmap))
+	for id := range *pmap {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// idCandidates returns identifiers of current scope.
+func (p *Parser) idCandidates() []string {
+	var ids []string
+	for _, v := range p.scope.visibleVars() {
+		ids = append(ids, v.Id)
+	}
+	for _, t := range p.scope.visibleTypes() {
+		ids = append(ids, t.Id)
+	}
+	ids = append(ids, p.Env.builtin.ids()...)
+	ids = append(ids, p.Defs.ids()...)
+	return ids
+}
+
+func (p *Parser) pusherrtokSuggest(tok Tok, id string, candidates []string, key string, args ...any) {
+	msg := withSuggestion(jn.GetError(key, args...), id, candidates)
+	p.Errors = append(p.Errors, logOfTok(tok, jnlog.Error, key, msg))
+}
+
+func (e *eval) pusherrtokSuggest(tok Tok, id string, candidates []string, key string, args ...any) {
+	if e.hasError {
+		return
+	}
+	e.hasError = true
+	e.p.pusherrtokSuggest(tok, id, candidates, key, args...)
+}
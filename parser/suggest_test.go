@@ -0,0 +1,77 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"count", "count", 0},
+		{"count", "cuont", 2},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"çay", "cay", 1},
+	}
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+		if got := levenshtein(c.b, c.a); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.b, c.a, got, c.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	stdPkgs := []string{"std::errors", "std::io", "std::math", "std::math::bits", "std::strings"}
+	cases := []struct {
+		name       string
+		id         string
+		candidates []string
+		want       string
+	}{
+		{"typo", "cuont", []string{"count", "counter", "amount"}, "count"},
+		{"closest", "prnt", []string{"print", "println", "panic"}, "print"},
+		{"too far", "xyz", []string{"count", "print"}, ""},
+		{"short id", "a", []string{"b", "c"}, ""},
+		{"same id", "count", []string{"count"}, ""},
+		{"no candidates", "count", nil, ""},
+		{"ordered ties", "ab", []string{"ac", "aa"}, "aa"},
+		{"package", "std::mth", stdPkgs, "std::math"},
+		{"sub package", "std::math::bitz", stdPkgs, "std::math::bits"},
+		{"unrelated package", "std::strings", stdPkgs[:4], ""},
+		{"other prefix", "std::bits", stdPkgs, ""},
+		{"path of id", "mth", stdPkgs, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := suggest(c.id, c.candidates); got != c.want {
+				t.Errorf("suggest(%q) = %q, want %q", c.id, got, c.want)
+			}
+		})
+	}
+}
@@ -20,38 +20,27 @@
 
 package parser
 
-type precedencer struct {
-	pairs [][]any
+import (
+	"github.com/DeRuneLabs/jane/package/jn"
+)
+
+func isTestFunc(f *function) bool {
+	return f.Ast.FindAttribute(jn.Attribute_Test) != nil
 }
 
-func (p *precedencer) set(level uint, expr any) {
-	for _, pair := range p.pairs {
-		pairLevel := pair[0].(uint)
-		if pairLevel == level {
-			if pair[1] == nil {
-				pair[1] = expr
-			}
-			return
-		}
-	}
-	for i, pair := range p.pairs {
-		pairLevel := pair[0].(uint)
-		if level > pairLevel {
-			first := p.pairs[:i]
-			appended := append([][]any{{level, expr}}, p.pairs[i:]...)
-			p.pairs = append(first, appended...)
-			return
-		}
+func (p *Parser) useTests() {
+	for _, f := range p.Tests() {
+		f.used = true
 	}
-	p.pairs = append(p.pairs, []any{level, expr})
 }
 
-func (p *precedencer) get() any {
-	for _, pair := range p.pairs {
-		data := pair[1]
-		if data != nil {
-			return data
+// Tests returns test functions of package.
+func (p *Parser) Tests() []*function {
+	var tests []*function
+	for _, f := range p.Defs.Funcs {
+		if isTestFunc(f) {
+			tests = append(tests, f)
 		}
 	}
-	return nil
+	return tests
 }
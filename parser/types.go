@@ -23,7 +23,6 @@ package parser
 import (
 	"strings"
 
-	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jn"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
@@ -37,6 +36,32 @@ func findGeneric(id string, generics []*GenericType) *GenericType {
 	return nil
 }
 
+// typeClone returns deep copy of type.
+// Type sources are resolved in place,
+// so types of expression trees are cloned before resolving.
+func typeClone(t DataType) DataType {
+	t = t.Copy()
+	switch tag := t.Tag.(type) {
+	case []DataType:
+		types := make([]DataType, len(tag))
+		for i, tt := range tag {
+			types[i] = typeClone(tt)
+		}
+		t.Tag = types
+	case *Func:
+		f := new(Func)
+		*f = *tag
+		f.Params = make([]Param, len(tag.Params))
+		for i, param := range tag.Params {
+			param.Type = typeClone(param.Type)
+			f.Params[i] = param
+		}
+		f.RetType.Type = typeClone(tag.RetType.Type)
+		t.Tag = f
+	}
+	return t
+}
+
 func typeIsVoid(t DataType) bool {
 	return t.Id == jntype.Void && !t.MultiTyped
 }
@@ -160,13 +185,6 @@ func typeIsPure(t DataType) bool {
 		!typeIsFunc(t)
 }
 
-func subIdAccessorOfType(t DataType) string {
-	if typeIsPtr(t) {
-		return "->"
-	}
-	return tokens.DOT
-}
-
 func typeIsNilCompatible(t DataType) bool {
 	return t.Id == jntype.Nil ||
 		typeIsFunc(t) ||
@@ -21,19 +21,20 @@
 package parser
 
 import (
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
 
 type unary struct {
-	tok   Tok
-	toks  Toks
-	model *exprModel
-	p     *Parser
+	tok  Tok
+	expr models.ExprNode
+	p    *Parser
 }
 
 func (u *unary) minus() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	if !typeIsPure(v.data.Type) || !jntype.IsNumeric(v.data.Type.Id) {
 		u.p.eval.pusherrtok(u.tok, "invalid_type_unary_operator", tokens.MINUS)
 	}
@@ -48,12 +49,14 @@ func (u *unary) minus() value {
 			v.expr = -t
 		}
 		v.model = numericModel(v)
+	} else {
+		v.model = &ir.Unary{Type: v.data.Type, Op: u.tok.Kind, X: v.model}
 	}
 	return v
 }
 
 func (u *unary) plus() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	if !typeIsPure(v.data.Type) || !jntype.IsNumeric(v.data.Type.Id) {
 		u.p.eval.pusherrtok(u.tok, "invalid_type_unary_operator", tokens.PLUS)
 	}
@@ -67,12 +70,14 @@ func (u *unary) plus() value {
 			v.expr = +t
 		}
 		v.model = numericModel(v)
+	} else {
+		v.model = &ir.Unary{Type: v.data.Type, Op: u.tok.Kind, X: v.model}
 	}
 	return v
 }
 
 func (u *unary) caret() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	if !typeIsPure(v.data.Type) || !jntype.IsInteger(v.data.Type.Id) {
 		u.p.eval.pusherrtok(u.tok, "invalid_type_unary_operator", tokens.CARET)
 	}
@@ -84,25 +89,30 @@ func (u *unary) caret() value {
 			v.expr = ^t
 		}
 		v.model = numericModel(v)
+	} else {
+		v.model = &ir.Unary{Type: v.data.Type, Op: u.tok.Kind, X: v.model}
 	}
 	return v
 }
 
 func (u *unary) logicalNot() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	if !isBoolExpr(v) {
 		u.p.eval.pusherrtok(u.tok, "invalid_type_unary_operator", tokens.EXCLAMATION)
-	} else if v.constExpr {
-		v.expr = !v.expr.(bool)
-		v.model = boolModel(v)
 	}
 	v.data.Type.Id = jntype.Bool
 	v.data.Type.Kind = tokens.BOOL
+	if v.constExpr {
+		v.expr = !v.expr.(bool)
+		v.model = boolModel(v)
+	} else {
+		v.model = &ir.Unary{Type: v.data.Type, Op: u.tok.Kind, X: v.model}
+	}
 	return v
 }
 
 func (u *unary) star() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	v.constExpr = false
 	v.lvalue = true
 	if !typeIsExplicitPtr(v.data.Type) {
@@ -110,29 +120,18 @@ func (u *unary) star() value {
 	} else {
 		v.data.Type.Kind = v.data.Type.Kind[1:]
 	}
+	v.model = &ir.Unary{Type: v.data.Type, Op: u.tok.Kind, X: v.model}
 	return v
 }
 
 func (u *unary) amper() value {
-	v := u.p.eval.process(u.toks, u.model)
+	v := u.p.eval.node(u.expr)
 	v.constExpr = false
 	if !canGetPtr(v) {
 		u.p.eval.pusherrtok(u.tok, "invalid_type_unary_operator", tokens.AMPER)
 	}
 	v.lvalue = true
 	v.data.Type.Kind = tokens.STAR + v.data.Type.Kind
-	nodes := &u.model.nodes[u.model.index].nodes
-	var func_call string
-	if v.heapMust {
-		func_call = "__jnc_ptr_of"
-	} else {
-		func_call = "__jnc_not_heap_ptr_of"
-	}
-	expr := []iExpr{
-		exprNode{func_call},
-		exprNode{tokens.LPARENTHESES},
-	}
-	*nodes = append(expr, *nodes...)
-	*nodes = append(*nodes, exprNode{tokens.RPARENTHESES})
+	v.model = &ir.AddrOf{Type: v.data.Type, X: v.model, Heap: v.heapMust}
 	return v
 }
@@ -20,11 +20,188 @@
 
 package parser
 
+import (
+	"path/filepath"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
 type use struct {
 	Path       string
 	LinkString string
 	defs       *Defmap
+	deps       []*use
 	tok        Tok
 	fullUse    bool
 	cppLink    bool
 }
+
+// usePackage is parsed package of use declarations.
+// it is shared by all use declarations of same path.
+type usePackage struct {
+	done     chan struct{}
+	claimed  bool
+	waits    *usePackage
+	defs     *Defmap
+	deps     []*use
+	errors   []jnlog.CompilerLog
+	warnings []jnlog.CompilerLog
+}
+
+func (env *Env) pkg(path string) *usePackage {
+	if env.packages == nil {
+		env.packages = map[string]*usePackage{}
+	}
+	pkg := env.packages[path]
+	if pkg == nil {
+		pkg = &usePackage{done: make(chan struct{})}
+		env.packages[path] = pkg
+	}
+	return pkg
+}
+
+// prefetch starts parsing of package of path on worker pool.
+func (env *Env) prefetch(path string) {
+	env.mutex.Lock()
+	pkg := env.pkg(path)
+	env.mutex.Unlock()
+	go func() {
+		env.workers <- struct{}{}
+		defer func() { <-env.workers }()
+		env.mutex.Lock()
+		claimed := pkg.claimed
+		pkg.claimed = true
+		env.mutex.Unlock()
+		if !claimed {
+			env.parsePackage(path, pkg)
+		}
+	}()
+}
+
+// load returns package of path for package from.
+// package is parsed by caller if not claimed yet,
+// otherwise waits until parsing is done.
+// reports cycle if package is waits for package from.
+func (env *Env) load(from *usePackage, path string) (_ *usePackage, cycle bool) {
+	env.mutex.Lock()
+	pkg := env.pkg(path)
+	for w := pkg; w != nil; w = w.waits {
+		if w == from {
+			env.mutex.Unlock()
+			return nil, true
+		}
+	}
+	if from != nil {
+		from.waits = pkg
+	}
+	claimed := pkg.claimed
+	pkg.claimed = true
+	env.mutex.Unlock()
+	if !claimed {
+		env.parsePackage(path, pkg)
+	}
+	<-pkg.done
+	if from != nil {
+		env.mutex.Lock()
+		from.waits = nil
+		env.mutex.Unlock()
+	}
+	return pkg, false
+}
+
+func (env *Env) parsePackage(path string, pkg *usePackage) {
+	defer close(pkg.done)
+	names, err := env.sourceFiles(path)
+	if err != nil {
+		pkg.errors = append(pkg.errors, jnlog.CompilerLog{
+			Type:    jnlog.FlatError,
+			Message: err.Error(),
+		})
+		return
+	}
+	for _, name := range names {
+		f, err := env.open(filepath.Join(path, name))
+		if err != nil {
+			pkg.errors = append(pkg.errors, jnlog.CompilerLog{
+				Type:    jnlog.FlatError,
+				Message: err.Error(),
+			})
+			continue
+		}
+		psub := New(f)
+		psub.Env = env
+		psub.pkg = pkg
+		psub.Parsef(false, false)
+		pkg.defs = new(Defmap)
+		psub.pushDefs(pkg.defs, psub.Defs)
+		// package is shared by parsers of goroutines
+		pkg.defs.buildIndex()
+		pkg.deps = psub.deps
+		pkg.errors = append(pkg.errors, psub.Errors...)
+		pkg.warnings = append(pkg.warnings, psub.Warnings...)
+		return
+	}
+}
+
+func logKey(log jnlog.CompilerLog) [5]any {
+	return [5]any{log.Path, log.Row, log.Column, log.Key, log.Message}
+}
+
+// pushPackageLogs pushes logs of package.
+// logs of packages that used by multiple packages are pushed once.
+func (p *Parser) pushPackageLogs(pkg *usePackage) {
+	pushed := map[[5]any]bool{}
+	for _, log := range p.Errors {
+		pushed[logKey(log)] = true
+	}
+	for _, log := range p.Warnings {
+		pushed[logKey(log)] = true
+	}
+	for _, log := range pkg.errors {
+		if !pushed[logKey(log)] {
+			pushed[logKey(log)] = true
+			p.Errors = append(p.Errors, log)
+		}
+	}
+	for _, log := range pkg.warnings {
+		if !pushed[logKey(log)] {
+			pushed[logKey(log)] = true
+			p.Warnings = append(p.Warnings, log)
+		}
+	}
+}
+
+// used returns used packages in dependency order.
+func (p *Parser) used() []*use {
+	var uses []*use
+	visited := map[string]bool{}
+	var visit func(deps []*use)
+	visit = func(deps []*use) {
+		for _, use := range deps {
+			if visited[use.Path] {
+				continue
+			}
+			visited[use.Path] = true
+			visit(use.deps)
+			uses = append(uses, use)
+		}
+	}
+	visit(p.deps)
+	return uses
+}
+
+// prefetchUses starts parsing of packages of use declarations of tree.
+func (p *Parser) prefetchUses(tree []models.Object) {
+	for _, obj := range tree {
+		switch t := obj.Data.(type) {
+		case models.Use:
+			if !t.Cpp {
+				p.Env.prefetch(t.Path)
+			}
+		case models.Comment:
+		default:
+			return
+		}
+	}
+}
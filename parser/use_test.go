@@ -0,0 +1,162 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DeRuneLabs/jane/package/jnlog"
+)
+
+// usePaths returns paths of used packages.
+func usePaths(uses []*use) []string {
+	paths := make([]string, len(uses))
+	for i, use := range uses {
+		paths[i] = use.Path
+	}
+	return paths
+}
+
+// TestUseShared uses same package from multiple packages,
+// package is parsed once and used in dependency order.
+func TestUseShared(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":      {Data: []byte("use std::a::*\nuse std::b::*\n\nmain() {\n\ta()\n\tb()\n}\n")},
+		"std/a/a.jn":   {Data: []byte("use std::c::*\n\npub a() { c() }\n")},
+		"std/b/b.jn":   {Data: []byte("use std::c::*\n\npub b() { c() }\n")},
+		"std/c/c.jn":   {Data: []byte("pub c() {}\n")},
+		"std/c/c_x.jn": {Data: []byte("pub x() {}\n")},
+	}
+	p := parseFS(t, fsys, "std")
+	if len(p.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors)
+	}
+	want := []string{"std/c", "std/a", "std/b"}
+	if paths := usePaths(p.used()); !reflect.DeepEqual(paths, want) {
+		t.Errorf("used packages: got %v, want %v", paths, want)
+	}
+	if len(p.Env.packages) != 3 {
+		t.Errorf("parsed packages: got %d, want 3", len(p.Env.packages))
+	}
+	a := p.Env.packages["std/a"]
+	b := p.Env.packages["std/b"]
+	if a.deps[0].defs != b.deps[0].defs {
+		t.Error("used package is not shared")
+	}
+}
+
+// TestUseSharedGeneric instantiates generic function of same package
+// from packages that checked concurrently.
+func TestUseSharedGeneric(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":    {Data: []byte("use std::a::*\nuse std::b::*\n\nmain() {\n\ta()\n\tb()\n}\n")},
+		"std/a/a.jn": {Data: []byte("use std::c::*\n\npub a() int { ret id(1) }\n")},
+		"std/b/b.jn": {Data: []byte("use std::c::*\n\npub b() str { ret id(\"b\") }\n")},
+		"std/c/c.jn": {Data: []byte("type[T]\npub id(x T) T { ret x }\n")},
+	}
+	for i := 0; i < 10; i++ {
+		p := parseFS(t, fsys, "std")
+		if len(p.Errors) > 0 {
+			t.Fatalf("unexpected errors: %v", p.Errors)
+		}
+		c := p.Env.packages["std/c"]
+		if n := len(*c.defs.Funcs[0].Ast.Combines); n != 2 {
+			t.Fatalf("generic combinations: got %d, want 2", n)
+		}
+	}
+}
+
+// TestUseCycle uses packages that uses each other.
+func TestUseCycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":    {Data: []byte("use std::a::*\n\nmain() {\n\ta()\n}\n")},
+		"std/a/a.jn": {Data: []byte("use std::b::*\n\npub a() { b() }\n")},
+		"std/b/b.jn": {Data: []byte("use std::a::*\n\npub b() {}\n")},
+	}
+	p := parseFS(t, fsys, "std")
+	found := false
+	for _, log := range p.Errors {
+		if log.Key == "use_cycle" {
+			found = true
+			if log.Path != "std/b/b.jn" || log.Row != 1 {
+				t.Errorf("cycle is reported at %s:%d", log.Path, log.Row)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("cycle is not reported: %v", logKeys(p.Errors))
+	}
+}
+
+// TestUseSelfCycle uses package from itself.
+func TestUseSelfCycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn":    {Data: []byte("use std::a::*\n\nmain() {}\n")},
+		"std/a/a.jn": {Data: []byte("use std::a::*\n\npub a() {}\n")},
+	}
+	p := parseFS(t, fsys, "std")
+	want := []string{"use_cycle", "use_has_errors"}
+	if keys := logKeys(p.Errors); !reflect.DeepEqual(keys, want) {
+		t.Errorf("errors: got %v, want %v", keys, want)
+	}
+}
+
+// TestUseLogOrder parses packages with errors many times,
+// errors of first package that has errors are reported
+// whatever the scheduling of other packages.
+func TestUseLogOrder(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn": {Data: []byte("use std::a\nuse std::b\nuse std::c\nuse std::d\n\nmain() {}\n")},
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		fsys["std/"+name+"/"+name+".jn"] = &fstest.MapFile{
+			Data: []byte("pub " + name + "() int {\n\tret undefined_" + name + "\n}\n"),
+		}
+	}
+	for i := 0; i < 20; i++ {
+		p := parseFS(t, fsys, "std")
+		want := []jnlog.CompilerLog{
+			{Type: jnlog.Error, Row: 2, Column: 9, Path: "std/a/a.jn", Key: "id_noexist"},
+			{Type: jnlog.Error, Row: 1, Column: 1, Path: "main.jn", Key: "use_has_errors"},
+		}
+		if len(p.Errors) != len(want) {
+			t.Fatalf("errors: %v", p.Errors)
+		}
+		for j, log := range p.Errors {
+			w := want[j]
+			if log.Type != w.Type || log.Row != w.Row || log.Column != w.Column || log.Path != w.Path || log.Key != w.Key {
+				t.Fatalf("error %d: got %v, want %v", j, log, w)
+			}
+		}
+	}
+}
+
+func TestUseMissing(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.jn": {Data: []byte("use std::missing\n\nmain() {}\n")},
+	}
+	p := parseFS(t, fsys, "std")
+	if len(p.Errors) == 0 {
+		t.Error("use of missing package is not reported")
+	}
+}
@@ -21,38 +21,30 @@
 package parser
 
 import (
-	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 
+	"github.com/DeRuneLabs/jane/ir"
 	"github.com/DeRuneLabs/jane/lexer/tokens"
 	"github.com/DeRuneLabs/jane/package/jnapi"
 	"github.com/DeRuneLabs/jane/package/jntype"
 )
 
 type valueEvaluator struct {
-	tok   Tok
-	model *exprModel
-	p     *Parser
+	tok Tok
+	p   *Parser
 }
 
-func strModel(v value) iExpr {
-	content := v.expr.(string)
-	if israwstr(content) {
-		return exprNode{jnapi.ToRawStr([]byte(content))}
-	}
-	return exprNode{jnapi.ToStr([]byte(content))}
+func strModel(v value) ir.Expr {
+	return &ir.Const{Type: v.data.Type, Value: v.expr.(string)}
 }
 
-func boolModel(v value) iExpr {
-	if v.expr.(bool) {
-		return exprNode{tokens.TRUE}
-	}
-	return exprNode{tokens.FALSE}
+func boolModel(v value) ir.Expr {
+	return &ir.Const{Type: v.data.Type, Value: v.expr.(bool)}
 }
 
-func getModel(v value) iExpr {
+func getModel(v value) ir.Expr {
 	switch v.expr.(type) {
 	case string:
 		return strModel(v)
@@ -63,15 +55,10 @@ func getModel(v value) iExpr {
 	}
 }
 
-func numericModel(v value) iExpr {
-	cppId := jntype.CppId(v.data.Type.Id)
-	switch t := v.expr.(type) {
-	case uint64:
-		return exprNode{cppId + "{" + strconv.FormatUint(t, 10) + "}"}
-	case int64:
-		return exprNode{cppId + "{" + strconv.FormatInt(t, 10) + "}"}
-	case float64:
-		return exprNode{cppId + "{" + fmt.Sprint(t) + "}"}
+func numericModel(v value) ir.Expr {
+	switch v.expr.(type) {
+	case uint64, int64, float64:
+		return &ir.Const{Type: v.data.Type, Value: v.expr}
 	}
 	return nil
 }
@@ -85,7 +72,6 @@ func (ve *valueEvaluator) str() value {
 	content := ve.tok.Kind[1 : len(ve.tok.Kind)-1]
 	v.expr = content
 	v.model = strModel(v)
-	ve.model.appendSubNode(v.model)
 	return v
 }
 
@@ -117,8 +103,7 @@ func (ve *valueEvaluator) char() value {
 	}
 	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 	v.expr, _ = strconv.ParseInt(content[2:], 16, 64)
-	v.model = exprNode{content}
-	ve.model.appendSubNode(v.model)
+	v.model = &ir.Const{Type: v.data.Type, Value: v.expr, Char: true}
 	return v
 }
 
@@ -130,7 +115,6 @@ func (ve *valueEvaluator) bool() value {
 	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 	v.expr = ve.tok.Kind == tokens.TRUE
 	v.model = boolModel(v)
-	ve.model.appendSubNode(v.model)
 	return v
 }
 
@@ -141,8 +125,7 @@ func (ve *valueEvaluator) nil() value {
 	v.data.Type.Id = jntype.Nil
 	v.data.Type.Kind = jntype.TypeMap[v.data.Type.Id]
 	v.expr = nil
-	v.model = exprNode{ve.tok.Kind}
-	ve.model.appendSubNode(v.model)
+	v.model = &ir.Const{Type: v.data.Type}
 	return v
 }
 
@@ -187,7 +170,6 @@ func (ve *valueEvaluator) numeric() value {
 	}
 	v.constExpr = true
 	v.model = numericModel(v)
-	ve.model.appendSubNode(v.model)
 	return v
 }
 
@@ -200,12 +182,12 @@ func (ve *valueEvaluator) varId(id string, variable *Var, global bool) (v value)
 	v.lvalue = true
 	v.heapMust = !global
 	if id == tokens.SELF && typeIsPtr(variable.Type) {
-		ve.model.appendSubNode(exprNode{jnapi.CppSelf})
+		v.model = &ir.SelfRef{Type: v.data.Type}
 	} else if v.constExpr {
 		v.expr = variable.ExprTag
 		v.model = variable.Expr.Model
 	} else {
-		ve.model.appendSubNode(exprNode{variable.OutId()})
+		v.model = &ir.VarRef{Type: v.data.Type, Var: variable}
 		ve.p.eval.hasError = ve.p.eval.hasError || typeIsVoid(v.data.Type)
 	}
 	return
@@ -218,7 +200,7 @@ func (ve *valueEvaluator) funcId(id string, f *function) (v value) {
 	v.data.Type.Tag = f.Ast
 	v.data.Type.Kind = f.Ast.DataTypeString()
 	v.data.Tok = f.Ast.Tok
-	ve.model.appendSubNode(exprNode{f.outId()})
+	v.model = &ir.FuncRef{Type: v.data.Type, Func: f.Ast, Entry: f.isEntryPoint}
 	return
 }
 
@@ -231,11 +213,7 @@ func (ve *valueEvaluator) enumId(id string, e *Enum) (v value) {
 	v.data.Tok = e.Tok
 	v.constExpr = true
 	v.isType = true
-	if e.Tok.Id == tokens.NA {
-		ve.model.appendSubNode(exprNode{jnapi.OutId(id, nil)})
-	} else {
-		ve.model.appendSubNode(exprNode{jnapi.OutId(id, e.Tok.File)})
-	}
+	v.model = &ir.EnumRef{Type: v.data.Type, Enum: e}
 	return
 }
 
@@ -248,12 +226,7 @@ func (ve *valueEvaluator) structId(id string, s *jnstruct) (v value) {
 	v.data.Type.Tok = s.Ast.Tok
 	v.data.Tok = s.Ast.Tok
 	v.isType = true
-	// If built-in.
-	if s.Ast.Tok.Id == tokens.NA {
-		ve.model.appendSubNode(exprNode{jnapi.OutId(id, nil)})
-	} else {
-		ve.model.appendSubNode(exprNode{jnapi.OutId(id, s.Ast.Tok.File)})
-	}
+	v.model = &ir.StructRef{Type: v.data.Type}
 	return
 }
 
@@ -301,6 +274,6 @@ func (ve *valueEvaluator) id() (_ value, ok bool) {
 		return ve.typeId(id, t)
 	}
 
-	ve.p.eval.pusherrtok(ve.tok, "id_noexist", id)
+	ve.p.eval.pusherrtokSuggest(ve.tok, id, ve.p.idCandidates(), "id_noexist", id)
 	return
 }
@@ -0,0 +1,255 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repl
+
+import (
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast"
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+	"github.com/DeRuneLabs/jane/package/jntype"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+// Kinds of inputs.
+const (
+	stmtInput uint8 = iota
+	declInput
+	useInput
+	prefixInput
+)
+
+// Row of first statement of entry point in program.
+const stmtRow = 3
+
+// Expressions are checked as assignments to ignore.
+const ignorePrefix = "_ = "
+
+// unclosed reports whether log is about unclosed range.
+func unclosed(log jnlog.CompilerLog) bool {
+	switch log.Key {
+	case "wait_close_parentheses", "wait_close_brace", "wait_close_bracket":
+		return true
+	}
+	return false
+}
+
+// syntaxOnly reports whether logs are only syntax errors.
+func syntaxOnly(logs []jnlog.CompilerLog) bool {
+	for _, log := range logs {
+		if log.Key != "invalid_syntax" {
+			return false
+		}
+	}
+	return true
+}
+
+// complete reports whether input has no unclosed ranges,
+// so input is ready to evaluate.
+func complete(input string) bool {
+	lex := lexer.NewLex(&jnio.File{Data: []rune(input)})
+	lex.Lex()
+	if len(lex.Logs) == 0 {
+		return true
+	}
+	for _, log := range lex.Logs {
+		if !unclosed(log) {
+			return true
+		}
+	}
+	return false
+}
+
+// kindOf returns kind of input by syntax tree of input as source file.
+// Inputs that not valid as source file are statements.
+// Inputs that have only comments, attributes and generics are
+// prefixes of next declaration.
+func kindOf(input string) uint8 {
+	lex := lexer.NewLex(&jnio.File{Data: []rune(input)})
+	toks := lex.Lex()
+	if len(lex.Logs) > 0 {
+		return stmtInput
+	}
+	b := ast.NewBuilder(toks)
+	b.Build()
+	if len(b.Errors) > 0 {
+		return stmtInput
+	}
+	kind := prefixInput
+	for _, obj := range b.Tree {
+		switch t := obj.Data.(type) {
+		case models.Use:
+			if kind == prefixInput {
+				kind = useInput
+			}
+		case models.Comment, models.Attribute, []models.GenericType:
+		case models.Statement:
+			if _, ok := t.Data.(models.Var); ok {
+				return stmtInput
+			}
+			kind = declInput
+		default:
+			kind = declInput
+		}
+	}
+	return kind
+}
+
+// lines returns line count of inputs.
+func lines(inputs []string) int {
+	n := 0
+	for _, input := range inputs {
+		n += strings.Count(input, "\n") + 1
+	}
+	return n
+}
+
+// inputStarts returns first rows of inputs in source of program,
+// in ascending order. Uses are single input at first row.
+func inputStarts(decls, stmts []string) []int {
+	starts := []int{1}
+	row := stmtRow
+	for _, s := range stmts {
+		starts = append(starts, row)
+		row += lines([]string{s})
+	}
+	// closing brace of entry point
+	row++
+	for _, d := range decls {
+		starts = append(starts, row)
+		row += lines([]string{d})
+	}
+	return starts
+}
+
+// source returns source code of program.
+// Uses are placed at first line and statements are placed into entry point,
+// so positions of statements are not changes by new uses and declarations.
+func source(uses, decls, stmts []string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.Join(uses, "; "))
+	sb.WriteString("\nmain() {\n")
+	for _, s := range stmts {
+		sb.WriteString(s)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("}\n")
+	for _, d := range decls {
+		sb.WriteString(d)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// program is checked program of inputs.
+type program struct {
+	p   *parser.Parser
+	pkg *ir.Package
+	// body is statements of entry point.
+	body []ir.Stmt
+}
+
+// check checks program of inputs.
+// Variables that not used are not reported,
+// because they can be used by next inputs.
+func (r *Repl) check(uses, decls, stmts []string) (*program, []jnlog.CompilerLog) {
+	r.file.Data = []rune(source(uses, decls, stmts))
+	r.starts = inputStarts(decls, stmts)
+	lex := lexer.NewLex(r.file)
+	toks := lex.Lex()
+	if len(lex.Logs) > 0 {
+		return nil, lex.Logs
+	}
+	b := ast.NewBuilder(toks)
+	b.StdlibPath = r.env.StdlibPath
+	b.Build()
+	if len(b.Errors) > 0 {
+		return nil, b.Errors
+	}
+	p := parser.New(r.file)
+	p.Env = r.env
+	p.NoLocalPkg = true
+	p.Parset(b.Tree, true, false)
+	var errors []jnlog.CompilerLog
+	for _, log := range p.Errors {
+		if log.Key != "declared_but_not_used" {
+			errors = append(errors, log)
+		}
+	}
+	if len(errors) > 0 {
+		return nil, errors
+	}
+	prog := &program{p: p, pkg: p.IR()}
+	for _, f := range prog.pkg.Funcs {
+		if f.Entry {
+			prog.body = f.Body.Stmts
+			break
+		}
+	}
+	return prog, nil
+}
+
+// tail returns statements of body after first n statements.
+// Statements that comes after recover calls are nested into try blocks.
+func tail(body []ir.Stmt, n int) []ir.Stmt {
+	for i, s := range body {
+		if i == n {
+			return body[i:]
+		}
+		if t, ok := s.(*ir.Try); ok {
+			return tail(t.Body.Stmts, n-i-1)
+		}
+	}
+	return nil
+}
+
+// count returns statement count of body, like tail.
+func count(body []ir.Stmt) int {
+	for i, s := range body {
+		if t, ok := s.(*ir.Try); ok {
+			return i + 1 + count(t.Body.Stmts)
+		}
+	}
+	return len(body)
+}
+
+// valueOf returns expression of statements if statements are
+// single expression that has value.
+func valueOf(stmts []ir.Stmt) ir.Expr {
+	if len(stmts) != 1 {
+		return nil
+	}
+	switch t := stmts[0].(type) {
+	case *ir.ExprStmt:
+		if t.X.DataType().Id != jntype.Void {
+			return t.X
+		}
+	case *ir.Assign:
+		if t.Left == nil {
+			return t.Right
+		}
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package repl implements read-eval-print loop of Jane.
+//
+// Inputs are checked as single program by parser and executed by
+// interpreter. Declarations are placed after entry point and statements
+// are appended to body of entry point, so executed statements are not
+// executed again and their variables are stays alive.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/DeRuneLabs/jane/ast/models"
+	"github.com/DeRuneLabs/jane/backend/interp"
+	"github.com/DeRuneLabs/jane/ir"
+	"github.com/DeRuneLabs/jane/lexer/tokens"
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnio"
+	"github.com/DeRuneLabs/jane/package/jnlog"
+	"github.com/DeRuneLabs/jane/parser"
+)
+
+// Prompts of loop.
+const (
+	Prompt     = ">>> "
+	MorePrompt = "... "
+)
+
+// Repl is read-eval-print loop.
+type Repl struct {
+	in  *bufio.Reader
+	out io.Writer
+	// Color reports logs are painted with ANSI colors.
+	Color bool
+
+	env     *parser.Env
+	file    *jnio.File
+	uses    []string
+	decls   []string
+	stmts   []string
+	n       int    // executed statement count of entry point
+	starts  []int  // first rows of inputs in last checked program
+	prefix  string // comments, attributes and generics of next declaration
+	p       *parser.Parser
+	interp  *interp.Interp
+	session *interp.Session
+}
+
+// New returns new loop that reads inputs from in
+// and writes results and outputs of program to out.
+func New(in io.Reader, out io.Writer) *Repl {
+	r := &Repl{
+		in:     bufio.NewReader(in),
+		out:    out,
+		file:   &jnio.File{Name: "<repl>"},
+		interp: interp.New(out),
+	}
+	r.session = r.interp.NewSession()
+	return r
+}
+
+// Run evaluates inputs until end of input or quit command.
+func (r *Repl) Run() error {
+	r.env = parser.DefaultEnv()
+	prog, logs := r.check(nil, nil, nil)
+	if r.report(logs, 1) {
+		return nil
+	}
+	r.load(prog)
+	for {
+		input, err := r.read()
+		if err == io.EOF {
+			fmt.Fprintln(r.out)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !r.eval(input) {
+			return nil
+		}
+	}
+}
+
+// read reads lines until input has no unclosed ranges.
+func (r *Repl) read() (string, error) {
+	var input strings.Builder
+	prompt := Prompt
+	for {
+		io.WriteString(r.out, prompt)
+		line, err := r.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		input.WriteString(line)
+		if complete(input.String()) {
+			return strings.TrimSpace(input.String()), nil
+		}
+		prompt = MorePrompt
+	}
+}
+
+// eval evaluates input, reports false if loop should be stopped.
+func (r *Repl) eval(input string) bool {
+	switch {
+	case input == "":
+	case strings.HasPrefix(input, tokens.COLON):
+		return r.command(input[1:])
+	default:
+		kind := kindOf(input)
+		if kind == prefixInput {
+			r.prefix += input + "\n"
+			break
+		}
+		prefix := r.prefix
+		r.prefix = ""
+		switch kind {
+		case useInput:
+			r.use(input)
+		case declInput:
+			r.declare(prefix, input)
+		default:
+			r.exec(input)
+		}
+	}
+	return true
+}
+
+// command runs meta-command.
+func (r *Repl) command(cmd string) bool {
+	name, arg, _ := strings.Cut(cmd, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "quit", "q":
+		return false
+	case "type":
+		r.typeOf(arg)
+	case "doc":
+		r.doc(arg)
+	case "use":
+		r.use(tokens.USE + " " + arg)
+	default:
+		r.errorf("repl_unknown_command", name)
+	}
+	return true
+}
+
+func (r *Repl) errorf(key string, args ...any) {
+	log := jnlog.CompilerLog{
+		Type:    jnlog.FlatError,
+		Key:     key,
+		Message: jn.GetError(key, args...),
+	}
+	io.WriteString(r.out, log.Pretty(r.Color))
+}
+
+// report writes logs, reports true if logs are exist.
+// Rows of input that starts at row are shown relative to input,
+// rows of previous inputs are shown relative to their inputs.
+func (r *Repl) report(logs []jnlog.CompilerLog, row int) bool {
+	path := r.file.Path()
+	for _, log := range logs {
+		if log.Path == path {
+			log.Row = r.inputRow(log.Row, row)
+		}
+		if len(log.Related) > 0 {
+			log.Related = append([]jnlog.Related(nil), log.Related...)
+			for i := range log.Related {
+				if rel := &log.Related[i]; rel.Path == path {
+					rel.Row = r.inputRow(rel.Row, row)
+				}
+			}
+		}
+		io.WriteString(r.out, log.Pretty(r.Color))
+	}
+	return len(logs) > 0
+}
+
+// inputRow returns row of program relative to input that contains it.
+// Current input starts at first, after its prefix.
+func (r *Repl) inputRow(row, first int) int {
+	start := 1
+	for _, s := range r.starts {
+		if s > row {
+			break
+		}
+		start = s
+	}
+	if start <= first && first <= row {
+		start = first
+	}
+	return row - start + 1
+}
+
+func (r *Repl) load(prog *program) {
+	r.p = prog.p
+	if err := r.interp.Load(prog.pkg); err != nil {
+		r.fail(err)
+	}
+}
+
+func (r *Repl) fail(err error) {
+	if _, ok := err.(*interp.Panic); ok {
+		fmt.Fprintln(r.out, "panic: "+err.Error())
+	} else {
+		fmt.Fprintln(r.out, err.Error())
+	}
+}
+
+// use adds use declarations of input.
+func (r *Repl) use(input string) {
+	uses := r.uses[:len(r.uses):len(r.uses)]
+	for _, line := range strings.Split(input, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			uses = append(uses, line)
+		}
+	}
+	prog, logs := r.check(uses, r.decls, r.stmts)
+	if r.report(logs, 1) {
+		return
+	}
+	r.uses = uses
+	r.load(prog)
+}
+
+// declare adds declarations of input.
+// Prefix is lines that given before input, such as documentations.
+func (r *Repl) declare(prefix, input string) {
+	decls := append(r.decls[:len(r.decls):len(r.decls)], prefix+input)
+	prog, logs := r.check(r.uses, decls, r.stmts)
+	row := stmtRow + lines(r.stmts) + 1 + lines(r.decls) + strings.Count(prefix, "\n")
+	if r.report(logs, row) {
+		return
+	}
+	r.decls = decls
+	r.load(prog)
+}
+
+// exec executes statements of input and writes value of input
+// if input is an expression. Expressions are checked as assignment
+// to ignore if input is not valid statement.
+func (r *Repl) exec(input string) {
+	stmts := append(r.stmts[:len(r.stmts):len(r.stmts)], input)
+	prog, logs := r.check(r.uses, r.decls, stmts)
+	if len(logs) > 0 {
+		x, xlogs := r.expr(input)
+		if x != nil {
+			r.value(x)
+			return
+		}
+		// input is not a statement, so errors of expression are relevant
+		if syntaxOnly(logs) && len(xlogs) > 0 {
+			logs = xlogs
+		}
+		r.report(logs, stmtRow+lines(r.stmts))
+		return
+	}
+	r.load(prog)
+	executed := tail(prog.body, r.n)
+	if x := valueOf(executed); x != nil {
+		if !r.value(x) {
+			return
+		}
+	} else if err := r.session.Exec(executed); err != nil {
+		r.fail(err)
+		return
+	}
+	r.stmts = stmts
+	r.n = count(prog.body)
+}
+
+// expr checks input as expression.
+// Package of expression is loaded if input is an expression.
+func (r *Repl) expr(input string) (ir.Expr, []jnlog.CompilerLog) {
+	stmts := append(r.stmts[:len(r.stmts):len(r.stmts)], ignorePrefix+input)
+	prog, logs := r.check(r.uses, r.decls, stmts)
+	if len(logs) > 0 {
+		r.trimPrefix(logs, stmtRow+lines(r.stmts))
+		return nil, logs
+	}
+	x := valueOf(tail(prog.body, r.n))
+	if x != nil {
+		r.load(prog)
+	}
+	return x, nil
+}
+
+// trimPrefix removes prefix of expressions from logs of row.
+func (r *Repl) trimPrefix(logs []jnlog.CompilerLog, row int) {
+	path := r.file.Path()
+	for i := range logs {
+		log := &logs[i]
+		if log.Path != path || log.Row != row || !strings.HasPrefix(log.Line, ignorePrefix) {
+			continue
+		}
+		log.Line = log.Line[len(ignorePrefix):]
+		log.Column -= len(ignorePrefix)
+		if log.Column < 1 {
+			log.Column = 1
+		}
+	}
+}
+
+// value evaluates expression and writes value with its type.
+func (r *Repl) value(x ir.Expr) bool {
+	v, err := r.session.Eval(x)
+	if err != nil {
+		r.fail(err)
+		return false
+	}
+	fmt.Fprintf(r.out, "%s: %s\n", v, x.DataType().Kind)
+	return true
+}
+
+// typeOf writes type of expression without evaluation.
+func (r *Repl) typeOf(input string) {
+	x, logs := r.expr(input)
+	if r.report(logs, stmtRow+lines(r.stmts)) {
+		return
+	}
+	if x == nil {
+		r.errorf("missing_expr")
+		return
+	}
+	fmt.Fprintln(r.out, x.DataType().Kind)
+}
+
+// doc writes definition and documentation of identifier.
+// Identifiers of used packages are given with namespaces.
+func (r *Repl) doc(id string) {
+	var sym *parser.Symbol
+	path := strings.Split(id, tokens.DOUBLE_COLON)
+	if n := len(path) - 1; n > 0 {
+		for _, s := range r.p.NamespaceSymbols(path[:n]) {
+			if s.Id == path[n] {
+				sym = s
+				break
+			}
+		}
+	} else {
+		// end of entry point, so all variables of inputs are visible.
+		tok := models.Tok{File: r.file, Row: stmtRow + lines(r.stmts), Column: 1}
+		sym = r.p.Lookup(id, tok)
+	}
+	if sym == nil {
+		r.errorf("id_noexist", id)
+		return
+	}
+	switch sym.Kind {
+	case parser.SymbolVar, parser.SymbolConst, parser.SymbolField:
+		fmt.Fprintln(r.out, sym.Id+": "+sym.Detail)
+	default:
+		fmt.Fprintln(r.out, sym.Detail)
+	}
+	if doc := strings.TrimSpace(sym.Doc); doc != "" {
+		fmt.Fprintln(r.out, doc)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2024 - DeRuneLabs
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package repl
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeRuneLabs/jane/package/jn"
+	"github.com/DeRuneLabs/jane/package/jnset"
+)
+
+// session runs loop with input and returns its output.
+func session(t *testing.T, input string) string {
+	t.Helper()
+	stdlib, err := filepath.Abs(filepath.Join("..", jn.Stdlib))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jn.StdlibPath = stdlib
+	jn.Set = jnset.Default
+	out := new(bytes.Buffer)
+	if err := New(strings.NewReader(input), out).Run(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestSession(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "variables",
+			input: "x: = 2\nx * 21\nx = 5\nx\n",
+			want:  ">>> >>> 42: int\n>>> >>> 5: int\n>>> \n",
+		},
+		{
+			name:  "statements are executed once",
+			input: "println(\"hi\")\nprint(\"a\")\nprintln(\"b\")\n",
+			want:  ">>> hi\n>>> a>>> b\n>>> \n",
+		},
+		{
+			name:  "multi-line declaration",
+			input: "add(a int, b int) int {\n\tret a + b\n}\nadd(1, 2)\n",
+			want:  ">>> ... ... >>> 3: int\n>>> \n",
+		},
+		{
+			name:  "documentation",
+			input: "//doc: triple of n\ntriple(n int) int { ret n * 3 }\n:doc triple\ntriple(2)\n",
+			want:  ">>> >>> >>> triple(int)int\ntriple of n\n>>> 6: int\n>>> \n",
+		},
+		{
+			name:  "type",
+			input: "x: = 1\n:type x + 1.5\n:type \"a\"\n",
+			want:  ">>> >>> f64\n>>> str\n>>> \n",
+		},
+		{
+			name:  "failed statement is dropped",
+			input: "x: = 1\nx: = 2\nx\n",
+			want: ">>> >>> error[exist_id]: identifier is already exist: x\n" +
+				" --> <repl>:1:1\n  |\n1 | x: = 2\n  | ^\n ::: <repl>:1:1\n  |\n1 | x: = 1\n  | - first declared here\n>>> 1: int\n>>> \n",
+		},
+		{
+			name:  "redeclared function",
+			input: "x: = 1\nf() {}\ny: = 2\nf() {}\n",
+			want: ">>> >>> >>> >>> error[exist_id]: identifier is already exist: f\n" +
+				" --> <repl>:1:1\n  |\n1 | f() {}\n  | ^\n ::: <repl>:1:1\n  |\n1 | f() {}\n  | - first declared here\n>>> \n",
+		},
+		{
+			name:  "panic",
+			input: "xs: = []int{1}\nxs[2]\nxs.len\n",
+			want:  ">>> >>> panic: index out of range [2]\n>>> 1: int\n>>> \n",
+		},
+		{
+			name:  "use",
+			input: "use std::errors\n:doc std::errors::new\n",
+			want:  ">>> >>> new(str)Error\ncreate new error with given message\n>>> \n",
+		},
+		{
+			name:  "commands",
+			input: ":nope\n:q\nprintln(1)\n",
+			want:  ">>> error[repl_unknown_command]: unknown command: nope\n>>> ",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := session(t, c.input); got != c.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, c.want)
+			}
+		})
+	}
+}
+
+// TestSessionErrorRows reports rows of errors relative to input.
+func TestSessionErrorRows(t *testing.T) {
+	out := session(t, "x: = 1\ny: = 2\nf() {\n\tret z\n}\n")
+	if !strings.Contains(out, "<repl>:2:9") || !strings.Contains(out, "2 |     ret z") {
+		t.Errorf("error is not relative to input:\n%s", out)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	cases := []struct {
+		input string
+		want  bool
+	}{
+		{"x: = 1", true},
+		{"f() {", false},
+		{"f() {\n}", true},
+		{"xs: = []int{1,", false},
+		{"s: = \"{\"", true},
+		{"// {", true},
+	}
+	for _, c := range cases {
+		if got := complete(c.input); got != c.want {
+			t.Errorf("complete(%q): got %v, want %v", c.input, got, c.want)
+		}
+	}
+}